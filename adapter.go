@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// FromErrorCheck converts a check in the heptiolabs/healthcheck style, which
+// returns nil whilst healthy, into a check for RegisterDependency
+func FromErrorCheck(check func() error) func() bool {
+	return func() bool {
+		return check() == nil
+	}
+}
+
+// ToErrorCheck converts a check for RegisterDependency into one in the
+// heptiolabs/healthcheck style, returning ErrUnhealthy whilst unhealthy
+func ToErrorCheck(check func() bool) func() error {
+	return func() error {
+		if !check() {
+			return ErrUnhealthy
+		}
+		return nil
+	}
+}
+
+// ErrorCheck returns a heptiolabs/healthcheck style check reporting the health
+// of the service as of the last check, so a ServiceCheck can be added to a
+// healthcheck.Handler whilst migrating
+func (s *ServiceCheck) ErrorCheck() func() error {
+	return ToErrorCheck(s.IsHealthy)
+}
+
+// FromContextCheck converts a check taking a context and returning nil whilst
+// healthy into a check for RegisterDependency, the context being cancelled
+// after `timeout` if it is positive. This is the shape of the checkers of both
+// alexliesenfeld/health and hellofresh/health-go, so their prebuilt checkers
+// can be registered directly:
+//
+//	// alexliesenfeld/health
+//	check.RegisterDependency("db", health.LevelHard, health.FromContextCheck(pg.Check, time.Second))
+//	// hellofresh/health-go
+//	check.RegisterDependency("redis", health.LevelSoft, health.FromContextCheck(redis.New(config), time.Second))
+func FromContextCheck(check func(context.Context) error, timeout time.Duration) func() bool {
+	return func() bool {
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return check(ctx) == nil
+	}
+}
+
+// ToContextCheck converts a check for RegisterDependency into one taking a
+// context, returning ErrUnhealthy whilst unhealthy, for registering with
+// alexliesenfeld/health or hellofresh/health-go. The check is not interrupted
+// when the context is cancelled.
+func ToContextCheck(check func() bool) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return ToErrorCheck(check)()
+	}
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFromErrorCheck(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, true},
+		{errors.New("connection refused"), false},
+	}
+
+	for _, tc := range testCases {
+		got := FromErrorCheck(func() error { return tc.err })()
+		if got != tc.expected {
+			t.Errorf("expected %v got %v for %v", tc.expected, got, tc.err)
+		}
+	}
+}
+
+func TestToErrorCheck(t *testing.T) {
+	if err := ToErrorCheck(func() bool { return true })(); err != nil {
+		t.Errorf("expected %v got %v", nil, err)
+	}
+	if err := ToErrorCheck(func() bool { return false })(); err != ErrUnhealthy {
+		t.Errorf("expected %v got %v", ErrUnhealthy, err)
+	}
+}
+
+func TestServiceErrorCheck(t *testing.T) {
+	s, _ := InitialiseServiceCheck("test", time.Minute)
+	s.RegisterDependency("db", LevelHard, func() bool { return false })
+
+	check := s.ErrorCheck()
+	if err := check(); err != nil {
+		t.Errorf("expected %v got %v before the first check", nil, err)
+	}
+
+	s.Update()
+	if err := check(); err != ErrUnhealthy {
+		t.Errorf("expected %v got %v", ErrUnhealthy, err)
+	}
+}
+
+// checkFunc has the shape of hellofresh/health-go's CheckFunc
+type checkFunc func(ctx context.Context) error
+
+func TestFromContextCheck(t *testing.T) {
+	var hasDeadline bool
+	var check checkFunc = func(ctx context.Context) error {
+		_, hasDeadline = ctx.Deadline()
+		return ctx.Err()
+	}
+
+	if !FromContextCheck(check, time.Second)() || !hasDeadline {
+		t.Error("expected healthy with a deadline")
+	}
+	if !FromContextCheck(check, 0)() || hasDeadline {
+		t.Error("expected healthy without a deadline")
+	}
+
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if FromContextCheck(slow, time.Millisecond)() {
+		t.Error("expected unhealthy once timed out")
+	}
+}
+
+func TestToContextCheck(t *testing.T) {
+	check := ToContextCheck(func() bool { return false })
+	if err := check(context.Background()); err != ErrUnhealthy {
+		t.Errorf("expected %v got %v", ErrUnhealthy, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ToContextCheck(func() bool { return true })(ctx); err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+}
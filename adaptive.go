@@ -0,0 +1,72 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// WithAdaptiveInterval checks the dependency less often the longer its health
+// holds, doubling the interval from `min` after each unchanged result up to
+// `max`, and going back to `min` as soon as it changes. This cuts the cost of
+// probing large fleets of stable dependencies. StartCheck can't check it more
+// often than the service's duration, StartSpread checks it at the interval.
+func WithAdaptiveInterval(min, max time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		if max < min {
+			max = min
+		}
+
+		a := &adaptive{min: min, max: max, interval: min, clock: d.owner.getClock()}
+		d.check = a.wrap(d.check)
+		d.adaptive = a
+	}
+}
+
+// adaptive tracks the interval of a dependency registered with
+// WithAdaptiveInterval
+type adaptive struct {
+	min   time.Duration
+	max   time.Duration
+	clock Clock
+
+	mu       sync.Mutex
+	interval time.Duration
+	ran      bool
+	last     time.Time
+	result   bool
+}
+
+// wrap returns fn run at most once per interval, reporting the last result in
+// between
+func (a *adaptive) wrap(fn func() bool) func() bool {
+	return func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		now := a.clock.Now()
+		if a.ran && now.Sub(a.last) < a.interval {
+			return a.result
+		}
+
+		result := fn()
+		switch {
+		case a.ran && result == a.result:
+			a.interval *= 2
+			if a.interval > a.max {
+				a.interval = a.max
+			}
+		default:
+			a.interval = a.min
+		}
+		a.ran, a.last, a.result = true, now, result
+
+		return result
+	}
+}
+
+// current returns the interval until the dependency is next checked
+func (a *adaptive) current() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.interval
+}
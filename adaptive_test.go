@@ -0,0 +1,63 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithAdaptiveInterval(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+
+	var runs int
+	healthy := true
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		runs++
+		return healthy
+	}, WithAdaptiveInterval(time.Second, 4*time.Second))
+
+	// stepped a second at a time, the check runs after 1s, then 2s, then 4s
+	var ran []int
+	for i := 1; i <= 12; i++ {
+		clock.advance(time.Second, 0)
+		before := runs
+		check.Update()
+		if runs != before {
+			ran = append(ran, i)
+		}
+	}
+
+	expected := []int{1, 3, 7, 11}
+	if len(ran) != len(expected) {
+		t.Fatalf("expected runs at %v got %v", expected, ran)
+	}
+	for i := range expected {
+		if ran[i] != expected[i] {
+			t.Errorf("expected runs at %v got %v", expected, ran)
+			break
+		}
+	}
+	if interval := db.adaptive.current(); interval != 4*time.Second {
+		t.Errorf("expected %v got %v", 4*time.Second, interval)
+	}
+
+	// a change goes straight back to the minimum
+	healthy = false
+	clock.advance(4*time.Second, 0)
+	check.Update()
+	if interval := db.adaptive.current(); interval != time.Second {
+		t.Errorf("expected %v got %v", time.Second, interval)
+	}
+	if db.IsHealthy() {
+		t.Error("expected db to be unhealthy")
+	}
+}
+
+func TestWithAdaptiveIntervalSpread(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Hour)
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true }, WithAdaptiveInterval(time.Millisecond, time.Minute))
+
+	if interval := check.interval(db); interval != time.Millisecond {
+		t.Errorf("expected %v got %v", time.Millisecond, interval)
+	}
+}
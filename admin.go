@@ -0,0 +1,100 @@
+package health
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AdminConfig configures the operations endpoints mounted by AdminMux
+type AdminConfig struct {
+	// Metrics is served at /metrics if set, for example promhttp.Handler()
+	Metrics http.Handler
+	// Authorise is consulted for every request if set, requests it rejects
+	// receive a 401. See BasicAuth and BearerToken.
+	Authorise func(r *http.Request) bool
+}
+
+// AdminMux returns a http.ServeMux with the operations endpoints mounted, so
+// an operations port is a single call:
+//
+//	/health   the status, as per HTTPHandler
+//	/readyz   the status, as per ReadinessHandler
+//	/livez    the status, as per LivenessHandler
+//	/metrics  config.Metrics, if set
+//
+// The mux can be extended with further endpoints, which aren't covered by
+// config.Authorise.
+func (s *ServiceCheck) AdminMux(config AdminConfig) *http.ServeMux {
+	guard := func(h http.Handler) http.Handler {
+		if config.Authorise == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !config.Authorise(r) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/health", guard(http.HandlerFunc(s.HTTPHandler)))
+	mux.Handle("/readyz", guard(http.HandlerFunc(s.ReadinessHandler)))
+	mux.Handle("/livez", guard(http.HandlerFunc(s.LivenessHandler)))
+	if config.Metrics != nil {
+		mux.Handle("/metrics", guard(config.Metrics))
+	}
+
+	return mux
+}
+
+// Handler returns HTTPHandler as a http.Handler, for routers taking one
+func (s *ServiceCheck) Handler() http.Handler {
+	return http.HandlerFunc(s.HTTPHandler)
+}
+
+// Mount registers the probe endpoints on `mux` under `basePath`, such as
+// "/internal" or "" for the root:
+//
+//	/healthz  the status, as per HTTPHandler
+//	/readyz   the status, as per ReadinessHandler
+//	/livez    the status, as per LivenessHandler
+//
+// Routes which should be shed whilst the service is unhealthy can be wrapped
+// in ShedMiddleware.
+func (s *ServiceCheck) Mount(mux *http.ServeMux, basePath string) {
+	basePath = strings.TrimSuffix(basePath, "/")
+	mux.HandleFunc(basePath+"/healthz", s.HTTPHandler)
+	mux.HandleFunc(basePath+"/readyz", s.ReadinessHandler)
+	mux.HandleFunc(basePath+"/livez", s.LivenessHandler)
+}
+
+// BasicAuth returns an AdminConfig.Authorise function accepting requests with
+// the given HTTP basic auth credentials
+func BasicAuth(username, password string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		return ok && equal(u, username) && equal(p, password)
+	}
+}
+
+// BearerToken returns an AdminConfig.Authorise function accepting requests
+// with the given bearer token
+func BearerToken(token string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			return false
+		}
+		return equal(strings.TrimPrefix(auth, "Bearer "), token)
+	}
+}
+
+// equal compares credentials in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
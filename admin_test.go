@@ -0,0 +1,100 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdminMux(t *testing.T) {
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("health_service_up 0\n"))
+	})
+	mux := (&ServiceCheck{Name: "test"}).AdminMux(AdminConfig{Metrics: metrics})
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/health", 503},
+		{"/readyz", 503},
+		{"/livez", 200},
+		{"/metrics", 200},
+		{"/debug", 404},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, w.Code, test.path)
+		}
+	}
+}
+
+func TestAdminMuxAuthorise(t *testing.T) {
+	tests := []struct {
+		authorise func(*http.Request) bool
+		setup     func(*http.Request)
+		expected  int
+	}{
+		{BasicAuth("ops", "secret"), func(r *http.Request) { r.SetBasicAuth("ops", "secret") }, 200},
+		{BasicAuth("ops", "secret"), func(r *http.Request) { r.SetBasicAuth("ops", "guess") }, 401},
+		{BasicAuth("ops", "secret"), func(r *http.Request) {}, 401},
+		{BearerToken("t0k3n"), func(r *http.Request) { r.Header.Set("Authorization", "Bearer t0k3n") }, 200},
+		{BearerToken("t0k3n"), func(r *http.Request) { r.Header.Set("Authorization", "t0k3n") }, 401},
+	}
+
+	for i, test := range tests {
+		mux := (&ServiceCheck{Name: "test", Healthy: true}).AdminMux(AdminConfig{Authorise: test.authorise})
+
+		r := httptest.NewRequest("GET", "/health", nil)
+		test.setup(r)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for case %d", test.expected, w.Code, i)
+		}
+	}
+}
+
+func TestMount(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return false })
+	check.Update()
+
+	tests := []struct {
+		basePath string
+		path     string
+		expected int
+	}{
+		{"", "/healthz", 503},
+		{"", "/readyz", 503},
+		{"", "/livez", 200},
+		{"/internal/", "/internal/healthz", 503},
+		{"/internal", "/internal/livez", 200},
+		{"/internal", "/healthz", 404},
+	}
+
+	for _, test := range tests {
+		mux := http.NewServeMux()
+		check.Mount(mux, test.basePath)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, w.Code, test.path)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Minute)
+
+	w := httptest.NewRecorder()
+	check.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 200 {
+		t.Errorf("expected 200 got %d", w.Code)
+	}
+}
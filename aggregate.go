@@ -0,0 +1,111 @@
+package health
+
+// Aggregator decides the Status of a service from those of its dependencies,
+// replacing the default of unhealthy whilst a hard dependency is failing and
+// degraded whilst a soft one is, see WithAggregator
+type Aggregator interface {
+	// Aggregate returns the Status of a service with `dependencies`, copies
+	// of its dependencies as of the last check. It is called with the
+	// check's lock held, so mustn't call its methods.
+	Aggregate(dependencies []Dependency) Status
+}
+
+// AggregatorFunc is a func used as an Aggregator
+type AggregatorFunc func(dependencies []Dependency) Status
+
+// Aggregate calls f
+func (f AggregatorFunc) Aggregate(dependencies []Dependency) Status {
+	return f(dependencies)
+}
+
+// WithAggregator decides the health of the service, and whether it is
+// degraded, with `aggregator` rather than by the levels of its failing
+// dependencies alone
+func WithAggregator(aggregator Aggregator) Option {
+	return func(s *ServiceCheck) {
+		s.aggregator = aggregator
+	}
+}
+
+// WeightedAggregator is an Aggregator scoring the failing dependencies by
+// their weights, see WithWeight. The service is unhealthy whilst a hard
+// dependency is failing or the failing share of the total weight of the hard
+// and soft dependencies exceeds Unhealthy, if set, and degraded whilst it
+// exceeds Degraded. Informational dependencies are left out.
+type WeightedAggregator struct {
+	Degraded  float64
+	Unhealthy float64
+}
+
+// Aggregate returns the Status of a service with `dependencies`
+func (a WeightedAggregator) Aggregate(dependencies []Dependency) Status {
+	var total, failing float64
+	for i := range dependencies {
+		dependency := &dependencies[i]
+		if dependency.Level == LevelInformational {
+			continue
+		}
+		if !dependency.Healthy && dependency.Level == LevelHard {
+			return StatusUnhealthy
+		}
+
+		total += dependency.Weight()
+		if !dependency.Healthy {
+			failing += dependency.Weight()
+		}
+	}
+	if failing == 0 {
+		return StatusHealthy
+	}
+
+	share := failing / total
+	switch {
+	case a.Unhealthy > 0 && share > a.Unhealthy:
+		return StatusUnhealthy
+	case share > a.Degraded:
+		return StatusDegraded
+	default:
+		return StatusHealthy
+	}
+}
+
+// WithWeight sets the weight of the dependency for a WeightedAggregator,
+// 1 by default
+func WithWeight(weight float64) DependencyOption {
+	return func(d *Dependency) {
+		d.weight = weight
+	}
+}
+
+// Weight returns the weight of the dependency, see WithWeight
+func (d *Dependency) Weight() float64 {
+	if d.weight <= 0 {
+		return 1
+	}
+
+	return d.weight
+}
+
+// aggregate returns the Status of the dependencies as per the check's
+// Aggregator, or by default unhealthy whilst a hard dependency is failing,
+// s.mu must be held
+func (s *ServiceCheck) aggregate() Status {
+	if s.aggregator == nil {
+		if !s.dependenciesHealthy() {
+			return StatusUnhealthy
+		}
+		return StatusHealthy
+	}
+
+	dependencies := make([]Dependency, len(s.Dependencies))
+	for i, dependency := range s.Dependencies {
+		// results recorded by recordResult are guarded by the shard lock
+		shard := s.shard(dependency)
+		shard.Lock()
+		dependencies[i] = *dependency
+		shard.Unlock()
+		dependencies[i].owner = nil
+	}
+
+	return s.aggregator.Aggregate(dependencies)
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLevelInformational(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("geoip", LevelInformational, func() bool { return false })
+	check.Update()
+
+	if !check.IsHealthy() || check.Status() != StatusHealthy {
+		t.Errorf("expected a failing informational dependency not to affect the service got %v", check.Status())
+	}
+	if failing := check.FailingDependencies(LevelInformational); failing != 1 {
+		t.Errorf("expected 1 got %d", failing)
+	}
+	expected := "healthy: 1 informational dependency failing (geoip)"
+	if message := check.Message(); message != expected {
+		t.Errorf("expected %s got %s", expected, message)
+	}
+}
+
+func TestWeightedAggregator(t *testing.T) {
+	tests := []struct {
+		hard     bool
+		failing  []string
+		expected Status
+	}{
+		{true, nil, StatusHealthy},
+		{true, []string{"search"}, StatusHealthy},
+		{true, []string{"cache"}, StatusDegraded},
+		{true, []string{"cache", "search"}, StatusDegraded},
+		{true, []string{"cache", "queue"}, StatusUnhealthy},
+		{false, nil, StatusUnhealthy},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithAggregator(WeightedAggregator{Degraded: 0.1, Unhealthy: 0.5}))
+		failing := make(map[string]bool)
+		for _, name := range test.failing {
+			failing[name] = true
+		}
+		hard := test.hard
+		check.RegisterDependency("db", LevelHard, func() bool { return hard }, WithWeight(4))
+		for name, weight := range map[string]float64{"cache": 3, "queue": 2, "search": 0.5} {
+			name := name
+			check.RegisterDependency(name, LevelSoft, func() bool { return !failing[name] }, WithWeight(weight))
+		}
+		// informational dependencies are left out
+		check.RegisterDependency("geoip", LevelInformational, func() bool { return false }, WithWeight(100))
+		check.Update()
+
+		if status := check.Status(); status != test.expected {
+			t.Errorf("expected %v got %v for %v", test.expected, status, test.failing)
+		}
+		if healthy := check.IsHealthy(); healthy != (test.expected != StatusUnhealthy) {
+			t.Errorf("expected healthy %v got %v for %v", test.expected != StatusUnhealthy, healthy, test.failing)
+		}
+	}
+}
+
+func TestAggregatorFunc(t *testing.T) {
+	// ensure an aggregator can ignore a failing hard dependency
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithAggregator(AggregatorFunc(func(dependencies []Dependency) Status {
+		return StatusDegraded
+	})))
+	check.RegisterDependency("db", LevelHard, func() bool { return false })
+	check.Update()
+
+	if !check.IsHealthy() || check.Status() != StatusDegraded || check.Document().Status != StatusDegraded {
+		t.Errorf("expected a degraded service got %v", check.Status())
+	}
+	expected := "degraded: 1 hard dependency failing (db)"
+	if message := check.Message(); message != expected {
+		t.Errorf("expected %s got %s", expected, message)
+	}
+}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithAsync runs the dependency's check every `every` on a goroutine of its
+// own, for checks too slow to hold up the check's cycles, such as an end to
+// end test of a write path. The cycles only read the last result, which is
+// unhealthy, and the dependency stale, once it is older than `maxAge`; until
+// the first result the dependency is unhealthy with ErrNoRecentResult. The
+// goroutine is started by the first check and stopped by StopCheck.
+func WithAsync(every, maxAge time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		d.async = &asyncCheck{check: d.check, every: every, maxAge: maxAge}
+		d.check = d.readAsync
+	}
+}
+
+// asyncCheck runs a dependency's check on a goroutine of its own, keeping
+// its last result for the cycles to read
+type asyncCheck struct {
+	check  func() bool
+	every  time.Duration
+	maxAge time.Duration
+
+	mu sync.Mutex
+	// ctx is that of the running goroutine, if any
+	ctx     context.Context
+	healthy bool
+	checked time.Time
+	stale   bool
+	err     error
+}
+
+// clone returns an asyncCheck with the same configuration which hasn't run
+// its check yet
+func (a *asyncCheck) clone() *asyncCheck {
+	return &asyncCheck{check: a.check, every: a.every, maxAge: a.maxAge}
+}
+
+// readAsync returns the last result of the asynchronous check, starting its
+// goroutine if it isn't running with the owner's current context
+func (d *Dependency) readAsync() bool {
+	ctx := context.Background()
+	if d.owner != nil {
+		ctx = d.owner.context()
+	}
+	now := d.clock().Now()
+
+	a := d.async
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.ctx != ctx {
+		a.ctx = ctx
+		go d.runAsync(ctx, a)
+	}
+
+	switch {
+	case a.checked.IsZero():
+		a.stale, a.err = false, ErrNoRecentResult
+		return false
+	case now.Sub(a.checked) > a.maxAge:
+		a.stale, a.err = true, ErrNoRecentResult
+		return false
+	default:
+		a.stale, a.err = false, nil
+		return a.healthy
+	}
+}
+
+// runAsync runs the check of `a` every a.every until ctx is done
+func (d *Dependency) runAsync(ctx context.Context, a *asyncCheck) {
+	clock := d.clock()
+	for {
+		healthy, _, _ := d.run(a.check)
+
+		a.mu.Lock()
+		if a.ctx != ctx {
+			// superseded by a goroutine with a newer context
+			a.mu.Unlock()
+			return
+		}
+		a.healthy, a.checked = healthy, clock.Now()
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(a.every):
+		}
+	}
+}
+
+// lastError returns why the last read of the result was unhealthy, if it
+// wasn't the check itself
+func (a *asyncCheck) lastError() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// isStale returns whether the last read found the result too old
+func (a *asyncCheck) isStale() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stale
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithAsync(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+	defer check.StopCheck()
+
+	results := make(chan bool)
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return <-results },
+		WithAsync(time.Minute, 90*time.Second))
+
+	// ensure the dependency is unhealthy until the first result
+	if state := check.DependencyStates()[0]; state.Healthy || state.LastError != ErrNoRecentResult.Error() {
+		t.Errorf("expected no result got %+v", state)
+	}
+
+	// the cycles read the result without waiting on the check
+	results <- true
+	clock.advance(0, 1)
+	check.Update()
+	if !db.IsHealthy() || db.LastError != "" {
+		t.Errorf("expected the healthy result got %v %s", db.IsHealthy(), db.LastError)
+	}
+
+	// the next check runs after a minute, and doesn't finish
+	clock.advance(time.Minute, 1)
+	check.Update()
+	if !db.IsHealthy() {
+		t.Errorf("expected the last result within the maximum age")
+	}
+
+	clock.advance(time.Minute, 0)
+	check.Update()
+	state := check.DependencyStates()[0]
+	if state.Healthy || !state.Stale || state.LastError != ErrNoRecentResult.Error() {
+		t.Errorf("expected a stale unhealthy result got %+v", state)
+	}
+
+	results <- false
+	clock.advance(0, 1)
+	check.Update()
+	state = check.DependencyStates()[0]
+	if state.Healthy || state.Stale || state.LastError != "" {
+		t.Errorf("expected the unhealthy result got %+v", state)
+	}
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultWaitBackoff is the initial delay between checks of
+	// WaitForDependencies unless set by WithWaitBackoff
+	DefaultWaitBackoff = 100 * time.Millisecond
+	// DefaultWaitMaxBackoff caps the delay between checks of
+	// WaitForDependencies unless set by WithWaitBackoff
+	DefaultWaitMaxBackoff = 10 * time.Second
+)
+
+// WithWaitBackoff sets the delay between the checks WaitForDependencies makes
+// whilst the check isn't started, starting at `backoff` and doubling after
+// each check up to `maxBackoff`. Each delay is jittered by up to half, so
+// replicas starting together don't check their dependencies in lockstep.
+func WithWaitBackoff(backoff, maxBackoff time.Duration) Option {
+	return func(s *ServiceCheck) {
+		s.waitBackoff, s.waitMaxBackoff = backoff, maxBackoff
+	}
+}
+
+// waitBackoffs returns the initial and greatest delay between the checks of
+// WaitForDependencies
+func (s *ServiceCheck) waitBackoffs() (time.Duration, time.Duration) {
+	backoff, maxBackoff := s.waitBackoff, s.waitMaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultWaitBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultWaitMaxBackoff
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	return backoff, maxBackoff
+}
+
+// jitter returns `d` less a random amount of up to half of it
+func jitter(d time.Duration) time.Duration {
+	if d < 2 {
+		return d
+	}
+	return d - rand.N(d/2)
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithWaitBackoff(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	check, _ := InitialiseServiceCheck("test", time.Second, WithClock(clock), WithWaitBackoff(time.Second, 4*time.Second))
+
+	var (
+		mu   sync.Mutex
+		runs []time.Time
+	)
+	check.RegisterDependency("redis", LevelHard, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		runs = append(runs, clock.Now())
+		return false
+	})
+
+	done := make(chan bool)
+	go func() { done <- check.WaitForDependencies(12 * time.Second) }()
+
+	// step the clock once both the deadline and the next retry are waiting
+	const step = 10 * time.Millisecond
+	for i := 0; i < 1200; i++ {
+		clock.advance(step, 2)
+	}
+	if <-done {
+		t.Error("expected to time out")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the checks on registration and on waiting, then the retries
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	if len(runs) < len(expected)+2 {
+		t.Fatalf("expected at least %d checks got %d", len(expected)+2, len(runs))
+	}
+	for i, backoff := range expected {
+		gap := runs[i+2].Sub(runs[i+1])
+		if gap < backoff/2 || gap > backoff+step {
+			t.Errorf("expected a delay of %v less up to half got %v for retry %d", backoff, gap, i+1)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := jitter(time.Second); d <= time.Second/2 || d > time.Second {
+			t.Errorf("expected a delay between 500ms and 1s got %v", d)
+		}
+	}
+}
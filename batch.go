@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GetAllConcurrency is the maximum number of endpoints GetAll probes at once
+const GetAllConcurrency = 10
+
+// Result is the outcome of probing a single health endpoint
+type Result struct {
+	// Healthy reports whether the endpoint responded with a 200 and a healthy
+	// status document, as per Get
+	Healthy bool
+	// StatusCode is the status code of the response, or zero if no response
+	// was received
+	StatusCode int
+	// Err is set if the request failed or the response couldn't be decoded
+	Err error
+	// Latency is how long the probe took
+	Latency time.Duration
+}
+
+// GetAll probes every URL in parallel, at most GetAllConcurrency at a time,
+// and returns the outcome of each keyed by URL. Duplicate URLs are only probed
+// once. Function supports passing an optional *http.Client to use a different
+// timeout for the probes.
+func GetAll(ctx context.Context, urls []string, optionalClient ...*http.Client) map[string]Result {
+	client := getHTTPClient(optionalClient)
+
+	var (
+		results = make(map[string]Result, len(urls))
+		seen    = make(map[string]bool, len(urls))
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, GetAllConcurrency)
+	)
+
+	for _, u := range urls {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+
+			var result Result
+			select {
+			case sem <- struct{}{}:
+				start := time.Now()
+				result.Healthy, result.StatusCode, result.Err = get(ctx, client, u)
+				result.Latency = time.Since(start)
+				<-sem
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+			}
+
+			mu.Lock()
+			results[u] = result
+			mu.Unlock()
+		}(u)
+	}
+
+	wg.Wait()
+	return results
+}
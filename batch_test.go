@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetAll(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&ServiceCheck{Name: "healthy", Healthy: true})
+	}))
+	defer healthy.Close()
+
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(&ServiceCheck{Name: "unhealthy"})
+	}))
+	defer unhealthy.Close()
+
+	unreachable := "http://127.0.0.1:0"
+
+	results := GetAll(context.Background(), []string{healthy.URL, unhealthy.URL, unreachable, healthy.URL})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results got %d", len(results))
+	}
+
+	tests := []struct {
+		url            string
+		expectedHealth bool
+		expectedCode   int
+		expectedErr    bool
+	}{
+		{healthy.URL, true, http.StatusOK, false},
+		{unhealthy.URL, false, http.StatusServiceUnavailable, false},
+		{unreachable, false, 0, true},
+	}
+
+	for _, test := range tests {
+		result := results[test.url]
+		if result.Healthy != test.expectedHealth {
+			t.Errorf("expected %v got %v for %s", test.expectedHealth, result.Healthy, test.url)
+		}
+		if result.StatusCode != test.expectedCode {
+			t.Errorf("expected %d got %d for %s", test.expectedCode, result.StatusCode, test.url)
+		}
+		if (result.Err != nil) != test.expectedErr {
+			t.Errorf("expected error %v got %v for %s", test.expectedErr, result.Err, test.url)
+		}
+	}
+}
+
+func TestGetAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := GetAll(ctx, []string{"http://127.0.0.1:0"})
+	if results["http://127.0.0.1:0"].Err == nil {
+		t.Error("expected an error from a cancelled context")
+	}
+}
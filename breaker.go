@@ -0,0 +1,158 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBreakerOpenFor is how long a circuit stays open unless set by
+	// BreakerConfig.OpenFor
+	DefaultBreakerOpenFor = 30 * time.Second
+	// DefaultBreakerHalfOpenRequests is the number of requests allowed
+	// through a half-open circuit unless set by BreakerConfig.HalfOpenRequests
+	DefaultBreakerHalfOpenRequests = 1
+)
+
+// BreakerState is the state of a dependency's circuit breaker, see
+// WithCircuitBreaker
+type BreakerState uint8
+
+const (
+	// BreakerClosed allows every request, the dependency is healthy
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every request, the dependency is unhealthy
+	BreakerOpen
+	// BreakerHalfOpen allows a few trial requests, the dependency has been
+	// unhealthy for longer than BreakerConfig.OpenFor
+	BreakerHalfOpen
+)
+
+// String returns the name of the state
+func (b BreakerState) String() string {
+	switch b {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures WithCircuitBreaker
+type BreakerConfig struct {
+	// OpenFor is how long the circuit stays open once the dependency is found
+	// unhealthy before it is half-open, DefaultBreakerOpenFor if zero
+	OpenFor time.Duration
+	// HalfOpenRequests is the number of requests allowed through whilst the
+	// circuit is half-open, DefaultBreakerHalfOpenRequests if zero
+	HalfOpenRequests int
+}
+
+// WithCircuitBreaker drives a circuit breaker from the dependency's checks,
+// so request paths can consult Allow to fail fast rather than calling a
+// dependency which is down. The circuit opens when a check finds the
+// dependency unhealthy, becomes half-open after `config.OpenFor`, letting a
+// few trial requests through, and closes once a check finds it healthy. A
+// check failing whilst the circuit is half-open opens it again.
+func WithCircuitBreaker(config BreakerConfig) DependencyOption {
+	return func(d *Dependency) {
+		if config.OpenFor <= 0 {
+			config.OpenFor = DefaultBreakerOpenFor
+		}
+		if config.HalfOpenRequests <= 0 {
+			config.HalfOpenRequests = DefaultBreakerHalfOpenRequests
+		}
+		d.breaker = &breaker{config: config}
+	}
+}
+
+// breaker is the circuit breaker of a dependency
+type breaker struct {
+	config BreakerConfig
+
+	mu       sync.Mutex
+	open     bool
+	openedAt time.Time
+	allowed  int
+}
+
+// Allow returns whether a request to the dependency should be made, as per
+// its circuit breaker, see WithCircuitBreaker, or without one whether it was
+// healthy as of the last check. It is cheap enough to call on every request.
+func (d *Dependency) Allow() bool {
+	if d.breaker == nil {
+		return d.IsHealthy()
+	}
+
+	return d.breaker.allow(d.clock().Now())
+}
+
+// BreakerState returns the state of the dependency's circuit breaker, or
+// without one BreakerClosed whilst it is healthy and BreakerOpen otherwise
+func (d *Dependency) BreakerState() BreakerState {
+	if d.breaker == nil {
+		if d.IsHealthy() {
+			return BreakerClosed
+		}
+		return BreakerOpen
+	}
+
+	d.breaker.mu.Lock()
+	defer d.breaker.mu.Unlock()
+	return d.breaker.state(d.clock().Now())
+}
+
+// clone returns a closed breaker with the same configuration
+func (b *breaker) clone() *breaker {
+	if b == nil {
+		return nil
+	}
+
+	return &breaker{config: b.config}
+}
+
+// state returns the state at `now`, b.mu must be held
+func (b *breaker) state(now time.Time) BreakerState {
+	switch {
+	case !b.open:
+		return BreakerClosed
+	case now.Sub(b.openedAt) < b.config.OpenFor:
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state(now) {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if b.allowed < b.config.HalfOpenRequests {
+			b.allowed++
+			return true
+		}
+	}
+	return false
+}
+
+// record moves the circuit on as per a check finding the dependency
+// `healthy` at `now`
+func (b *breaker) record(healthy bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case healthy:
+		b.open, b.allowed = false, 0
+	case b.state(now) != BreakerOpen:
+		b.open, b.openedAt, b.allowed = true, now, 0
+	}
+}
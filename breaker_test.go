@@ -0,0 +1,65 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+	healthy := true
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return healthy },
+		WithCircuitBreaker(BreakerConfig{OpenFor: time.Minute, HalfOpenRequests: 2}))
+
+	if !db.Allow() || db.BreakerState() != BreakerClosed {
+		t.Errorf("expected a closed circuit got %s", db.BreakerState())
+	}
+
+	healthy = false
+	check.Update()
+	if db.Allow() || db.BreakerState() != BreakerOpen {
+		t.Errorf("expected an open circuit got %s", db.BreakerState())
+	}
+
+	// ensure failing checks whilst open don't keep it open
+	clock.advance(30*time.Second, 0)
+	check.Update()
+	clock.advance(30*time.Second, 0)
+	if db.BreakerState() != BreakerHalfOpen {
+		t.Errorf("expected a half-open circuit got %s", db.BreakerState())
+	}
+	for i, expected := range []bool{true, true, false} {
+		if allowed := db.Allow(); allowed != expected {
+			t.Errorf("request %d: expected %v got %v", i, expected, allowed)
+		}
+	}
+
+	// a failing check whilst half-open opens it again
+	check.Update()
+	if db.Allow() || db.BreakerState() != BreakerOpen {
+		t.Errorf("expected a reopened circuit got %s", db.BreakerState())
+	}
+
+	healthy = true
+	check.Update()
+	if !db.Allow() || db.BreakerState() != BreakerClosed {
+		t.Errorf("expected a closed circuit got %s", db.BreakerState())
+	}
+}
+
+func TestAllowWithoutBreaker(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	healthy := false
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+
+	if db.Allow() || db.BreakerState() != BreakerOpen {
+		t.Errorf("expected an unhealthy dependency to be disallowed got %s", db.BreakerState())
+	}
+
+	healthy = true
+	check.Update()
+	if !db.Allow() || db.BreakerState() != BreakerClosed {
+		t.Errorf("expected a healthy dependency to be allowed got %s", db.BreakerState())
+	}
+}
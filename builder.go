@@ -0,0 +1,85 @@
+package health
+
+import "time"
+
+// DependencyBuilder registers a dependency step by step, for dependencies with
+// more settings than RegisterDependency's arguments cover. Use NewDependency
+// to instantiate one:
+//
+//	redis, err := check.NewDependency("redis").
+//		Hard().
+//		Interval(5 * time.Second).
+//		Timeout(time.Second).
+//		Threshold(3, 1).
+//		Check(ping).
+//		Register()
+type DependencyBuilder struct {
+	s     *ServiceCheck
+	name  string
+	level Level
+	check func() bool
+	opts  []DependencyOption
+}
+
+// NewDependency returns a DependencyBuilder for a soft dependency `name` of
+// the service
+func (s *ServiceCheck) NewDependency(name string) *DependencyBuilder {
+	return &DependencyBuilder{s: s, name: name, level: LevelSoft}
+}
+
+// Hard makes the dependency a hard dependency
+func (b *DependencyBuilder) Hard() *DependencyBuilder {
+	return b.Level(LevelHard)
+}
+
+// Soft makes the dependency a soft dependency, the default
+func (b *DependencyBuilder) Soft() *DependencyBuilder {
+	return b.Level(LevelSoft)
+}
+
+// Level sets the level of the dependency
+func (b *DependencyBuilder) Level(level Level) *DependencyBuilder {
+	b.level = level
+	return b
+}
+
+// Interval checks the dependency at most once every `interval` in the
+// check's cycles, reporting the last result in between, for checks more
+// expensive than the service's duration allows, see WithInterval
+func (b *DependencyBuilder) Interval(interval time.Duration) *DependencyBuilder {
+	return b.Options(WithInterval(interval))
+}
+
+// Timeout reports the dependency as unhealthy if the check takes longer than
+// `timeout`, see WithTimeout
+func (b *DependencyBuilder) Timeout(timeout time.Duration) *DependencyBuilder {
+	return b.Options(WithTimeout(timeout))
+}
+
+// Threshold only changes the reported health of the dependency after
+// `failures` consecutive failures or `successes` consecutive successes, see
+// WithThreshold
+func (b *DependencyBuilder) Threshold(failures, successes int) *DependencyBuilder {
+	return b.Options(WithThreshold(failures, successes))
+}
+
+// Check sets the function checking the dependency
+func (b *DependencyBuilder) Check(check func() bool) *DependencyBuilder {
+	b.check = check
+	return b
+}
+
+// Options adds DependencyOptions, such as WithURL
+func (b *DependencyBuilder) Options(opts ...DependencyOption) *DependencyBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Register registers the dependency, as RegisterDependency
+func (b *DependencyBuilder) Register() (*Dependency, error) {
+	if b.check == nil {
+		return nil, b.s.dependencyError(b.name, ErrNoCheck)
+	}
+
+	return b.s.RegisterDependency(b.name, b.level, b.check, b.opts...)
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDependencyBuilder(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	dep, err := check.NewDependency("redis").
+		Hard().
+		Options(WithURL("http://redis/health")).
+		Check(func() bool { return true }).
+		Register()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if dep.Level != LevelHard || dep.URL != "http://redis/health" || !dep.Healthy {
+		t.Errorf("expected a healthy hard dependency with a URL got %+v", dep)
+	}
+
+	if _, err := check.NewDependency("cache").Register(); !errors.Is(err, ErrNoCheck) {
+		t.Errorf("expected %v got %v", ErrNoCheck, err)
+	}
+	if _, err := check.NewDependency("redis").Check(func() bool { return true }).Register(); !errors.Is(err, ErrDependencyAlreadyRegistered) {
+		t.Errorf("expected %v got %v", ErrDependencyAlreadyRegistered, err)
+	}
+}
+
+func TestDependencyBuilderInterval(t *testing.T) {
+	var calls int
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+	check.NewDependency("db").
+		Interval(time.Minute).
+		Check(func() bool { calls++; return true }).
+		Register()
+
+	check.scheduledUpdate()
+	if calls != 1 {
+		t.Errorf("expected 1 call got %d", calls)
+	}
+
+	clock.advance(time.Minute, 0)
+	for i := 0; i < 60; i++ {
+		check.scheduledUpdate()
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls got %d", calls)
+	}
+}
+
+func TestDependencyBuilderTimeout(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	release := make(chan struct{})
+	defer close(release)
+
+	dep, _ := check.NewDependency("db").
+		Timeout(time.Millisecond).
+		Check(func() bool { <-release; return true }).
+		Register()
+	if dep.IsHealthy() {
+		t.Error("expected unhealthy once timed out")
+	}
+}
+
+func TestDependencyBuilderThreshold(t *testing.T) {
+	results := []bool{true, false, false, true}
+	expected := []bool{true, true, false, true}
+
+	var i int
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	dep, _ := check.NewDependency("db").
+		Threshold(2, 1).
+		Check(func() bool { i++; return results[i-1] }).
+		Register()
+
+	for j := range results {
+		if j > 0 {
+			check.Update()
+		}
+		if got := dep.IsHealthy(); got != expected[j] {
+			t.Errorf("expected %v got %v for result %d", expected[j], got, j)
+		}
+	}
+}
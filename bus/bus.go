@@ -0,0 +1,105 @@
+// Package bus fans in health status published by other services over a
+// message bus, such as Kafka or NATS, exposing each as a dependency. This
+// serves meshes where services may not scrape each other over HTTP.
+//
+// Messages are status documents as written by ServiceCheck.WriteStatus. The
+// package doesn't depend on any bus client: feed it messages from your
+// consumer with Ingest or Consume.
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// ErrNoServiceName is returned when an ingested message has no service name
+var ErrNoServiceName = errors.New("message has no service name")
+
+// FanIn holds the latest status received from each service. Use NewFanIn to
+// instantiate one
+type FanIn struct {
+	maxAge time.Duration
+
+	mu       sync.RWMutex
+	statuses map[string]received
+}
+
+type received struct {
+	healthy bool
+	at      time.Time
+}
+
+// NewFanIn returns a FanIn which considers a service unhealthy once no status
+// has been received from it for `maxAge`. Zero disables the age check.
+func NewFanIn(maxAge time.Duration) *FanIn {
+	return &FanIn{
+		maxAge:   maxAge,
+		statuses: make(map[string]received),
+	}
+}
+
+// Ingest decodes a status document and records it as the latest status of the
+// service it names
+func (f *FanIn) Ingest(payload []byte) error {
+	var status struct {
+		Name    string `json:"name"`
+		Healthy bool   `json:"healthy"`
+	}
+	if err := json.Unmarshal(payload, &status); err != nil {
+		return err
+	}
+	if status.Name == "" {
+		return ErrNoServiceName
+	}
+
+	f.mu.Lock()
+	f.statuses[status.Name] = received{healthy: status.Healthy, at: time.Now()}
+	f.mu.Unlock()
+	return nil
+}
+
+// Consume ingests every message received on `messages` until it is closed or
+// ctx is cancelled. Messages which can't be decoded are dropped.
+func (f *FanIn) Consume(ctx context.Context, messages <-chan []byte) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload, ok := <-messages:
+			if !ok {
+				return
+			}
+			f.Ingest(payload)
+		}
+	}
+}
+
+// Check returns a check which is healthy whilst the latest status received
+// from `service` is healthy and recent enough
+func (f *FanIn) Check(service string) func() bool {
+	return func() bool {
+		f.mu.RLock()
+		status, ok := f.statuses[service]
+		f.mu.RUnlock()
+
+		if !ok {
+			return false
+		}
+		if f.maxAge > 0 && time.Since(status.at) > f.maxAge {
+			return false
+		}
+		return status.healthy
+	}
+}
+
+// Register registers `service` as a dependency of `s`, checked against the
+// statuses received from it
+func (f *FanIn) Register(s *health.ServiceCheck, service string, level health.Level, opts ...health.DependencyOption) error {
+	_, err := s.RegisterDependency(service, level, f.Check(service), opts...)
+	return err
+}
@@ -0,0 +1,68 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func status(t *testing.T, name string, healthy bool) []byte {
+	var buf bytes.Buffer
+	if err := (&health.ServiceCheck{Name: name, Healthy: healthy}).WriteStatus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFanIn(t *testing.T) {
+	fanIn := NewFanIn(50 * time.Millisecond)
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	if err := fanIn.Register(check, "users", health.LevelHard); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	users := fanIn.Check("users")
+	if users() {
+		t.Error("expected service to be unhealthy before any status is received")
+	}
+
+	fanIn.Ingest(status(t, "users", true))
+	if !users() {
+		t.Error("expected service to be healthy after a healthy status")
+	}
+
+	fanIn.Ingest(status(t, "users", false))
+	if users() {
+		t.Error("expected service to be unhealthy after an unhealthy status")
+	}
+
+	fanIn.Ingest(status(t, "users", true))
+	time.Sleep(60 * time.Millisecond)
+	if users() {
+		t.Error("expected service to be unhealthy once its status is too old")
+	}
+
+	if err := fanIn.Ingest([]byte(`{"healthy":true}`)); err != ErrNoServiceName {
+		t.Errorf("expected %v got %v", ErrNoServiceName, err)
+	}
+	if err := fanIn.Ingest([]byte(`not json`)); err == nil {
+		t.Error("expected an error from an invalid message")
+	}
+}
+
+func TestFanInConsume(t *testing.T) {
+	fanIn := NewFanIn(0)
+	messages := make(chan []byte, 2)
+	messages <- []byte(`garbage`)
+	messages <- status(t, "orders", true)
+	close(messages)
+
+	fanIn.Consume(context.Background(), messages)
+	if !fanIn.Check("orders")() {
+		t.Error("expected consumed status to be recorded")
+	}
+}
@@ -0,0 +1,167 @@
+// Package cachet keeps Cachet components in line with a ServiceCheck, for
+// teams running a self-hosted Cachet status page.
+package cachet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Component statuses understood by Cachet
+const (
+	StatusOperational       = 1
+	StatusPerformanceIssues = 2
+	StatusPartialOutage     = 3
+	StatusMajorOutage       = 4
+)
+
+// Notifier updates Cachet components as the service and its dependencies
+// change health. Components are only updated on transitions.
+type Notifier struct {
+	// BaseURL of the Cachet installation, for example
+	// https://status.example.com
+	BaseURL string
+	// Token is an API token with permission to update components
+	Token string
+
+	// ServiceComponent is the ID of the component reflecting the service as a
+	// whole: a major outage whilst unhealthy and performance issues whilst
+	// soft dependencies fail. Zero disables it.
+	ServiceComponent int
+	// Components maps dependency names to the ID of the component reflecting
+	// them: a major outage whilst a hard dependency fails and a partial outage
+	// whilst a soft one fails
+	Components map[string]int
+
+	// Client is used to make requests, a client with a 10 second timeout is
+	// used if nil
+	Client *http.Client
+
+	mu   sync.Mutex
+	last map[int]int
+}
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Notify updates every component whose status has changed since it was last
+// successfully updated
+func (n *Notifier) Notify(ctx context.Context, check *health.ServiceCheck) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.last == nil {
+		n.last = make(map[int]int)
+	}
+
+	var errs []error
+	for component, status := range n.statuses(check) {
+		if n.last[component] == status {
+			continue
+		}
+
+		if err := n.update(ctx, component, status); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n.last[component] = status
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("updating %d cachet components failed, first error: %v", len(errs), errs[0])
+	}
+	return nil
+}
+
+// Start calls Notify every `interval` until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context, check *health.ServiceCheck, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n.Notify(ctx, check)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// statuses returns the desired status of every configured component
+func (n *Notifier) statuses(check *health.ServiceCheck) map[int]int {
+	statuses := make(map[int]int, len(n.Components)+1)
+
+	softFailing := false
+	for _, dependency := range check.DependencyStates() {
+		// an informational dependency neither fails nor degrades the service,
+		// so its component stays operational
+		status := StatusOperational
+		if !dependency.Healthy && dependency.Level != health.LevelInformational {
+			status = StatusMajorOutage
+			if dependency.Level == health.LevelSoft {
+				status = StatusPartialOutage
+				softFailing = true
+			}
+		}
+
+		if component, ok := n.Components[dependency.Name]; ok {
+			statuses[component] = status
+		}
+	}
+
+	if n.ServiceComponent != 0 {
+		switch {
+		case !check.IsHealthy():
+			statuses[n.ServiceComponent] = StatusMajorOutage
+		case softFailing:
+			statuses[n.ServiceComponent] = StatusPerformanceIssues
+		default:
+			statuses[n.ServiceComponent] = StatusOperational
+		}
+	}
+
+	return statuses
+}
+
+func (n *Notifier) update(ctx context.Context, component, status int) error {
+	body, err := json.Marshal(map[string]int{"status": status})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/api/v1/components/%d", strings.TrimSuffix(n.BaseURL, "/"), component), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Cachet-Token", n.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("cachet responded to component %d update with status code %d", component, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package cachet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestNotifier(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		updates = map[string]int{}
+		calls   int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.Header.Get("X-Cachet-Token") != "token" {
+			t.Errorf("unexpected request %s with %q", r.Method, r.Header.Get("X-Cachet-Token"))
+		}
+
+		var body struct {
+			Status int `json:"status"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		updates[r.URL.Path] = body.Status
+		calls++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return false })
+	check.Update()
+
+	notifier := &Notifier{
+		BaseURL:          server.URL,
+		Token:            "token",
+		ServiceComponent: 1,
+		Components:       map[string]int{"db": 2},
+	}
+
+	if err := notifier.Notify(context.Background(), check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	notifier.Notify(context.Background(), check)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expected := map[string]int{
+		"/api/v1/components/1": StatusMajorOutage,
+		"/api/v1/components/2": StatusMajorOutage,
+	}
+	for path, status := range expected {
+		if updates[path] != status {
+			t.Errorf("expected %v got %v for %s", status, updates[path], path)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected components to only be updated on transitions got %d calls", calls)
+	}
+}
+
+func TestNotifierInformational(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("audit", health.LevelInformational, func() bool { return false })
+	check.Update()
+
+	notifier := &Notifier{ServiceComponent: 1, Components: map[string]int{"audit": 2}}
+
+	// ensure a failing informational dependency leaves every component operational
+	for component, status := range notifier.statuses(check) {
+		if status != StatusOperational {
+			t.Errorf("expected %v got %v for %v", StatusOperational, status, component)
+		}
+	}
+}
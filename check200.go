@@ -0,0 +1,207 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultCheck200MaxRedirects is the number of redirects Check200Helper
+	// follows when Check200Options.MaxRedirects is not set, as many as
+	// net/http does
+	DefaultCheck200MaxRedirects = 10
+	// DefaultCheck200DrainLimit is how much of the body Check200Helper reads
+	// before closing it when Check200Options.DrainLimit is not set, so the
+	// connection can be reused without a large body pinning it
+	DefaultCheck200DrainLimit = 4 << 10
+)
+
+// defaultCheck200Schemes are the schemes Check200Helper allows when
+// Check200Options.Schemes is not set
+var defaultCheck200Schemes = []string{"http", "https"}
+
+// Check200Options hardens Check200HelperWithOptions against URLs sourced from
+// config and slow upstreams
+type Check200Options struct {
+	// Client makes the request, a NewHTTPClient is used if nil
+	Client *http.Client
+	// MaxRedirects is the number of redirects followed,
+	// DefaultCheck200MaxRedirects if zero. Negative follows none, reporting
+	// the redirect itself, which isn't a 200.
+	MaxRedirects int
+	// Schemes are the schemes of URLs, including redirects, which are
+	// requested, only http and https if nil
+	Schemes []string
+	// ResponseHeaderTimeout limits how long to wait for the response headers,
+	// on top of the client's timeout, if set
+	ResponseHeaderTimeout time.Duration
+	// DrainLimit is how much of the body is read before it is closed,
+	// DefaultCheck200DrainLimit if zero. Negative closes it unread.
+	DrainLimit int64
+
+	// Method of the request, GET if empty
+	Method string
+	// Header is added to the request, for example to authenticate
+	Header http.Header
+	// ExpectedStatus are the status codes reported healthy, only 200 if nil
+	ExpectedStatus []int
+	// BodyContains must be found within the first MaxResponseSize bytes of
+	// the body for it to be healthy, if set
+	BodyContains string
+	// TLSConfig of the connection, in place of the client transport's, if set
+	TLSConfig *tls.Config
+}
+
+// Check200HelperWithOptions is Check200Helper with options limiting the
+// redirects followed, the schemes requested, how long to wait for headers and
+// how much of the body is read
+func Check200HelperWithOptions(rawURL string, opts Check200Options) (bool, error) {
+	client := opts.Client
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	maxRedirects := opts.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = DefaultCheck200MaxRedirects
+	}
+	schemes := opts.Schemes
+	if schemes == nil {
+		schemes = defaultCheck200Schemes
+	}
+	drainLimit := opts.DrainLimit
+	if drainLimit == 0 {
+		drainLimit = DefaultCheck200DrainLimit
+	}
+
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return false, err
+	}
+	if !allowedScheme(u, schemes) {
+		return false, ErrUnsupportedScheme
+	}
+
+	// a copy of the client, so its redirect policy isn't changed for others
+	limited := *client
+	limited.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if maxRedirects < 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return ErrTooManyRedirects
+		}
+		if !allowedScheme(req.URL, schemes) {
+			return ErrUnsupportedScheme
+		}
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(req, via)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	var timer *time.Timer
+	if opts.ResponseHeaderTimeout > 0 {
+		timer = time.AfterFunc(opts.ResponseHeaderTimeout, func() {
+			cancel(ErrResponseHeaderTimeout)
+		})
+		defer timer.Stop()
+	}
+
+	if opts.TLSConfig != nil {
+		transport := withTLSConfig(limited.Transport, opts.TLSConfig)
+		defer transport.CloseIdleConnections()
+		limited.Transport = transport
+	}
+
+	method := opts.Method
+	if method == "" {
+		method = "GET"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range opts.Header {
+		req.Header[key] = values
+	}
+
+	resp, err := limited.Do(req)
+	// the headers are in, so the timeout mustn't cut the body short
+	if timer != nil && !timer.Stop() && err == nil {
+		resp.Body.Close()
+		return false, ErrResponseHeaderTimeout
+	}
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			return false, cause
+		}
+		return false, err
+	}
+
+	// ensure resp.Body is drained and closed when function returns
+	defer func() {
+		if drainLimit > 0 {
+			io.CopyN(io.Discard, resp.Body, drainLimit)
+		}
+		resp.Body.Close()
+	}()
+
+	if !expectedStatus(resp.StatusCode, opts.ExpectedStatus) {
+		return false, nil
+	}
+	if opts.BodyContains != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize))
+		if err != nil {
+			return false, err
+		}
+		return strings.Contains(string(body), opts.BodyContains), nil
+	}
+
+	return true, nil
+}
+
+// expectedStatus returns whether `code` is one of `expected`, or 200 if there
+// are none
+func expectedStatus(code int, expected []int) bool {
+	if expected == nil {
+		return code == http.StatusOK
+	}
+
+	for _, e := range expected {
+		if code == e {
+			return true
+		}
+	}
+	return false
+}
+
+// withTLSConfig returns a copy of `rt`, or of the default transport if it
+// isn't an *http.Transport, using `config`
+func withTLSConfig(rt http.RoundTripper, config *tls.Config) *http.Transport {
+	transport, ok := rt.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = config
+	return transport
+}
+
+// allowedScheme returns whether the scheme of `u` is one of `schemes`
+func allowedScheme(u *url.URL, schemes []string) bool {
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheck200HelperSchemes(t *testing.T) {
+	for _, rawURL := range []string{"file:///etc/passwd", "gopher://example.com/"} {
+		if healthy, err := Check200Helper(rawURL); healthy || !errors.Is(err, ErrUnsupportedScheme) {
+			t.Errorf("expected %v got %v (%v) for %s", ErrUnsupportedScheme, err, healthy, rawURL)
+		}
+	}
+
+	// redirects to other schemes aren't followed either
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, err := Check200Helper(server.URL); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("expected %v got %v", ErrUnsupportedScheme, err)
+	}
+}
+
+func TestCheck200HelperRedirects(t *testing.T) {
+	// redirects `n` times before responding
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n > 0 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		redirects    int
+		maxRedirects int
+		healthy      bool
+		err          error
+	}{
+		{3, 0, true, nil},
+		{3, 3, true, nil},
+		{4, 3, false, ErrTooManyRedirects},
+		{11, 0, false, ErrTooManyRedirects},
+		{1, -1, false, nil},
+	}
+
+	for _, test := range tests {
+		healthy, err := Check200HelperWithOptions(server.URL+"/"+strconv.Itoa(test.redirects), Check200Options{MaxRedirects: test.maxRedirects})
+		if healthy != test.healthy || !errors.Is(err, test.err) {
+			t.Errorf("expected %v (%v) got %v (%v) for %d redirects", test.healthy, test.err, healthy, err, test.redirects)
+		}
+	}
+}
+
+func TestCheck200HelperResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	healthy, err := Check200HelperWithOptions(server.URL, Check200Options{ResponseHeaderTimeout: 10 * time.Millisecond})
+	if healthy || !errors.Is(err, ErrResponseHeaderTimeout) {
+		t.Errorf("expected %v got %v (%v)", ErrResponseHeaderTimeout, err, healthy)
+	}
+}
+
+func TestCheck200HelperResponseHeaderTimeoutSlowBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(150 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// ensure the timeout only applies until the headers arrive
+	healthy, err := Check200HelperWithOptions(server.URL, Check200Options{
+		ResponseHeaderTimeout: 50 * time.Millisecond,
+		BodyContains:          "ok",
+	})
+	if !healthy || err != nil {
+		t.Errorf("expected true got %v (%v)", healthy, err)
+	}
+
+	healthy, err = CheckHTTP(server.URL, WithResponseHeaderTimeout(50*time.Millisecond), WithBodyContains("ok"))
+	if !healthy || err != nil {
+		t.Errorf("expected true got %v (%v)", healthy, err)
+	}
+}
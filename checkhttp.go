@@ -0,0 +1,77 @@
+package health
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// HTTPCheckOption configures CheckHTTP
+type HTTPCheckOption func(*Check200Options)
+
+// CheckHTTP checks the endpoint at `rawURL` as Check200Helper, but with
+// options for authenticated endpoints, HEAD requests and services responding
+// with other codes than 200:
+//
+//	healthy, err := health.CheckHTTP(url,
+//		health.WithExpectedStatus(200, 204),
+//		health.WithHeader("Authorization", token),
+//		health.WithBodyContains("ok"),
+//	)
+func CheckHTTP(rawURL string, opts ...HTTPCheckOption) (bool, error) {
+	var options Check200Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return Check200HelperWithOptions(rawURL, options)
+}
+
+// WithExpectedStatus reports the endpoint healthy when it responds with any
+// of `codes`, rather than only 200
+func WithExpectedStatus(codes ...int) HTTPCheckOption {
+	return func(o *Check200Options) {
+		o.ExpectedStatus = append(o.ExpectedStatus, codes...)
+	}
+}
+
+// WithHeader adds the header `key` with `value` to the request
+func WithHeader(key, value string) HTTPCheckOption {
+	return func(o *Check200Options) {
+		if o.Header == nil {
+			o.Header = make(http.Header)
+		}
+		o.Header.Add(key, value)
+	}
+}
+
+// WithMethod makes the request with `method`, such as HEAD, rather than GET
+func WithMethod(method string) HTTPCheckOption {
+	return func(o *Check200Options) {
+		o.Method = method
+	}
+}
+
+// WithBodyContains only reports the endpoint healthy if its body contains
+// `s`
+func WithBodyContains(s string) HTTPCheckOption {
+	return func(o *Check200Options) {
+		o.BodyContains = s
+	}
+}
+
+// WithTLSConfig connects to the endpoint with `config`, for example to trust
+// a private CA or present a client certificate
+func WithTLSConfig(config *tls.Config) HTTPCheckOption {
+	return func(o *Check200Options) {
+		o.TLSConfig = config
+	}
+}
+
+// WithResponseHeaderTimeout fails the check if the endpoint takes longer than
+// `timeout` to respond with its headers
+func WithResponseHeaderTimeout(timeout time.Duration) HTTPCheckOption {
+	return func(o *Check200Options) {
+		o.ResponseHeaderTimeout = timeout
+	}
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == "HEAD":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte(`{"status":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	auth := WithHeader("Authorization", "Bearer token")
+	tests := []struct {
+		opts     []HTTPCheckOption
+		expected bool
+	}{
+		{nil, false},
+		{[]HTTPCheckOption{auth}, true},
+		{[]HTTPCheckOption{auth, WithMethod("HEAD")}, false},
+		{[]HTTPCheckOption{auth, WithMethod("HEAD"), WithExpectedStatus(200, 204)}, true},
+		{[]HTTPCheckOption{WithExpectedStatus(401)}, true},
+		{[]HTTPCheckOption{auth, WithBodyContains(`"ok"`)}, true},
+		{[]HTTPCheckOption{auth, WithBodyContains(`"down"`)}, false},
+	}
+
+	for i, test := range tests {
+		healthy, err := CheckHTTP(server.URL, test.opts...)
+		if err != nil {
+			t.Errorf("expected nil got %v on test case #%d", err, i)
+		}
+		if healthy != test.expected {
+			t.Errorf("expected %v got %v on test case #%d", test.expected, healthy, i)
+		}
+	}
+}
+
+func TestCheckHTTPTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// the server's certificate isn't trusted by default
+	if healthy, err := CheckHTTP(server.URL); healthy || err == nil {
+		t.Errorf("expected a certificate error got %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	if healthy, err := CheckHTTP(server.URL, WithTLSConfig(&tls.Config{RootCAs: pool})); !healthy || err != nil {
+		t.Errorf("expected healthy got %v (%v)", healthy, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package checks provides ready-made checks of common dependencies, each
+// returning a function for ServiceCheck.RegisterDependency:
+//
+//	check.RegisterDependency("db", health.LevelHard, checks.SQL(db))
+//	check.RegisterDependency("search", health.LevelSoft, checks.TCP("search:9200", time.Second))
+package checks
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"time"
+)
+
+// DefaultTimeout bounds the checks which aren't given a timeout
+const DefaultTimeout = 2 * time.Second
+
+// SQL returns a check which is healthy whilst `db` can be pinged within
+// DefaultTimeout
+func SQL(db *sql.DB) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+		defer cancel()
+
+		return db.PingContext(ctx) == nil
+	}
+}
+
+// TCP returns a check which is healthy whilst a TCP connection can be opened
+// to `addr` within `timeout`
+func TCP(addr string, timeout time.Duration) func() bool {
+	return func() bool {
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err != nil {
+			return false
+		}
+
+		conn.Close()
+		return true
+	}
+}
+
+// DNS returns a check which is healthy whilst `host` resolves to at least one
+// address within DefaultTimeout
+func DNS(host string) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+		defer cancel()
+
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		return err == nil && len(addrs) > 0
+	}
+}
+
+// Pinger is a client which can be pinged, such as a Redis client
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingerFunc adapts a function to a Pinger, for clients whose Ping doesn't
+// return an error directly:
+//
+//	checks.Redis(checks.PingerFunc(func(ctx context.Context) error {
+//		return client.Ping(ctx).Err()
+//	}))
+type PingerFunc func(ctx context.Context) error
+
+// Ping calls f
+func (f PingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Redis returns a check which is healthy whilst `pinger` can be pinged within
+// DefaultTimeout
+func Redis(pinger Pinger) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+		defer cancel()
+
+		return pinger.Ping(ctx) == nil
+	}
+}
@@ -0,0 +1,95 @@
+package checks
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeDriver opens connections which ping as healthy or not
+type fakeDriver struct{}
+
+type fakeConn struct {
+	healthy bool
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{healthy: name == "healthy"}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("unsupported") }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("unsupported") }
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	if !c.healthy {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+func TestSQL(t *testing.T) {
+	for _, name := range []string{"healthy", "unhealthy"} {
+		db, _ := sql.Open("fake", name)
+		if got := SQL(db)(); got != (name == "healthy") {
+			t.Errorf("expected %v got %v", name == "healthy", got)
+		}
+		db.Close()
+	}
+}
+
+func TestTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	addr := listener.Addr().String()
+
+	if !TCP(addr, time.Second)() {
+		t.Errorf("expected true got false")
+	}
+
+	listener.Close()
+	if TCP(addr, time.Second)() {
+		t.Errorf("expected false got true")
+	}
+}
+
+func TestDNS(t *testing.T) {
+	if !DNS("localhost")() {
+		t.Errorf("expected true got false")
+	}
+	if DNS("does-not-exist.invalid")() {
+		t.Errorf("expected false got true")
+	}
+}
+
+func TestRedis(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, true},
+		{errors.New("connection refused"), false},
+	}
+
+	for _, test := range tests {
+		pinger := PingerFunc(func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("expected a deadline")
+			}
+			return test.err
+		})
+		if got := Redis(pinger)(); got != test.expected {
+			t.Errorf("expected %v got %v", test.expected, got)
+		}
+	}
+}
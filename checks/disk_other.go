@@ -0,0 +1,14 @@
+//go:build !unix
+
+package checks
+
+import "errors"
+
+// errUnsupported is returned where free disk space can't be measured
+var errUnsupported = errors.New("unsupported platform")
+
+// freeBytes can't measure the free bytes on this platform, so DiskFree is
+// always unhealthy
+func freeBytes(path string) (uint64, error) {
+	return 0, errUnsupported
+}
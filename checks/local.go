@@ -0,0 +1,37 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// FileExists returns a check which is healthy whilst `path` exists, such as
+// a socket or a file written by another process
+func FileExists(path string) func() bool {
+	return func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+}
+
+// DiskFree returns a check which is healthy whilst the filesystem holding
+// `path` has at least `minBytes` free for unprivileged users
+func DiskFree(path string, minBytes uint64) func() bool {
+	return func() bool {
+		free, err := freeBytes(path)
+		return err == nil && free >= minBytes
+	}
+}
+
+// Command returns a check which is healthy whilst the command `name` with
+// `args` exits with 0 within DefaultTimeout. The command is killed once ctx
+// is done.
+func Command(ctx context.Context, name string, args ...string) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+
+		return exec.CommandContext(ctx, name, args...).Run() == nil
+	}
+}
@@ -0,0 +1,68 @@
+package checks
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileExists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ready")
+
+	if FileExists(path)() {
+		t.Errorf("expected a missing file to be unhealthy")
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if !FileExists(path)() {
+		t.Errorf("expected an existing file to be healthy")
+	}
+}
+
+func TestDiskFree(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		path     string
+		minBytes uint64
+		expected bool
+	}{
+		{dir, 1, true},
+		{dir, math.MaxUint64, false},
+		{filepath.Join(dir, "missing"), 1, false},
+	}
+
+	for _, test := range tests {
+		if healthy := DiskFree(test.path, test.minBytes)(); healthy != test.expected {
+			t.Errorf("expected %v got %v for %d bytes", test.expected, healthy, test.minBytes)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"sh", []string{"-c", "exit 0"}, true},
+		{"sh", []string{"-c", "exit 1"}, false},
+		{"no-such-command", nil, false},
+	}
+
+	for _, test := range tests {
+		if healthy := Command(context.Background(), test.name, test.args...)(); healthy != test.expected {
+			t.Errorf("expected %v got %v for %s %v", test.expected, healthy, test.name, test.args)
+		}
+	}
+
+	// ensure the command is killed once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if Command(ctx, "sh", "-c", "exit 0")() {
+		t.Errorf("expected a cancelled command to be unhealthy")
+	}
+}
@@ -0,0 +1,23 @@
+// Package chihealth mounts the health handler on chi routers. chi is built on
+// net/http, so the request reaches the native handler untouched and
+// ServiceCheck.ShedMiddleware can be passed straight to Use.
+package chihealth
+
+import (
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fresh8/health"
+)
+
+// Mount serves the status of `check` on `r` at `path`
+func Mount(r chi.Router, path string, check *health.ServiceCheck) {
+	r.Get(path, check.HTTPHandler)
+}
+
+// MountRegistry serves the combined status of `registry` on `r` at `path` and
+// the status of each ServiceCheck at `path/{service}`
+func MountRegistry(r chi.Router, path string, registry *health.Registry) {
+	h := registry.Handler(path)
+	r.Get(path, h.ServeHTTP)
+	r.Get(path+"/{service}", h.ServeHTTP)
+}
@@ -0,0 +1,64 @@
+package chihealth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fresh8/health"
+)
+
+func TestMount(t *testing.T) {
+	check := &health.ServiceCheck{Name: "api"}
+
+	r := chi.NewRouter()
+	r.Use(check.ShedMiddleware(time.Second))
+	Mount(r, "/health", check)
+
+	tests := []struct {
+		healthy  bool
+		expected int
+	}{
+		{true, 200},
+		{false, 503},
+	}
+
+	for _, test := range tests {
+		check.Healthy = test.healthy
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v", test.expected, w.Code)
+		}
+	}
+}
+
+func TestMountRegistry(t *testing.T) {
+	registry := health.NewRegistry()
+	registry.Register(&health.ServiceCheck{Name: "api", Healthy: true})
+	registry.Register(&health.ServiceCheck{Name: "worker", Healthy: false})
+
+	r := chi.NewRouter()
+	MountRegistry(r, "/health", registry)
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/health", 503},
+		{"/health/api", 200},
+		{"/health/worker", 503},
+		{"/health/missing", 404},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, w.Code, test.path)
+		}
+	}
+}
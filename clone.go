@@ -0,0 +1,99 @@
+package health
+
+// Clone returns an independent copy of the service with the same dependencies
+// and configuration but fresh state: healthy, unless WithInitialHealth says
+// otherwise, neither starting nor draining, and not yet checked. The clone
+// isn't started, nor does it save its state, see WithStore. It is intended for
+// tests which fork a production configured check and simulate failures on it,
+// see Pause and SetHealthy, without touching the live instance.
+//
+// The clone's dependencies call the same check functions as the original's,
+// so checks holding state of their own, such as thresholds, share it.
+func (s *ServiceCheck) Clone() *ServiceCheck {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	clone := &ServiceCheck{
+		Name:         s.Name,
+		Healthy:      !s.startUnhealthy,
+		Dependencies: make([]*Dependency, len(s.Dependencies)),
+
+		duration:        s.duration,
+		clock:           s.clock,
+		client:          s.client,
+		namePolicy:      s.namePolicy,
+		duplicatePolicy: s.duplicatePolicy,
+		order:           s.order,
+		stopGrace:       s.stopGrace,
+		checkTimeout:    s.checkTimeout,
+		checkTimes:      s.checkTimes,
+		degradedCode:    s.degradedCode,
+		aggregator:      s.aggregator,
+		unhealthyCode:   s.unhealthyCode,
+		startUnhealthy:  s.startUnhealthy,
+		handlerMode:     s.handlerMode,
+		logger:          s.logger,
+		waitBackoff:     s.waitBackoff,
+		waitMaxBackoff:  s.waitMaxBackoff,
+		startupGrace:    s.startupGrace,
+		graceUntil:      s.graceUntil,
+		concurrency:     s.concurrency,
+		queueSize:       s.queueSize,
+		overrun:         s.overrun,
+		notifyQueue:     s.notifyQueue,
+		notifyTimeout:   s.notifyTimeout,
+		schedule:        s.schedule,
+		rename:          s.rename,
+		marshalHook:     s.marshalHook,
+		metadata:        s.metadata,
+		sseHeartbeat:    s.sseHeartbeat,
+		redact:          s.redact,
+		history:         s.history.clone(),
+		limiter:         s.limiter,
+		tracer:          s.tracer,
+		env:             s.env,
+		ignoreEnv:       s.ignoreEnv,
+	}
+
+	for i, dependency := range s.Dependencies {
+		dep := &Dependency{
+			Name:    dependency.Name,
+			Healthy: true,
+			Level:   dependency.Level,
+			URL:     dependency.URL,
+
+			check:      dependency.check,
+			errs:       dependency.errs,
+			panics:     &checkErrors{},
+			probes:     dependency.probes,
+			interval:   dependency.interval,
+			schedule:   dependency.schedule,
+			timeout:    dependency.timeout,
+			maxLatency: dependency.maxLatency,
+			weight:     dependency.weight,
+			failures:   dependency.failures,
+			successes:  dependency.successes,
+			group:      dependency.group,
+			breaker:    dependency.breaker.clone(),
+			adaptive:   dependency.adaptive,
+			ttl:        dependency.ttl,
+			owner:      clone,
+		}
+		if dependency.remote != nil {
+			dep.remote = dependency.remote.clone()
+			dep.remote.context = clone.context
+			dep.check = dep.remote.check
+		}
+		if dependency.async != nil {
+			dep.async = dependency.async.clone()
+			dep.check = dep.readAsync
+		}
+
+		clone.Dependencies[i] = dep
+		if s.watchdog != nil && s.watchdog.dep == dependency {
+			clone.watchdog = &watchdog{cycles: s.watchdog.cycles, level: s.watchdog.level, dep: dep}
+		}
+	}
+
+	return clone
+}
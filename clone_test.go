@@ -0,0 +1,53 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("users", time.Second)
+		check.HTTPHandler(w, r)
+	}))
+	defer remote.Close()
+
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithDuplicatePolicy(DuplicateReplace))
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterRemoteService("users", remote.URL, LevelSoft, WithRemoteDetail(0))
+	check.Drain()
+
+	clone := check.Clone()
+	if clone.Name != "api" || clone.IsDraining() || !clone.LastChecked().IsZero() || clone.duplicatePolicy != DuplicateReplace {
+		t.Errorf("expected the configuration without the state got %+v", clone)
+	}
+
+	db, err := clone.Dependency("db")
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	db.SetHealthy(false)
+	if clone.IsHealthy() || !check.IsHealthy() {
+		t.Error("expected the failure to affect only the clone")
+	}
+
+	users, _ := clone.Dependency("users")
+	if users.Remote != nil || users.remote == nil {
+		t.Error("expected the remote check without its last status")
+	}
+
+	clone.Update()
+	users, _ = clone.Dependency("users")
+	if users.Remote == nil || users.Remote.Name != "users" {
+		t.Errorf("expected the clone to fetch the remote status got %+v", users.Remote)
+	}
+
+	if _, err := clone.RegisterDependency("cache", LevelSoft, func() bool { return true }); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if len(check.DependencyStates()) != 2 {
+		t.Error("expected registering on the clone not to affect the original")
+	}
+}
@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Cluster aggregates the health of an instance with that of its peers, for
+// active-active deployments where every replica serves the same traffic. Use
+// NewCluster to instantiate one
+type Cluster struct {
+	self     *ServiceCheck
+	peers    []string
+	interval time.Duration
+	client   *http.Client
+
+	results map[string]Result
+	mu      sync.RWMutex
+}
+
+// ClusterStatus summarises the health of every replica in a Cluster,
+// including the local instance
+type ClusterStatus struct {
+	Healthy int          `json:"healthy"`
+	Total   int          `json:"total"`
+	Summary string       `json:"summary"`
+	Peers   []PeerStatus `json:"peers"`
+}
+
+// PeerStatus is the last observed health of a single peer
+type PeerStatus struct {
+	URL     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewCluster returns a Cluster for the local instance `self` and the health
+// endpoints of its peers, which will be polled every `interval` once Start is
+// called. Peers are polled with the client of `self`, see WithHTTPClient.
+// Function supports passing an optional *http.Client to use a different
+// timeout for polling peers.
+func NewCluster(self *ServiceCheck, peers []string, interval time.Duration, optionalClient ...*http.Client) *Cluster {
+	client := self.getHTTPClient()
+	if len(optionalClient) > 0 {
+		client = optionalClient[0]
+	}
+
+	return &Cluster{
+		self:     self,
+		peers:    peers,
+		interval: interval,
+		client:   client,
+		results:  make(map[string]Result),
+	}
+}
+
+// Start polls the peers in the background until ctx is cancelled
+func (c *Cluster) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.Refresh(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SetPeers replaces the health endpoints of the peers, taking effect from the
+// next Refresh
+func (c *Cluster) SetPeers(peers []string) {
+	c.mu.Lock()
+	c.peers = peers
+	c.mu.Unlock()
+}
+
+// Refresh polls every peer once and records the results
+func (c *Cluster) Refresh(ctx context.Context) {
+	c.mu.RLock()
+	peers := c.peers
+	c.mu.RUnlock()
+
+	results := GetAll(ctx, peers, c.client)
+
+	c.mu.Lock()
+	c.results = results
+	c.mu.Unlock()
+}
+
+// Status returns the health of the cluster as of the last Refresh. Peers which
+// haven't been polled yet are reported as unhealthy.
+func (c *Cluster) Status() ClusterStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := ClusterStatus{
+		Total: len(c.peers) + 1,
+		Peers: make([]PeerStatus, 0, len(c.peers)),
+	}
+	if c.self.IsHealthy() {
+		status.Healthy++
+	}
+
+	for _, peer := range c.peers {
+		result, ok := c.results[peer]
+		peerStatus := PeerStatus{
+			URL:     peer,
+			Healthy: result.Healthy,
+		}
+		switch {
+		case !ok:
+			peerStatus.Error = "not yet polled"
+		case result.Err != nil:
+			peerStatus.Error = result.Err.Error()
+		}
+
+		if peerStatus.Healthy {
+			status.Healthy++
+		}
+		status.Peers = append(status.Peers, peerStatus)
+	}
+
+	status.Summary = fmt.Sprintf("%d/%d replicas healthy", status.Healthy, status.Total)
+	return status
+}
+
+// HTTPHandler outputs the status of the local instance alongside the cluster
+// status. The response code reflects the local instance only, so load
+// balancers keep routing to healthy replicas regardless of their peers.
+func (c *Cluster) HTTPHandler(w http.ResponseWriter, r *http.Request) {
+	setContentType(w)
+	if c.self.IsHealthy() {
+		w.WriteHeader(200)
+	} else {
+		w.WriteHeader(503)
+	}
+
+	status := c.self.load().status
+	b, _ := status.marshal(struct {
+		StatusDocument
+		Cluster ClusterStatus `json:"cluster"`
+	}{status.document(), c.Status()})
+	w.Write(append(b, '\n'))
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCluster(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&ServiceCheck{Name: "test", Healthy: true})
+	}))
+	defer healthy.Close()
+
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(&ServiceCheck{Name: "test"})
+	}))
+	defer unhealthy.Close()
+
+	self, _ := InitialiseServiceCheck("test", time.Second)
+	cluster := NewCluster(self, []string{healthy.URL, unhealthy.URL}, time.Second)
+
+	status := cluster.Status()
+	if status.Healthy != 1 || status.Total != 3 {
+		t.Errorf("expected 1/3 before polling got %s", status.Summary)
+	}
+
+	cluster.Refresh(context.Background())
+
+	status = cluster.Status()
+	if status.Summary != "2/3 replicas healthy" {
+		t.Errorf("expected %q got %q", "2/3 replicas healthy", status.Summary)
+	}
+	if len(status.Peers) != 2 || !status.Peers[0].Healthy || status.Peers[1].Healthy {
+		t.Errorf("unexpected peer statuses %+v", status.Peers)
+	}
+
+	rec := httptest.NewRecorder()
+	cluster.HTTPHandler(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected %d got %d", http.StatusOK, rec.Code)
+	}
+
+	var body struct {
+		Name    string        `json:"name"`
+		Healthy bool          `json:"healthy"`
+		Cluster ClusterStatus `json:"cluster"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Name != "test" || !body.Healthy {
+		t.Errorf("expected own status to be included got %+v", body)
+	}
+	if body.Cluster.Healthy != 2 {
+		t.Errorf("expected 2 healthy replicas got %d", body.Cluster.Healthy)
+	}
+}
@@ -0,0 +1,34 @@
+// Command check_health is a Nagios plugin checking a health endpoint served by
+// a ServiceCheck.
+//
+//	check_health -url http://localhost:8080/health -timeout 5s
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fresh8/health/nagios"
+)
+
+func main() {
+	url := flag.String("url", "", "health endpoint to check")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout for the check")
+	flag.Parse()
+
+	if *url == "" {
+		fmt.Println("HEALTH UNKNOWN - -url is required")
+		os.Exit(int(nagios.Unknown))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	result := nagios.Check(ctx, *url, &http.Client{Timeout: *timeout})
+	cancel()
+
+	fmt.Println(result.Output)
+	os.Exit(int(result.Code))
+}
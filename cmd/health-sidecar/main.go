@@ -0,0 +1,32 @@
+// Command health-sidecar runs the checks described in a config file on behalf
+// of another application and serves their status, see package sidecar.
+//
+//	health-sidecar -config /etc/health-sidecar.json
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/fresh8/health/sidecar"
+)
+
+func main() {
+	path := flag.String("config", "health-sidecar.json", "config file describing the checks")
+	flag.Parse()
+
+	config, err := sidecar.LoadConfig(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := sidecar.Run(ctx, config); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,15 @@
+// Command healthcheck probes a health endpoint served by a ServiceCheck and
+// exits 0 when healthy and 1 otherwise, for use as a Docker HEALTHCHECK.
+//
+//	HEALTHCHECK CMD ["healthcheck", "-url", "http://localhost:8080/health"]
+package main
+
+import (
+	"os"
+
+	"github.com/fresh8/health/healthcheck"
+)
+
+func main() {
+	os.Exit(healthcheck.Run(os.Args[1:], os.Stdout))
+}
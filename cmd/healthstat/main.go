@@ -0,0 +1,155 @@
+// Command healthstat fetches one or more health endpoints served by a
+// ServiceCheck and prints a table of the services and their dependencies. It
+// exits 1 if any service is unhealthy or couldn't be reached, for use in CI
+// smoke tests.
+//
+//	healthstat -watch 2s http://api:8080/health http://worker:8080/health
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+const (
+	green  = "\033[32m"
+	red    = "\033[31m"
+	yellow = "\033[33m"
+	reset  = "\033[0m"
+)
+
+// row is the outcome of fetching a single endpoint
+type row struct {
+	URL     string
+	Status  *health.RemoteStatus
+	Latency time.Duration
+	Err     error
+}
+
+func main() {
+	watch := flag.Duration("watch", 0, "refresh the table every interval until interrupted")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout for each request")
+	noColor := flag.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable coloured output")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: healthstat [flags] url...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	for {
+		rows := fetchAll(context.Background(), client, flag.Args())
+		if *watch > 0 {
+			fmt.Print("\033[H\033[2J")
+		}
+		healthy := render(os.Stdout, rows, !*noColor && isTerminal(os.Stdout))
+
+		if *watch == 0 {
+			if !healthy {
+				os.Exit(1)
+			}
+			return
+		}
+		time.Sleep(*watch)
+	}
+}
+
+// fetchAll fetches every endpoint in parallel, returning the rows in the order
+// of `urls`
+func fetchAll(ctx context.Context, client *http.Client, urls []string) []row {
+	rows := make([]row, len(urls))
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			rows[i] = fetch(ctx, client, url)
+		}(i, url)
+	}
+	wg.Wait()
+
+	return rows
+}
+
+// fetch requests the status document served at url
+func fetch(ctx context.Context, client *http.Client, url string) row {
+	r := row{URL: url}
+
+	start := time.Now()
+	r.Status, r.Err = health.GetStatus(ctx, url, health.WithStatusClient(client))
+	r.Latency = time.Since(start)
+	return r
+}
+
+// isTerminal returns whether `f` is a terminal, so colour isn't written into
+// pipes and files
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// latency formats the latency of a dependency's last check, which services
+// include with health.WithCheckTimes
+func latency(ms *float64) string {
+	if ms == nil {
+		return "-"
+	}
+	return time.Duration(*ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
+
+// render writes the table and returns whether every service is healthy
+func render(w io.Writer, rows []row, color bool) bool {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + reset
+	}
+	state := func(healthy bool) string {
+		if healthy {
+			return paint(green, "healthy")
+		}
+		return paint(red, "unhealthy")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tDEPENDENCY\tLEVEL\tSTATE\tLATENCY\tERROR")
+
+	allHealthy := true
+	for _, r := range rows {
+		took := r.Latency.Round(time.Millisecond).String()
+
+		if r.Err != nil {
+			allHealthy = false
+			fmt.Fprintf(tw, "%s\t-\t-\t%s\t%s\t%v\n", r.URL, paint(yellow, "unknown"), took, r.Err)
+			continue
+		}
+
+		if !r.Status.Healthy {
+			allHealthy = false
+		}
+		fmt.Fprintf(tw, "%s\t-\t-\t%s\t%s\t\n", r.Status.Name, state(r.Status.Healthy), took)
+
+		for _, dependency := range r.Status.Dependencies {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Status.Name, dependency.Name, dependency.Level, state(dependency.Healthy), latency(dependency.LatencyMs), dependency.LastError)
+		}
+	}
+
+	tw.Flush()
+	return allHealthy
+}
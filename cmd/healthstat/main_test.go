@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fresh8/health"
+)
+
+func TestRender(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", 0, health.WithCheckTimes())
+	check.RegisterDependencyContext("db", health.LevelHard, func(ctx context.Context) (bool, error) {
+		return false, errors.New("connection reset")
+	})
+	check.Update()
+
+	server := httptest.NewServer(http.HandlerFunc(check.HTTPHandler))
+	defer server.Close()
+
+	// ensure oversized documents are rejected rather than read into memory
+	large := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"` + strings.Repeat("a", int(health.MaxResponseSize)) + `"}`))
+	}))
+	defer large.Close()
+
+	tests := []struct {
+		urls     []string
+		expected bool
+		output   []string
+	}{
+		{[]string{server.URL}, false, []string{"api", "db", "hard", "unhealthy", "0s", "connection reset"}},
+		{[]string{large.URL}, false, []string{"unknown", health.ErrResponseTooLarge.Error()}},
+		{[]string{"http://127.0.0.1:1"}, false, []string{"unknown", "connection refused"}},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		rows := fetchAll(context.Background(), http.DefaultClient, test.urls)
+		if healthy := render(&out, rows, false); healthy != test.expected {
+			t.Errorf("expected %v got %v", test.expected, healthy)
+		}
+
+		for _, s := range test.output {
+			if !strings.Contains(out.String(), s) {
+				t.Errorf("expected %q in %q", s, out.String())
+			}
+		}
+	}
+}
+
+func TestRenderHealthy(t *testing.T) {
+	var out bytes.Buffer
+	rows := []row{{URL: "http://api", Status: &health.RemoteStatus{StatusDocument: health.StatusDocument{Name: "api", Healthy: true}}}}
+	if !render(&out, rows, true) {
+		t.Error("expected healthy")
+	}
+	if !strings.Contains(out.String(), green+"healthy"+reset) {
+		t.Errorf("expected coloured state got %q", out.String())
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// ensure colour isn't written to files
+	if isTerminal(f) {
+		t.Errorf("expected %v got %v", false, true)
+	}
+}
@@ -0,0 +1,56 @@
+package health
+
+import "sync"
+
+// WithConcurrency checks up to `n` dependencies at once each cycle, rather
+// than one at a time, so a slow dependency doesn't hold up the others. Every
+// due dependency is checked, where one at a time the rest of the cycle is
+// skipped once a hard dependency fails.
+func WithConcurrency(n int) Option {
+	return func(s *ServiceCheck) {
+		s.concurrency = n
+	}
+}
+
+// runChecks runs the checks of a cycle, returning those which ran or were
+// skipped
+func (s *ServiceCheck) runChecks(checks []pending) []pending {
+	if s.concurrency <= 1 {
+		for i := range checks {
+			c := &checks[i]
+			if !c.skip {
+				c.healthy, c.start, c.took = c.dep.run(c.check)
+			}
+
+			// the remaining dependencies needn't be checked once a hard one
+			// fails
+			if !c.healthy && c.level == LevelHard {
+				return checks[:i+1]
+			}
+		}
+
+		return checks
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, s.concurrency)
+	)
+	for i := range checks {
+		c := &checks[i]
+		if c.skip {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.healthy, c.start, c.took = c.dep.run(c.check)
+		}()
+	}
+	wg.Wait()
+
+	return checks
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithConcurrency(t *testing.T) {
+	tests := []struct {
+		concurrency int
+		expected    int
+	}{
+		{0, 1},
+		{2, 2},
+		{10, 4},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithConcurrency(test.concurrency))
+
+		var (
+			mu               sync.Mutex
+			running, maximum int
+			block            bool
+		)
+		for _, name := range []string{"db", "cache", "queue", "search"} {
+			check.RegisterDependency(name, LevelSoft, func() bool {
+				mu.Lock()
+				running++
+				if running > maximum {
+					maximum = running
+				}
+				blocking := block
+				mu.Unlock()
+
+				if blocking {
+					time.Sleep(20 * time.Millisecond)
+				}
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return true
+			})
+		}
+
+		mu.Lock()
+		block, maximum = true, 0
+		mu.Unlock()
+		check.Update()
+
+		mu.Lock()
+		if maximum != test.expected {
+			t.Errorf("expected %d at once got %d for concurrency %d", test.expected, maximum, test.concurrency)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestWithConcurrencyHardFailure(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithConcurrency(concurrency))
+
+		healthy := true
+		checked := make(chan string, 10)
+		check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+		check.RegisterDependency("cache", LevelSoft, func() bool {
+			checked <- "cache"
+			return true
+		})
+		<-checked
+
+		// ensure every dependency is checked when checking concurrently
+		healthy = false
+		check.Update()
+		if got := len(checked) == 1; got != (concurrency > 1) {
+			t.Errorf("expected cache checked %v got %v for concurrency %d", concurrency > 1, got, concurrency)
+		}
+		if check.IsHealthy() {
+			t.Errorf("expected unhealthy for concurrency %d", concurrency)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package consul integrates health with the local Consul agent, keeping
+// Consul's view of the service in line with its ServiceCheck.
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultAddress is the address of the local Consul agent
+const DefaultAddress = "http://127.0.0.1:8500"
+
+// Agent is a minimal client for the Consul agent HTTP API
+type Agent struct {
+	// Address of the agent, DefaultAddress if empty
+	Address string
+	// Token is sent as the ACL token on every request if set
+	Token string
+	// Client is used to make requests, a client with a 5 second timeout is
+	// used if nil
+	Client *http.Client
+}
+
+var defaultClient = &http.Client{Timeout: 5 * time.Second}
+
+// do sends `body` as JSON to the agent endpoint `path`
+func (a *Agent) do(ctx context.Context, method, path string, body interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	address := a.Address
+	if address == "" {
+		address = DefaultAddress
+	}
+
+	req, err := http.NewRequest(method, address+path, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.Token != "" {
+		req.Header.Set("X-Consul-Token", a.Token)
+	}
+
+	client := a.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul agent responded to %s %s with status code %d", method, path, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// consulDuration formats d the way Consul expects durations
+func consulDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
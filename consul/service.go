@@ -0,0 +1,201 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// requestTimeout bounds each request a started Service makes to the agent
+const requestTimeout = 5 * time.Second
+
+// ErrStarted is returned by Start for a Service which is already started
+var ErrStarted = errors.New("consul: service already started")
+
+// Service registers a service with the Consul agent between Start and Stop,
+// making the package a one-stop mesh enrollment helper
+type Service struct {
+	Agent *Agent
+
+	// ID and Name of the service, ID defaults to the Name
+	ID      string
+	Name    string
+	Address string
+	Port    int
+	Tags    []string
+	Meta    map[string]string
+
+	// TTL, if set, registers a TTL check with the service which is updated
+	// from the ServiceCheck, see TTLCheck
+	TTL time.Duration
+	// DeregisterAfter removes the service once its TTL check has been
+	// critical for this long. Zero disables it.
+	DeregisterAfter time.Duration
+	// DeregisterWhenUnhealthy removes the service from the agent whilst the
+	// ServiceCheck is unhealthy and registers it again once it recovers
+	DeregisterWhenUnhealthy bool
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan error
+}
+
+type serviceRegistration struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address,omitempty"`
+	Port    int               `json:"Port,omitempty"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+}
+
+func (s *Service) id() string {
+	if s.ID == "" {
+		return s.Name
+	}
+	return s.ID
+}
+
+// ttlCheck returns the TTL check associated with the service, or nil if it
+// has no TTL
+func (s *Service) ttlCheck() *TTLCheck {
+	if s.TTL <= 0 {
+		return nil
+	}
+
+	return &TTLCheck{
+		Agent:           s.Agent,
+		ID:              "service:" + s.id(),
+		Name:            "Service '" + s.Name + "' health",
+		ServiceID:       s.id(),
+		TTL:             s.TTL,
+		DeregisterAfter: s.DeregisterAfter,
+	}
+}
+
+// Register registers the service, and its TTL check if it has one, with the
+// agent
+func (s *Service) Register(ctx context.Context) error {
+	err := s.Agent.do(ctx, "PUT", "/v1/agent/service/register", serviceRegistration{
+		ID:      s.id(),
+		Name:    s.Name,
+		Address: s.Address,
+		Port:    s.Port,
+		Tags:    s.Tags,
+		Meta:    s.Meta,
+	})
+	if err != nil {
+		return err
+	}
+
+	if ttl := s.ttlCheck(); ttl != nil {
+		return ttl.Register(ctx)
+	}
+	return nil
+}
+
+// Deregister removes the service, along with its checks, from the agent
+func (s *Service) Deregister(ctx context.Context) error {
+	return s.Agent.do(ctx, "PUT", "/v1/agent/service/deregister/"+url.PathEscape(s.id()), nil)
+}
+
+// Start registers the service, and its TTL check if it has one, then keeps
+// the agent in line with `check` until Stop is called. The agent is updated
+// whenever the health of `check` changes, see ServiceCheck.Changed, and the
+// TTL check is passed again at half the TTL so it doesn't expire.
+func (s *Service) Start(check *health.ServiceCheck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return ErrStarted
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := s.Register(ctx); err != nil {
+		return err
+	}
+
+	s.stop, s.done = make(chan struct{}), make(chan error, 1)
+	go s.run(check, s.stop, s.done)
+	return nil
+}
+
+// Stop stops keeping the agent in line and deregisters the service, along
+// with its checks, if it is registered. It does nothing unless the service is
+// started.
+func (s *Service) Stop() error {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	return <-done
+}
+
+// run syncs the agent with `check` on each change until `stop` is closed,
+// sending the result of deregistering the service to `done`
+func (s *Service) run(check *health.ServiceCheck, stop <-chan struct{}, done chan<- error) {
+	var refresh <-chan time.Time
+	if s.TTL > 0 {
+		ticker := time.NewTicker(s.TTL / 2)
+		defer ticker.Stop()
+		refresh = ticker.C
+	}
+
+	registered := true
+	for {
+		// fetch the channel before syncing so no change is missed
+		changed := check.Changed()
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		registered = s.sync(ctx, check, registered)
+		cancel()
+
+		select {
+		case <-stop:
+			if !registered {
+				done <- nil
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			done <- s.Deregister(ctx)
+			cancel()
+			return
+		case <-changed:
+		case <-refresh:
+		}
+	}
+}
+
+// sync brings the agent in line with the current health of `check`, returning
+// whether the service is registered afterwards
+func (s *Service) sync(ctx context.Context, check *health.ServiceCheck, registered bool) bool {
+	healthy := check.IsHealthy()
+
+	if s.DeregisterWhenUnhealthy {
+		switch {
+		case registered && !healthy:
+			if s.Deregister(ctx) == nil {
+				return false
+			}
+		case !registered && healthy:
+			if s.Register(ctx) != nil {
+				return false
+			}
+			registered = true
+		}
+	}
+
+	if ttl := s.ttlCheck(); ttl != nil && registered {
+		ttl.Update(ctx, check)
+	}
+	return registered
+}
@@ -0,0 +1,150 @@
+package consul
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestServiceRegister(t *testing.T) {
+	fake := &fakeAgent{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service := &Service{
+		Agent: &Agent{Address: server.URL},
+		Name:  "users",
+		Port:  8080,
+		Tags:  []string{"v1"},
+		TTL:   10 * time.Second,
+	}
+
+	if err := service.Register(context.Background()); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	expected := []string{
+		"PUT /v1/agent/service/register",
+		"PUT /v1/agent/check/register",
+	}
+	if !reflect.DeepEqual(fake.requests, expected) {
+		t.Fatalf("expected %v got %v", expected, fake.requests)
+	}
+	if fake.bodies[0]["ID"] != "users" || fake.bodies[0]["Port"] != float64(8080) {
+		t.Errorf("unexpected service registration %v", fake.bodies[0])
+	}
+	if fake.bodies[1]["ServiceID"] != "users" || fake.bodies[1]["ID"] != "service:users" {
+		t.Errorf("unexpected check registration %v", fake.bodies[1])
+	}
+}
+
+func TestServiceSync(t *testing.T) {
+	fake := &fakeAgent{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service := &Service{
+		Agent:                   &Agent{Address: server.URL},
+		Name:                    "users",
+		DeregisterWhenUnhealthy: true,
+	}
+	check := &health.ServiceCheck{Name: "users", Healthy: false}
+
+	registered := service.sync(context.Background(), check, true)
+	if registered {
+		t.Error("expected unhealthy service to be deregistered")
+	}
+
+	registered = service.sync(context.Background(), check, registered)
+	if registered {
+		t.Error("expected unhealthy service to remain deregistered")
+	}
+
+	check.Healthy = true
+	registered = service.sync(context.Background(), check, registered)
+	if !registered {
+		t.Error("expected recovered service to be registered")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	expected := []string{
+		"PUT /v1/agent/service/deregister/users",
+		"PUT /v1/agent/service/register",
+	}
+	if !reflect.DeepEqual(fake.requests, expected) {
+		t.Errorf("expected %v got %v", expected, fake.requests)
+	}
+}
+
+func TestServiceStart(t *testing.T) {
+	fake := &fakeAgent{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service := &Service{
+		Agent:                   &Agent{Address: server.URL},
+		Name:                    "users",
+		DeregisterWhenUnhealthy: true,
+	}
+	check, _ := health.InitialiseServiceCheck("users", time.Minute)
+	db, _ := check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+
+	if err := service.Start(check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if err := service.Start(check); err != ErrStarted {
+		t.Errorf("expected %v got %v", ErrStarted, err)
+	}
+
+	// ensure a change of health is pushed without waiting on a poll
+	db.SetHealthy(false)
+	waitForRequests(t, fake, 2)
+	db.SetHealthy(true)
+	waitForRequests(t, fake, 3)
+
+	if err := service.Stop(); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if err := service.Stop(); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	expected := []string{
+		"PUT /v1/agent/service/register",
+		"PUT /v1/agent/service/deregister/users",
+		"PUT /v1/agent/service/register",
+		"PUT /v1/agent/service/deregister/users",
+	}
+	if !reflect.DeepEqual(fake.requests, expected) {
+		t.Errorf("expected %v got %v", expected, fake.requests)
+	}
+}
+
+// waitForRequests waits up to a second for `fake` to have received `n`
+// requests
+func waitForRequests(t *testing.T, fake *fakeAgent, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		fake.mu.Lock()
+		got := len(fake.requests)
+		fake.mu.Unlock()
+		if got >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d requests", n)
+}
@@ -0,0 +1,119 @@
+package consul
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Consul check statuses
+const (
+	StatusPassing  = "passing"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+)
+
+// TTLCheck is a Consul TTL check which is passed or failed according to a
+// ServiceCheck, so the Consul catalog reflects the same hard dependency logic
+// as the service's health endpoint
+type TTLCheck struct {
+	Agent *Agent
+
+	// ID and Name of the check, Name defaults to the ID
+	ID   string
+	Name string
+	// ServiceID associates the check with a service registered with the agent
+	ServiceID string
+	// TTL is how long Consul waits for an update before marking the check
+	// critical
+	TTL time.Duration
+	// DeregisterAfter removes the check, and its service, once it has been
+	// critical for this long. Zero disables it.
+	DeregisterAfter time.Duration
+}
+
+type checkRegistration struct {
+	ID                             string `json:"ID"`
+	Name                           string `json:"Name"`
+	ServiceID                      string `json:"ServiceID,omitempty"`
+	TTL                            string `json:"TTL"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+type checkUpdate struct {
+	Status string `json:"Status"`
+	Output string `json:"Output"`
+}
+
+// Register registers the check with the agent
+func (c *TTLCheck) Register(ctx context.Context) error {
+	name := c.Name
+	if name == "" {
+		name = c.ID
+	}
+
+	return c.Agent.do(ctx, "PUT", "/v1/agent/check/register", checkRegistration{
+		ID:                             c.ID,
+		Name:                           name,
+		ServiceID:                      c.ServiceID,
+		TTL:                            consulDuration(c.TTL),
+		DeregisterCriticalServiceAfter: consulDuration(c.DeregisterAfter),
+	})
+}
+
+// Update passes the check if `s` is healthy and fails it otherwise, using the
+// status of `s` as the check output
+func (c *TTLCheck) Update(ctx context.Context, s *health.ServiceCheck) error {
+	var output bytes.Buffer
+	if err := s.WriteStatus(&output); err != nil {
+		return err
+	}
+
+	status := StatusPassing
+	if !s.IsHealthy() {
+		status = StatusCritical
+	}
+
+	return c.Agent.do(ctx, "PUT", "/v1/agent/check/update/"+url.PathEscape(c.ID), checkUpdate{
+		Status: status,
+		Output: output.String(),
+	})
+}
+
+// Deregister removes the check from the agent
+func (c *TTLCheck) Deregister(ctx context.Context) error {
+	return c.Agent.do(ctx, "PUT", "/v1/agent/check/deregister/"+url.PathEscape(c.ID), nil)
+}
+
+// Start registers the check and then updates it from `s` at half the TTL, so
+// a single slow update doesn't expire it, until ctx is cancelled. The check is
+// deregistered once ctx is cancelled.
+func (c *TTLCheck) Start(ctx context.Context, s *health.ServiceCheck) error {
+	if err := c.Register(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(c.TTL / 2)
+		defer ticker.Stop()
+
+		for {
+			c.Update(ctx, s)
+
+			select {
+			case <-ctx.Done():
+				// ctx is already cancelled, give deregistration its own deadline
+				deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				c.Deregister(deregisterCtx)
+				cancel()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// fakeAgent records the requests made to it
+type fakeAgent struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]interface{}
+	tokens   []string
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, r.Method+" "+r.URL.Path)
+	a.bodies = append(a.bodies, body)
+	a.tokens = append(a.tokens, r.Header.Get("X-Consul-Token"))
+}
+
+func TestTTLCheck(t *testing.T) {
+	fake := &fakeAgent{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	check := &TTLCheck{
+		Agent:     &Agent{Address: server.URL, Token: "secret"},
+		ID:        "service:users",
+		ServiceID: "users",
+		TTL:       10 * time.Second,
+	}
+
+	if err := check.Register(context.Background()); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	s := &health.ServiceCheck{Name: "users", Healthy: false}
+	if err := check.Update(context.Background(), s); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	expected := []string{
+		"PUT /v1/agent/check/register",
+		"PUT /v1/agent/check/update/service:users",
+	}
+	if len(fake.requests) != len(expected) {
+		t.Fatalf("expected %v got %v", expected, fake.requests)
+	}
+	for i := range expected {
+		if fake.requests[i] != expected[i] {
+			t.Errorf("expected %v got %v", expected[i], fake.requests[i])
+		}
+		if fake.tokens[i] != "secret" {
+			t.Errorf("expected token to be sent with %v", fake.requests[i])
+		}
+	}
+
+	if fake.bodies[0]["TTL"] != "10s" || fake.bodies[0]["ServiceID"] != "users" {
+		t.Errorf("unexpected registration %v", fake.bodies[0])
+	}
+	if fake.bodies[1]["Status"] != StatusCritical {
+		t.Errorf("expected %v got %v", StatusCritical, fake.bodies[1]["Status"])
+	}
+}
+
+func TestAgentError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	check := &TTLCheck{Agent: &Agent{Address: server.URL}, ID: "users", TTL: time.Second}
+	if err := check.Register(context.Background()); err == nil {
+		t.Error("expected an error from a rejected registration")
+	}
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithCheckTimeout sets how long a check registered with
+// RegisterDependencyContext has before its context is cancelled, the
+// duration if zero
+func WithCheckTimeout(timeout time.Duration) Option {
+	return func(s *ServiceCheck) {
+		s.checkTimeout = timeout
+	}
+}
+
+// WithCheckTimes includes how long each dependency's last check took in the
+// status document, as latencyMs. It is left out by default so consecutive
+// documents of a healthy service can be diffed.
+func WithCheckTimes() Option {
+	return func(s *ServiceCheck) {
+		s.checkTimes = true
+	}
+}
+
+// checkErrors keeps the error a check last returned, for its dependency's
+// LastError
+type checkErrors struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (c *checkErrors) set(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+func (c *checkErrors) last() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// RegisterDependencyContext registers a dependency as RegisterDependency, but
+// whose check takes a context and may return an error, which makes it
+// unhealthy and is recorded as its LastError. The context is cancelled once
+// the check's timeout passes, see WithTimeout and WithCheckTimeout, or the
+// check is stopped. A check which doesn't return by then is reported
+// unhealthy rather than stalling the other checks, and left to return in the
+// background.
+func (s *ServiceCheck) RegisterDependencyContext(name string, level Level, check func(ctx context.Context) (bool, error), opts ...DependencyOption) (*Dependency, error) {
+	if check == nil {
+		return nil, s.dependencyError(name, ErrNoCheck)
+	}
+
+	var (
+		dep  *Dependency
+		errs = &checkErrors{}
+	)
+	opts = append([]DependencyOption{func(d *Dependency) { d.errs, dep = errs, d }}, opts...)
+	return s.RegisterDependency(name, level, s.withContext(check, errs, func() *Dependency { return dep }), opts...)
+}
+
+// withContext converts a check taking a context into one for
+// RegisterDependency, running it in a span of the Tracer, if any, with a
+// context cancelled after the timeout of the dependency `dependency` returns,
+// if it has one, or the check timeout, and keeping its error in `errs`
+func (s *ServiceCheck) withContext(check func(context.Context) (bool, error), errs *checkErrors, dependency func() *Dependency) func() bool {
+	return func() bool {
+		dep := dependency()
+		ctx, end := s.startSpan(s.context(), dep)
+		if timeout := s.getCheckTimeout(dep.timeout); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		// buffered, so a check returning after it was abandoned doesn't
+		// block forever
+		type result struct {
+			healthy bool
+			err     error
+		}
+		results := make(chan result, 1)
+		go func() {
+			// ensure a panicking check fails rather than crashing the service
+			defer func() {
+				if r := recover(); r != nil {
+					results <- result{false, panicError(r)}
+				}
+			}()
+			healthy, err := check(ctx)
+			results <- result{healthy, err}
+		}()
+
+		var r result
+		select {
+		case r = <-results:
+		case <-ctx.Done():
+			r.err = ctx.Err()
+		}
+		errs.set(r.err)
+		end(r.healthy && r.err == nil, r.err)
+		return r.healthy && r.err == nil
+	}
+}
+
+// getCheckTimeout returns the timeout of checks taking a context, that of the
+// dependency if it has one
+func (s *ServiceCheck) getCheckTimeout(dependency time.Duration) time.Duration {
+	if dependency > 0 {
+		return dependency
+	}
+	if s.checkTimeout > 0 {
+		return s.checkTimeout
+	}
+
+	return s.duration
+}
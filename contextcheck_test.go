@@ -0,0 +1,140 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterDependencyContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    func(context.Context) (bool, error)
+		expected bool
+	}{
+		{"healthy", func(ctx context.Context) (bool, error) { return true, nil }, true},
+		{"unhealthy", func(ctx context.Context) (bool, error) { return false, nil }, false},
+		{"error", func(ctx context.Context) (bool, error) { return true, errors.New("connection refused") }, false},
+		{"respects deadline", func(ctx context.Context) (bool, error) {
+			<-ctx.Done()
+			return true, ctx.Err()
+		}, false},
+		{"ignores deadline", func(ctx context.Context) (bool, error) {
+			time.Sleep(time.Second)
+			return true, nil
+		}, false},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithCheckTimeout(20*time.Millisecond))
+
+		start := time.Now()
+		dep, err := check.RegisterDependencyContext(test.name, LevelHard, test.check)
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if dep.IsHealthy() != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, dep.IsHealthy(), test.name)
+		}
+		// ensure a hung check doesn't stall registration
+		if took := time.Since(start); took > 500*time.Millisecond {
+			t.Errorf("expected the check to time out got %v for %s", took, test.name)
+		}
+	}
+}
+
+func TestRegisterDependencyContextStop(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithStopGrace(time.Millisecond))
+
+	block := false
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	check.RegisterDependencyContext("db", LevelHard, func(ctx context.Context) (bool, error) {
+		if block {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+		}
+		return true, nil
+	})
+
+	block = true
+	check.StartCheck()
+	<-started
+	check.StopCheck()
+
+	// ensure stopping the check cancels the context of checks in flight
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("expected the context to be cancelled")
+	}
+
+	if _, err := check.RegisterDependencyContext("cache", LevelSoft, nil); !errors.Is(err, ErrNoCheck) {
+		t.Errorf("expected %v got %v", ErrNoCheck, err)
+	}
+}
+
+func TestLastError(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock), WithCheckTimes())
+
+	var err error
+	dep, _ := check.RegisterDependencyContext("db", LevelHard, func(ctx context.Context) (bool, error) {
+		return err == nil, err
+	})
+	succeeded := clock.Now()
+
+	clock.advance(time.Second, 0)
+	err = errors.New("connection refused")
+	check.Update()
+
+	doc := check.Document().Dependencies[0]
+	if doc.LastError != "connection refused" {
+		t.Errorf("expected connection refused got %s", doc.LastError)
+	}
+	if !doc.LastChecked.Equal(clock.Now()) || !doc.LastSuccess.Equal(succeeded) {
+		t.Errorf("expected checked at %v and last healthy at %v got %v and %v", clock.Now(), succeeded, doc.LastChecked, doc.LastSuccess)
+	}
+
+	// ensure the error is cleared once it recovers
+	err = nil
+	check.Update()
+	if doc := check.Document().Dependencies[0]; doc.LastError != "" || !dep.IsHealthy() {
+		t.Errorf("expected no error got %s", doc.LastError)
+	}
+}
+
+func TestCheckTimes(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	for _, times := range []bool{false, true} {
+		opts := []Option{WithClock(clock)}
+		if times {
+			opts = append(opts, WithCheckTimes())
+		}
+		check, _ := InitialiseServiceCheck("api", time.Minute, opts...)
+		check.RegisterDependency("db", LevelHard, func() bool { return true })
+		check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+
+		// ensure the times are included either way, leaving out those which
+		// haven't happened
+		doc := check.Document()
+		if db := doc.Dependencies[0]; !db.LastChecked.Equal(clock.Now()) || !db.LastSuccess.Equal(clock.Now()) {
+			t.Errorf("expected %v got %v and %v", clock.Now(), db.LastChecked, db.LastSuccess)
+		}
+		var b strings.Builder
+		check.WriteStatus(&b)
+		if got := strings.Count(b.String(), `"lastSuccess"`); got != 1 {
+			t.Errorf("expected %v got %v in %s", 1, got, b.String())
+		}
+		if got := strings.Contains(b.String(), `"latencyMs"`); got != times {
+			t.Errorf("expected %v got %v in %s", times, got, b.String())
+		}
+
+		// the times are recorded either way
+		if got := check.DependencyStates()[0].LastChecked; !got.Equal(clock.Now()) {
+			t.Errorf("expected %v got %v", clock.Now(), got)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package health
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckSchedule decides when a dependency is next due to be checked, see
+// WithCheckSchedule
+type CheckSchedule interface {
+	// Next returns the first time after `after` the dependency is due, or
+	// the zero time if it never is again
+	Next(after time.Time) time.Time
+}
+
+// WithCheckSchedule checks the dependency in the check's cycles only when
+// `schedule` has it due since the previous cycle, such as only during
+// business hours, reporting the last result in between. The schedule is
+// honoured to the nearest cycle, and times missed whilst the check isn't
+// running aren't caught up. Update still checks it on demand.
+//
+//	hours, _ := health.ParseCron("*/5 9-17 * * 1-5")
+//	check.RegisterDependency("reports", health.LevelSoft, checkReports, health.WithCheckSchedule(hours))
+func WithCheckSchedule(schedule CheckSchedule) DependencyOption {
+	return func(d *Dependency) {
+		d.schedule = schedule
+	}
+}
+
+// scheduleDue returns whether the dependency's schedule, if any, has it due
+// at `now` in a cycle every `duration`, since both the previous cycle and its
+// last check
+func (d *Dependency) scheduleDue(now time.Time, duration time.Duration) bool {
+	if d.schedule == nil || !d.checked {
+		return true
+	}
+
+	since := d.started
+	if previous := now.Add(-duration); previous.After(since) {
+		since = previous
+	}
+	next := d.schedule.Next(since)
+	return !next.IsZero() && !now.Before(next)
+}
+
+// cron is a CheckSchedule parsed from a cron expression, each field a set of
+// the values it matches
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	// anyDom and anyDow are set for fields given as "*", as a day matches
+	// either of the day fields when both are restricted
+	anyDom, anyDow bool
+}
+
+// cronFields are the bounds of the fields of a cron expression
+var cronFields = [5]struct{ min, max int }{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of the month
+	{1, 12}, // month
+	{0, 7},  // day of the week, 0 and 7 being Sunday
+}
+
+// ParseCron parses a standard five field cron expression, "minute hour
+// day-of-month month day-of-week", into a CheckSchedule in the time zone of
+// the times it is given. Fields are "*", values, ranges such as "9-17",
+// steps such as "*/5" or "9-17/2", and lists of them separated by commas.
+func ParseCron(expr string) (CheckSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q: expected %d fields", ErrInvalidCron, expr, len(cronFields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCron, expr, err)
+		}
+		sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cron{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: fields[2] == "*",
+		anyDow: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField returns the set of values between `min` and `max` matched by
+// `field`
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+			step, part = n, part[:i]
+		}
+
+		low, high := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			low, high = n, n
+			if len(bounds) == 2 {
+				if high, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if step > 1 {
+				// a single value with a step, such as "5/15", runs to the end
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// Next returns the first minute after `after` matching the expression, or
+// the zero time if none does within five years
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	loc := t.Location()
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		year, month, day := t.Date()
+		switch {
+		case c.month&(1<<month) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchesDay(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay returns whether the day of `t` matches the day fields
+func (c *cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	if c.anyDom || c.anyDow {
+		return dom && dow
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+	}
+
+	for _, expr := range tests {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("expected %v got %v for %q", ErrInvalidCron, err, expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// a Monday
+	after := time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 1, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2024, 1, 1, 10, 25, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{"0 9 * * 6,0", time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2024, 2, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// either day field matches when both are restricted
+		{"0 0 15 * 3", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseCron(test.expr)
+		if err != nil {
+			t.Fatalf("expected nil got %v for %q", err, test.expr)
+		}
+		if next := schedule.Next(after); !next.Equal(test.expected) {
+			t.Errorf("expected %v got %v for %q", test.expected, next, test.expr)
+		}
+	}
+}
+
+func TestWithCheckSchedule(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 16, 58, 0, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock))
+	hours, _ := ParseCron("0 9-17 * * *")
+
+	var runs int
+	check.RegisterDependency("reports", LevelSoft, func() bool {
+		runs++
+		return true
+	}, WithCheckSchedule(hours))
+
+	// checked on registration, then on the hour during business hours only
+	expected := []int{1, 2, 2, 2, 2}
+	for i, want := range expected {
+		clock.advance(time.Minute, 0)
+		check.scheduledUpdate()
+		if runs != want {
+			t.Errorf("expected %d runs got %d at %v after cycle %d", want, runs, clock.Now(), i+1)
+		}
+		if i == 2 {
+			// skip to the evening
+			clock.advance(3*time.Hour, 0)
+		}
+	}
+
+	// ensure Update checks it on demand
+	check.Update()
+	if runs != 3 {
+		t.Errorf("expected 3 runs got %d", runs)
+	}
+}
@@ -0,0 +1,52 @@
+package health
+
+import "sync"
+
+// WithDedupKey collapses concurrent checks of dependencies sharing `key`, in
+// this or any other ServiceCheck, into a single run of the check whose result
+// they all share. It suits several dependencies probing the same endpoint.
+// Only checks which overlap are collapsed, the result isn't cached.
+func WithDedupKey(key string) DependencyOption {
+	return func(d *Dependency) {
+		d.check = dedup(key, d.check)
+	}
+}
+
+// flight is a run of a check shared by the dependencies waiting on it
+type flight struct {
+	done    chan struct{}
+	healthy bool
+}
+
+// flights are the checks running per dedup key
+var flights = struct {
+	sync.Mutex
+	running map[string]*flight
+}{running: make(map[string]*flight)}
+
+// dedup returns `check` sharing its result with concurrent checks keyed by
+// `key`
+func dedup(key string, check func() bool) func() bool {
+	return func() bool {
+		flights.Lock()
+		if f, ok := flights.running[key]; ok {
+			flights.Unlock()
+			<-f.done
+			return f.healthy
+		}
+		f := &flight{done: make(chan struct{})}
+		flights.running[key] = f
+		flights.Unlock()
+
+		// ensure the waiters are released even if the check panics
+		defer func() {
+			flights.Lock()
+			delete(flights.running, key)
+			flights.Unlock()
+			close(f.done)
+		}()
+
+		f.healthy = check()
+		return f.healthy
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDedupKey(t *testing.T) {
+	var (
+		runs    int32
+		release = make(chan struct{})
+		started = make(chan struct{}, 1)
+	)
+	probe := func() bool {
+		atomic.AddInt32(&runs, 1)
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return true
+	}
+
+	first, _ := InitialiseServiceCheck("first", time.Minute)
+	second, _ := InitialiseServiceCheck("second", time.Minute)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		first.RegisterDependency("cache", LevelHard, probe, WithDedupKey("tcp://cache:6379"))
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		second.RegisterDependency("cache", LevelSoft, probe, WithDedupKey("tcp://cache:6379"))
+	}()
+
+	// give the second check time to join the first
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if runs != 1 {
+		t.Errorf("expected 1 run got %d", runs)
+	}
+	for _, check := range []*ServiceCheck{first, second} {
+		if dep, _ := check.Dependency("cache"); dep == nil || !dep.IsHealthy() {
+			t.Errorf("expected %s's cache to share the result", check.Name)
+		}
+	}
+
+	// checks which don't overlap both run
+	first.Update()
+	second.Update()
+	if runs != 3 {
+		t.Errorf("expected 3 runs got %d", runs)
+	}
+}
+
+func TestWithDedupKeyPanic(t *testing.T) {
+	release := make(chan struct{})
+	check := dedup("panics", func() bool {
+		<-release
+		panic("boom")
+	})
+
+	go func() {
+		defer func() { recover() }()
+		check()
+	}()
+
+	// wait for the first run to be in flight
+	for {
+		flights.Lock()
+		_, ok := flights.running["panics"]
+		flights.Unlock()
+		if ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan bool)
+	go func() { done <- check() }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if healthy := <-done; healthy {
+		t.Errorf("expected %v got %v", false, healthy)
+	}
+}
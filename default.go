@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultServiceCheck is the ServiceCheck used by the package level Register,
+// Handler and Start functions. It is named after the running binary, and the
+// Env environment variables override it as any other check.
+var DefaultServiceCheck = newDefaultServiceCheck()
+
+// newDefaultServiceCheck initialises DefaultServiceCheck, ignoring the
+// environment if it's invalid rather than failing every importer
+func newDefaultServiceCheck() *ServiceCheck {
+	name := filepath.Base(os.Args[0])
+	if check, err := InitialiseServiceCheck(name, 0); err == nil {
+		return check
+	}
+
+	check, _ := InitialiseServiceCheck(name, 0, WithoutEnv())
+	return check
+}
+
+// Register registers a new dependency on DefaultServiceCheck
+func Register(name string, level Level, check func() bool, opts ...DependencyOption) (*Dependency, error) {
+	return DefaultServiceCheck.RegisterDependency(name, level, check, opts...)
+}
+
+// Handler outputs the status of DefaultServiceCheck with the relevant response
+// code to a ResponseWriter
+func Handler(w http.ResponseWriter, r *http.Request) {
+	DefaultServiceCheck.HTTPHandler(w, r)
+}
+
+// Start starts checking the dependencies of DefaultServiceCheck every
+// `duration`, unless EnvInterval overrides it
+func Start(duration time.Duration) {
+	DefaultServiceCheck.duration = duration
+	if interval := DefaultServiceCheck.env.interval; interval > 0 {
+		DefaultServiceCheck.duration = interval
+	}
+	DefaultServiceCheck.StartCheck()
+}
+
+// IsHealthy returns a bool whether DefaultServiceCheck is healthy
+func IsHealthy() bool {
+	return DefaultServiceCheck.IsHealthy()
+}
+
+// Serve serves the status of DefaultServiceCheck at `addr` until ctx is
+// cancelled, see ServiceCheck.Serve
+func Serve(ctx context.Context, addr string) error {
+	return DefaultServiceCheck.Serve(ctx, addr)
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDefaultServiceCheck(t *testing.T) {
+	original := DefaultServiceCheck
+	defer func() { DefaultServiceCheck = original }()
+	DefaultServiceCheck = &ServiceCheck{Name: "test", Healthy: true}
+
+	if _, err := Register("redis", LevelHard, func() bool { return false }); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if _, err := Register("redis", LevelHard, func() bool { return false }); !errors.Is(err, ErrDependencyAlreadyRegistered) {
+		t.Errorf("expected %v got %v", ErrDependencyAlreadyRegistered, err)
+	}
+
+	DefaultServiceCheck.updateStatus()
+	if IsHealthy() {
+		t.Error("expected default service check to be unhealthy")
+	}
+
+	rec := httptest.NewRecorder()
+	Handler(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+}
+
+func TestDefaultServiceCheckEnv(t *testing.T) {
+	tests := []struct {
+		interval, timeout string
+		expected          time.Duration
+		expectedTimeout   time.Duration
+	}{
+		{"1m", "500ms", time.Minute, 500 * time.Millisecond},
+		// an invalid environment is ignored rather than failing
+		{"soon", "500ms", 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Setenv(EnvInterval, test.interval)
+		t.Setenv(EnvTimeout, test.timeout)
+
+		// ensure the environment reaches the default check as any other
+		check := newDefaultServiceCheck()
+		if check.duration != test.expected {
+			t.Errorf("expected %v got %v", test.expected, check.duration)
+		}
+		dep, _ := check.RegisterDependency("redis", LevelHard, func() bool { return true })
+		if dep.timeout != test.expectedTimeout {
+			t.Errorf("expected %v got %v", test.expectedTimeout, dep.timeout)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package health
+
+import "time"
+
+// Pause stops the dependency being checked, holding its current health until
+// Resume is called
+func (d *Dependency) Pause() {
+	d.lock()
+	defer d.unlock()
+	d.Paused = true
+	d.publish()
+}
+
+// Resume starts checking a paused dependency again from the next check
+func (d *Dependency) Resume() {
+	d.lock()
+	defer d.unlock()
+	d.Paused = false
+	d.publish()
+}
+
+// IsPaused returns a bool whether the dependency is paused
+func (d *Dependency) IsPaused() bool {
+	d.lock()
+	defer d.unlock()
+	return d.Paused
+}
+
+// SetHealthy records the health of the dependency without running its check,
+// updating the health of the service straight away. Unless the dependency is
+// paused the next check replaces it.
+func (d *Dependency) SetHealthy(healthy bool) {
+	d.lock()
+	defer d.unlock()
+	changed := d.Healthy != healthy
+	d.Healthy = healthy
+	if d.owner != nil {
+		d.owner.recordHealth(changed)
+	}
+}
+
+// IsHealthy returns a bool whether the dependency was healthy as of the last
+// check
+func (d *Dependency) IsHealthy() bool {
+	d.lock()
+	defer d.unlock()
+	return d.Healthy
+}
+
+// CheckDuration returns how long the last check of the dependency took
+func (d *Dependency) CheckDuration() time.Duration {
+	d.lock()
+	defer d.unlock()
+	return d.took
+}
+
+// lock holds the lock of the ServiceCheck the dependency is registered on, if
+// any
+func (d *Dependency) lock() {
+	if d.owner != nil {
+		d.owner.mu.Lock()
+	}
+}
+
+func (d *Dependency) unlock() {
+	if d.owner != nil {
+		d.owner.mu.Unlock()
+	}
+}
+
+// publish publishes the state of the ServiceCheck the dependency is registered
+// on, if any, whilst locked
+func (d *Dependency) publish() {
+	if d.owner != nil {
+		d.owner.publish()
+	}
+}
+
+// TypedDependency is a Dependency handle carrying user data, such as the
+// client the dependency is checked with. See RegisterTypedDependency
+type TypedDependency[T any] struct {
+	*Dependency
+	Data T
+}
+
+// RegisterTypedDependency registers a dependency on `s` whose check is passed
+// `data`, returning a handle carrying it, so callers needn't keep the data and
+// the handle side by side:
+//
+//	db, err := health.RegisterTypedDependency(check, "db", health.LevelHard, pool, func(pool *pgxpool.Pool) bool {
+//		return pool.Ping(context.Background()) == nil
+//	})
+//	db.Data.Close()
+func RegisterTypedDependency[T any](s *ServiceCheck, name string, level Level, data T, check func(T) bool, opts ...DependencyOption) (*TypedDependency[T], error) {
+	dep, err := s.RegisterDependency(name, level, func() bool { return check(data) }, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedDependency[T]{Dependency: dep, Data: data}, nil
+}
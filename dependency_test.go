@@ -0,0 +1,68 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDependencyHandle(t *testing.T) {
+	healthy := true
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	db, err := check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	db.SetHealthy(false)
+	if db.IsHealthy() || check.IsHealthy() {
+		t.Error("expected the dependency and service to be unhealthy once set")
+	}
+
+	check.Update()
+	if !db.IsHealthy() || !check.IsHealthy() {
+		t.Error("expected the next check to replace the set health")
+	}
+
+	db.Pause()
+	healthy = false
+	check.Update()
+	if !db.IsPaused() || !db.IsHealthy() {
+		t.Error("expected a paused dependency to hold its health")
+	}
+
+	db.SetHealthy(false)
+	check.Update()
+	if check.IsHealthy() {
+		t.Error("expected the set health to be held whilst paused")
+	}
+
+	db.Resume()
+	healthy = true
+	check.Update()
+	if db.IsPaused() || !check.IsHealthy() {
+		t.Error("expected checks to run again once resumed")
+	}
+}
+
+func TestRegisterTypedDependency(t *testing.T) {
+	type client struct{ up bool }
+
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	db, err := RegisterTypedDependency(check, "db", LevelHard, &client{}, func(c *client) bool { return c.up })
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if db.IsHealthy() {
+		t.Error("expected unhealthy whilst the client is down")
+	}
+
+	db.Data.up = true
+	check.Update()
+	if !db.IsHealthy() || db.Name != "db" {
+		t.Errorf("expected db to be healthy got %+v", db.Dependency)
+	}
+
+	if _, err := RegisterTypedDependency(check, "db", LevelHard, &client{}, func(c *client) bool { return c.up }); err == nil {
+		t.Error("expected an error registering a duplicate")
+	}
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"sort"
+	"sync"
+)
+
+// GroupMember is the state of a member of a dependency group, see
+// RegisterDependencyGroup
+type GroupMember struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+}
+
+// dependencyGroup checks the members of a dependency group
+type dependencyGroup struct {
+	minHealthy int
+	names      []string
+	checks     map[string]func() bool
+
+	mu   sync.Mutex
+	last []GroupMember
+}
+
+// RegisterDependencyGroup registers a dependency `name` which is healthy while
+// at least `minHealthy` of its members are, such as a cluster of replicas of
+// which only one needs to be reachable. Every member is checked in turn, in
+// name order, and their states are nested under the dependency as members.
+func (s *ServiceCheck) RegisterDependencyGroup(name string, level Level, minHealthy int, checks map[string]func() bool, opts ...DependencyOption) (*Dependency, error) {
+	if minHealthy < 1 || minHealthy > len(checks) {
+		return nil, s.dependencyError(name, ErrInvalidGroupSize)
+	}
+
+	g := &dependencyGroup{
+		minHealthy: minHealthy,
+		names:      make([]string, 0, len(checks)),
+		checks:     make(map[string]func() bool, len(checks)),
+	}
+	for member, check := range checks {
+		if check == nil {
+			return nil, s.dependencyError(name, ErrNoCheck)
+		}
+		g.names = append(g.names, member)
+		g.checks[member] = check
+	}
+	sort.Strings(g.names)
+
+	return s.RegisterDependency(name, level, g.check, append(opts, func(d *Dependency) {
+		d.group = g
+	})...)
+}
+
+// check checks every member, returning whether enough of them are healthy
+func (g *dependencyGroup) check() bool {
+	members := make([]GroupMember, len(g.names))
+	healthy := 0
+	for i, name := range g.names {
+		members[i] = GroupMember{Name: name, Healthy: g.checks[name]()}
+		if members[i].Healthy {
+			healthy++
+		}
+	}
+
+	g.mu.Lock()
+	g.last = members
+	g.mu.Unlock()
+	return healthy >= g.minHealthy
+}
+
+// members returns the state of the members as of the last check
+func (g *dependencyGroup) members() []GroupMember {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.last
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegisterDependencyGroup(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	replicas := map[string]bool{"replica-1": true, "replica-2": true, "replica-3": true}
+	checks := make(map[string]func() bool)
+	for name := range replicas {
+		name := name
+		checks[name] = func() bool { return replicas[name] }
+	}
+	group, err := check.RegisterDependencyGroup("cluster", LevelHard, 1, checks)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	tests := []struct {
+		down     []string
+		expected bool
+	}{
+		{nil, true},
+		{[]string{"replica-1", "replica-3"}, true},
+		{[]string{"replica-1", "replica-2", "replica-3"}, false},
+	}
+
+	for _, test := range tests {
+		for name := range replicas {
+			replicas[name] = true
+		}
+		for _, name := range test.down {
+			replicas[name] = false
+		}
+		check.Update()
+
+		if group.IsHealthy() != test.expected || check.IsHealthy() != test.expected {
+			t.Errorf("expected %v got %v with %v down", test.expected, group.IsHealthy(), test.down)
+		}
+	}
+
+	// ensure the members are nested under the group in name order
+	expected := []GroupMember{{"replica-1", false}, {"replica-2", false}, {"replica-3", false}}
+	if members := check.Document().Dependencies[0].Members; !reflect.DeepEqual(members, expected) {
+		t.Errorf("expected %v got %v", expected, members)
+	}
+
+	var doc struct {
+		Dependencies []struct {
+			Members []GroupMember `json:"members"`
+		} `json:"dependencies"`
+	}
+	b, _ := json.Marshal(check)
+	json.Unmarshal(b, &doc)
+	if len(doc.Dependencies) != 1 || !reflect.DeepEqual(doc.Dependencies[0].Members, expected) {
+		t.Errorf("expected %v got %s", expected, b)
+	}
+}
+
+func TestRegisterDependencyGroupErrors(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	ok := func() bool { return true }
+
+	tests := []struct {
+		minHealthy int
+		checks     map[string]func() bool
+		expected   error
+	}{
+		{0, map[string]func() bool{"a": ok}, ErrInvalidGroupSize},
+		{2, map[string]func() bool{"a": ok}, ErrInvalidGroupSize},
+		{1, map[string]func() bool{"a": nil}, ErrNoCheck},
+	}
+
+	for _, test := range tests {
+		if _, err := check.RegisterDependencyGroup("cluster", LevelHard, test.minHealthy, test.checks); !errors.Is(err, test.expected) {
+			t.Errorf("expected %v got %v", test.expected, err)
+		}
+	}
+}
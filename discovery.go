@@ -0,0 +1,161 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PeerDiscoverer finds the health endpoints of an instance's peers
+type PeerDiscoverer interface {
+	Peers(ctx context.Context) ([]string, error)
+}
+
+// Resolver is the subset of *net.Resolver used for peer discovery
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// SRVDiscoverer discovers peers from the targets of a DNS SRV record, such as
+// _http._tcp.users.default.svc.cluster.local
+type SRVDiscoverer struct {
+	// Service, Proto and Name identify the record as per net.LookupSRV
+	Service string
+	Proto   string
+	Name    string
+
+	// Scheme and Path of the peers' health endpoints, "http" and "/health" if
+	// not set
+	Scheme string
+	Path   string
+
+	// Self is the host:port of the local instance, which is excluded from the
+	// peers
+	Self string
+
+	// Resolver is used to perform lookups, net.DefaultResolver if nil
+	Resolver Resolver
+}
+
+// Peers looks up the SRV record and returns the health endpoint of every
+// target
+func (d SRVDiscoverer) Peers(ctx context.Context) ([]string, error) {
+	_, records, err := resolver(d.Resolver).LookupSRV(ctx, d.Service, d.Proto, d.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+	}
+
+	return peerURLs(addrs, d.Self, d.Scheme, d.Path), nil
+}
+
+// HostDiscoverer discovers peers from the A/AAAA records of a host, such as a
+// Kubernetes headless service which resolves to the address of every ready pod
+type HostDiscoverer struct {
+	// Host to resolve and the Port every peer serves health on
+	Host string
+	Port int
+
+	// Scheme and Path of the peers' health endpoints, "http" and "/health" if
+	// not set
+	Scheme string
+	Path   string
+
+	// Self is the address of the local instance, which is excluded from the
+	// peers. With Kubernetes this is usually the pod IP.
+	Self string
+
+	// Resolver is used to perform lookups, net.DefaultResolver if nil
+	Resolver Resolver
+}
+
+// Peers resolves the host and returns the health endpoint of every address
+func (d HostDiscoverer) Peers(ctx context.Context) ([]string, error) {
+	hosts, err := resolver(d.Resolver).LookupHost(ctx, d.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	port := strconv.Itoa(d.Port)
+	self := d.Self
+	if self != "" {
+		self = net.JoinHostPort(self, port)
+	}
+
+	addrs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addrs = append(addrs, net.JoinHostPort(host, port))
+	}
+
+	return peerURLs(addrs, self, d.Scheme, d.Path), nil
+}
+
+// StartDiscovery refreshes the peers from `d` every `interval` until ctx is
+// cancelled. Failed lookups keep the previously discovered peers, so a DNS
+// blip doesn't empty the cluster.
+func (c *Cluster) StartDiscovery(ctx context.Context, d PeerDiscoverer, interval time.Duration) {
+	discover := func() {
+		peers, err := d.Peers(ctx)
+		if err != nil {
+			return
+		}
+		c.SetPeers(peers)
+	}
+
+	// discover synchronously first so the first Refresh has peers to poll
+	discover()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				discover()
+			}
+		}
+	}()
+}
+
+// peerURLs builds sorted health endpoint URLs for every address except self
+func peerURLs(addrs []string, self, scheme, path string) []string {
+	if scheme == "" {
+		scheme = "http"
+	}
+	if path == "" {
+		path = "/health"
+	}
+
+	peers := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr == self {
+			continue
+		}
+
+		u := url.URL{Scheme: scheme, Host: addr, Path: path}
+		peers = append(peers, u.String())
+	}
+
+	sort.Strings(peers)
+	return peers
+}
+
+func resolver(r Resolver) Resolver {
+	if r == nil {
+		return net.DefaultResolver
+	}
+	return r
+}
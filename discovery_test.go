@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeResolver struct {
+	srv   []*net.SRV
+	hosts []string
+	err   error
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return "", r.srv, r.err
+}
+
+func (r *fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.hosts, r.err
+}
+
+func TestSRVDiscoverer(t *testing.T) {
+	d := SRVDiscoverer{
+		Service: "http",
+		Proto:   "tcp",
+		Name:    "users.default.svc.cluster.local",
+		Self:    "users-0.users.default.svc.cluster.local:8080",
+		Resolver: &fakeResolver{srv: []*net.SRV{
+			{Target: "users-1.users.default.svc.cluster.local.", Port: 8080},
+			{Target: "users-0.users.default.svc.cluster.local.", Port: 8080},
+		}},
+	}
+
+	peers, err := d.Peers(context.Background())
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := []string{"http://users-1.users.default.svc.cluster.local:8080/health"}
+	if !reflect.DeepEqual(peers, expected) {
+		t.Errorf("expected %v got %v", expected, peers)
+	}
+}
+
+func TestHostDiscoverer(t *testing.T) {
+	d := HostDiscoverer{
+		Host:     "users",
+		Port:     8080,
+		Scheme:   "https",
+		Path:     "/healthz",
+		Self:     "10.0.0.1",
+		Resolver: &fakeResolver{hosts: []string{"10.0.0.3", "10.0.0.1", "fd00::2"}},
+	}
+
+	peers, err := d.Peers(context.Background())
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := []string{"https://10.0.0.3:8080/healthz", "https://[fd00::2]:8080/healthz"}
+	if !reflect.DeepEqual(peers, expected) {
+		t.Errorf("expected %v got %v", expected, peers)
+	}
+}
+
+func TestStartDiscovery(t *testing.T) {
+	self, _ := InitialiseServiceCheck("test", time.Second)
+	cluster := NewCluster(self, []string{"http://static/health"}, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cluster.StartDiscovery(ctx, HostDiscoverer{
+		Host:     "users",
+		Port:     80,
+		Resolver: &fakeResolver{err: errors.New("no such host")},
+	}, time.Hour)
+	if status := cluster.Status(); status.Total != 2 {
+		t.Errorf("expected failed discovery to keep existing peers got %d replicas", status.Total)
+	}
+
+	cluster.StartDiscovery(ctx, HostDiscoverer{
+		Host:     "users",
+		Port:     80,
+		Resolver: &fakeResolver{hosts: []string{"10.0.0.2", "10.0.0.3"}},
+	}, time.Hour)
+	if status := cluster.Status(); status.Total != 3 {
+		t.Errorf("expected discovered peers to be used got %d replicas", status.Total)
+	}
+}
@@ -0,0 +1,284 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SchemaVersion is the revision of the status document's format, encoded as
+// its schemaVersion field. It changes only when the format does, so consumers
+// across a fleet can parse documents from services running different versions
+// of this package.
+const SchemaVersion = 1
+
+// StatusDocument is the status document of a ServiceCheck, as served by
+// HTTPHandler and written by WriteStatus. It is kept apart from ServiceCheck
+// so the check's internal state can change without changing the wire format.
+type StatusDocument struct {
+	SchemaVersion int                  `json:"schemaVersion"`
+	Name          string               `json:"name"`
+	Healthy       bool                 `json:"healthy"`
+	Status        Status               `json:"status"`
+	Dependencies  []DependencyDocument `json:"dependencies"`
+	Draining      bool                 `json:"draining,omitempty"`
+	ShuttingDown  bool                 `json:"shuttingDown,omitempty"`
+	Starting      bool                 `json:"starting,omitempty"`
+	Maintenance   bool                 `json:"maintenance,omitempty"`
+	Message       string               `json:"message"`
+	Metadata      *Metadata            `json:"metadata,omitempty"`
+}
+
+// DependencyDocument is the entry for a dependency in a StatusDocument
+type DependencyDocument struct {
+	Name     string          `json:"name"`
+	Healthy  bool            `json:"healthy"`
+	Level    Level           `json:"level"`
+	URL      string          `json:"url,omitempty"`
+	Remote   *StatusDocument `json:"remote,omitempty"`
+	Stale    bool            `json:"stale,omitempty"`
+	Paused   bool            `json:"paused,omitempty"`
+	Override *bool           `json:"override,omitempty"`
+	Members  []GroupMember   `json:"members,omitempty"`
+
+	// UptimePercent and AvgCheckDurationMs summarise the dependency's
+	// history, only included with HistoryConfig.Summary
+	UptimePercent      *float64 `json:"uptimePercent,omitempty"`
+	AvgCheckDurationMs *float64 `json:"avgCheckDurationMs,omitempty"`
+
+	// LatencyMs is how long the last check took, only included with
+	// WithCheckTimes
+	LatencyMs *float64 `json:"latencyMs,omitempty"`
+
+	LastError   string    `json:"lastError,omitempty"`
+	LastChecked time.Time `json:"lastChecked,omitzero"`
+	LastSuccess time.Time `json:"lastSuccess,omitzero"`
+}
+
+// WithFieldNames renames every field of the status document with `rename`,
+// such as SnakeCase, to match an existing health schema
+func WithFieldNames(rename func(string) string) Option {
+	return func(s *ServiceCheck) {
+		s.rename = rename
+	}
+}
+
+// WithMarshalHook calls `hook` with the status document, after any renaming,
+// before it is encoded. The hook may change the document as it likes, such as
+// adding fields expected by an existing health schema.
+func WithMarshalHook(hook func(doc map[string]interface{})) Option {
+	return func(s *ServiceCheck) {
+		s.marshalHook = hook
+	}
+}
+
+// SnakeCase converts a field name such as "schemaVersion" to "schema_version"
+func SnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// CamelCase converts a field name such as "schema_version" to "schemaVersion"
+func CamelCase(name string) string {
+	var (
+		b     strings.Builder
+		upper bool
+	)
+	for _, r := range name {
+		if r == '_' || r == '-' {
+			upper = b.Len() > 0
+			continue
+		}
+		if upper {
+			r, upper = unicode.ToUpper(r), false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes the status document, including the schemaVersion
+func (s *ServiceCheck) MarshalJSON() ([]byte, error) {
+	status := s.load().status
+	return status.marshal(status.document())
+}
+
+// Document returns the status document as of the last check
+func (s *ServiceCheck) Document() StatusDocument {
+	return s.load().status.document()
+}
+
+// document returns the StatusDocument of a snapshot, or of a ServiceCheck
+// whilst s.mu is held
+func (s *ServiceCheck) document() StatusDocument {
+	doc := StatusDocument{
+		SchemaVersion: SchemaVersion,
+		Name:          s.Name,
+		Healthy:       s.Healthy,
+		Status:        s.serviceStatus(),
+		Draining:      s.Draining,
+		ShuttingDown:  s.ShuttingDown,
+		Starting:      s.Starting,
+		Maintenance:   s.Maintenance,
+		Message:       s.message(),
+		Metadata:      s.metadata,
+	}
+	if s.Dependencies != nil {
+		doc.Dependencies = make([]DependencyDocument, len(s.Dependencies))
+		for i, dependency := range s.Dependencies {
+			doc.Dependencies[i] = dependency.document(s.checkTimes)
+		}
+	}
+
+	return doc
+}
+
+// document returns the entry for the dependency in a StatusDocument, with
+// how long its last check took if `times`
+func (d *Dependency) document(times bool) DependencyDocument {
+	doc := DependencyDocument{
+		Name:        d.Name,
+		Healthy:     d.Healthy,
+		Level:       d.Level,
+		URL:         d.URL,
+		Stale:       d.Stale,
+		Paused:      d.Paused,
+		Override:    d.Override,
+		LastError:   d.LastError,
+		LastChecked: d.LastChecked,
+		LastSuccess: d.LastSuccess,
+	}
+	if d.Members != nil {
+		doc.Members = append([]GroupMember(nil), d.Members...)
+	}
+	if times && d.checked {
+		latency := float64(d.took) / float64(time.Millisecond)
+		doc.LatencyMs = &latency
+	}
+	if d.summary != nil {
+		uptime := d.summary.Availability
+		duration := float64(d.summary.AverageDuration) / float64(time.Millisecond)
+		doc.UptimePercent, doc.AvgCheckDurationMs = &uptime, &duration
+	}
+	if d.Remote != nil {
+		remote := d.Remote.document()
+		doc.Remote = &remote
+	}
+
+	return doc
+}
+
+// marshal encodes `v`, applying WithFieldNames, WithRedactor and
+// WithMarshalHook
+func (s *ServiceCheck) marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || (s.rename == nil && s.redact == nil && s.marshalHook == nil) {
+		return b, err
+	}
+
+	var doc map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	// keep numbers as they were encoded
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	doc = transform(doc, s.rename, s.redact).(map[string]interface{})
+	if s.marshalHook != nil {
+		s.marshalHook(doc)
+	}
+
+	return json.Marshal(doc)
+}
+
+// transform renames the fields of every object within `v` and redacts every
+// string, either function being optional
+func transform(v interface{}, rename, redact func(string) string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		transformed := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			if rename != nil {
+				key = rename(key)
+			}
+			transformed[key] = transform(value, rename, redact)
+		}
+		return transformed
+	case []interface{}:
+		for i, value := range v {
+			v[i] = transform(value, rename, redact)
+		}
+		return v
+	case string:
+		if redact != nil {
+			return redact(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// Message returns a summary of the state of the service for humans, such as
+// "degraded: 2 soft dependencies failing (redis, cache)". It is included in
+// the status document as message.
+func (s *ServiceCheck) Message() string {
+	return s.load().status.message()
+}
+
+// message returns the Message of a snapshot, or of a ServiceCheck whilst s.mu
+// is held
+func (s *ServiceCheck) message() string {
+	var names [LevelInformational + 1][]string
+	for _, dependency := range s.Dependencies {
+		if !dependency.Healthy && dependency.Level <= LevelInformational {
+			names[dependency.Level] = append(names[dependency.Level], dependency.Name)
+		}
+	}
+
+	var reasons []string
+	for _, level := range []Level{LevelHard, LevelSoft, LevelInformational} {
+		if len(names[level]) > 0 {
+			reasons = append(reasons, failing(names[level], level))
+		}
+	}
+
+	state := s.serviceStatus().String()
+	if len(reasons) > 0 {
+		state += ": " + strings.Join(reasons, ", ")
+	}
+
+	switch {
+	case s.ShuttingDown:
+		state = "shutting down, " + state
+	case s.Draining:
+		state = "draining, " + state
+	case s.Maintenance:
+		state = "maintenance, " + state
+	case s.Starting:
+		state = "starting, " + state
+	}
+	return state
+}
+
+// failing describes the named dependencies of `level` as failing
+func failing(names []string, level Level) string {
+	noun := "dependencies"
+	if len(names) == 1 {
+		noun = "dependency"
+	}
+	return fmt.Sprintf("%d %s %s failing (%s)", len(names), level, noun, strings.Join(names, ", "))
+}
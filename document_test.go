@@ -0,0 +1,139 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock))
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	var b bytes.Buffer
+	if err := check.WriteStatus(&b); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := `{"schemaVersion":1,"name":"api","healthy":true,"status":"healthy","dependencies":[{"name":"db","healthy":true,"level":"hard","lastChecked":"2020-01-01T00:00:00Z","lastSuccess":"2020-01-01T00:00:00Z"}],"message":"healthy"}`
+	if got := strings.TrimSpace(b.String()); got != expected {
+		t.Errorf("expected %s got %s", expected, got)
+	}
+
+	// documents still decode into a ServiceCheck
+	var decoded ServiceCheck
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil || decoded.Name != "api" || len(decoded.Dependencies) != 1 {
+		t.Errorf("expected the document to decode got %v (%v)", decoded.Name, err)
+	}
+}
+
+func TestFieldNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		snake string
+		camel string
+	}{
+		{"schemaVersion", "schema_version", "schemaVersion"},
+		{"healthy", "healthy", "healthy"},
+		{"lastCheckedAt", "last_checked_at", "lastCheckedAt"},
+	}
+
+	for _, test := range tests {
+		if got := SnakeCase(test.name); got != test.snake {
+			t.Errorf("expected %s got %s", test.snake, got)
+		}
+		if got := CamelCase(test.snake); got != test.camel {
+			t.Errorf("expected %s got %s", test.camel, got)
+		}
+	}
+}
+
+func TestWithFieldNames(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("api", time.Minute,
+		WithClock(clock),
+		WithFieldNames(SnakeCase),
+		WithMarshalHook(func(doc map[string]interface{}) {
+			doc["status"] = "pass"
+			delete(doc, "healthy")
+		}),
+	)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	b, err := json.Marshal(check)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := `{"dependencies":[{"healthy":true,"last_checked":"2020-01-01T00:00:00Z","last_success":"2020-01-01T00:00:00Z","level":"hard","name":"db"}],"message":"healthy","name":"api","schema_version":1,"status":"pass"}`
+	if string(b) != expected {
+		t.Errorf("expected %s got %s", expected, b)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		hard, soft []bool
+		drain      func(*ServiceCheck)
+		expected   string
+	}{
+		{[]bool{true}, []bool{true}, nil, "healthy"},
+		{[]bool{true}, []bool{false, false}, nil, "degraded: 2 soft dependencies failing (soft0, soft1)"},
+		{[]bool{false}, nil, nil, "unhealthy: 1 hard dependency failing (hard0)"},
+		{[]bool{false}, []bool{false}, nil, "unhealthy: 1 hard dependency failing (hard0), 1 soft dependency failing (soft0)"},
+		{nil, nil, (*ServiceCheck).Drain, "draining, healthy"},
+		{nil, nil, (*ServiceCheck).BeginShutdown, "shutting down, healthy"},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute)
+		for i, healthy := range test.hard {
+			healthy := healthy
+			check.RegisterDependency(fmt.Sprintf("hard%d", i), LevelHard, func() bool { return healthy })
+		}
+		for i, healthy := range test.soft {
+			healthy := healthy
+			check.RegisterDependency(fmt.Sprintf("soft%d", i), LevelSoft, func() bool { return healthy })
+		}
+		check.Update()
+		if test.drain != nil {
+			test.drain(check)
+		}
+
+		if got := check.Message(); got != test.expected {
+			t.Errorf("expected %s got %s", test.expected, got)
+		}
+	}
+}
+
+func TestDocument(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return false })
+	check.Update()
+
+	doc := check.Document()
+	if doc.SchemaVersion != SchemaVersion || doc.Name != "api" || doc.Healthy {
+		t.Errorf("expected an unhealthy api document got %+v", doc)
+	}
+	if len(doc.Dependencies) != 1 || doc.Dependencies[0].Name != "db" || doc.Dependencies[0].Healthy {
+		t.Fatalf("expected an unhealthy db got %+v", doc.Dependencies)
+	}
+
+	// the document is encoded the same as the status
+	var b bytes.Buffer
+	check.WriteStatus(&b)
+	encoded, _ := json.Marshal(doc)
+	if got := strings.TrimSpace(b.String()); got != string(encoded) {
+		t.Errorf("expected %s got %s", encoded, got)
+	}
+
+	// changing the document doesn't change the check
+	doc.Dependencies[0].Healthy = true
+	if check.Document().Dependencies[0].Healthy {
+		t.Errorf("expected the check to be unchanged")
+	}
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DrainDelay is how long HandleSignals waits after starting to drain before
+// signalling the application to shut down, giving load balancers time to
+// notice the failing health check
+var DrainDelay = 15 * time.Second
+
+// Drain marks the service as draining, so HTTPHandler responds 503 and load
+// balancers stop routing new requests to it, whilst IsHealthy is unaffected so
+// in-flight work carries on. Draining can't be undone.
+func (s *ServiceCheck) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Draining {
+		s.Draining = true
+		s.notifyChanged()
+	}
+}
+
+// BeginShutdown starts draining the service for shutdown, as Drain, so
+// readiness fails whilst liveness is unaffected and Changed and
+// OnStatusChange callbacks are notified. The status document is flagged as
+// shuttingDown as well as draining. It can't be undone.
+func (s *ServiceCheck) BeginShutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ShuttingDown {
+		s.Draining, s.ShuttingDown = true, true
+		s.notifyChanged()
+	}
+}
+
+// IsDraining returns a bool whether Drain has been called
+func (s *ServiceCheck) IsDraining() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Draining
+}
+
+// HandleSignals starts draining when one of `signals`, SIGTERM if none are
+// given, is received, and returns a context which is cancelled DrainDelay
+// later to signal the application to shut down. A second signal cancels it
+// straight away. The context is also cancelled with ctx.
+//
+//	ctx := check.HandleSignals(context.Background())
+//	<-ctx.Done()
+//	server.Shutdown(context.Background())
+func (s *ServiceCheck) HandleSignals(ctx context.Context, signals ...os.Signal) context.Context {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	shutdown, cancel := context.WithCancel(ctx)
+	go func() {
+		defer signal.Stop(ch)
+		defer cancel()
+		s.handleSignals(ctx, ch, DrainDelay)
+	}()
+
+	return shutdown
+}
+
+// handleSignals drains on the first signal from `ch` and returns `delay`
+// later, on a second signal or once ctx is cancelled
+func (s *ServiceCheck) handleSignals(ctx context.Context, ch <-chan os.Signal, delay time.Duration) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-ch:
+	}
+
+	s.Drain()
+
+	select {
+	case <-ctx.Done():
+	case <-s.getClock().After(delay):
+	case <-ch:
+	}
+}
@@ -0,0 +1,140 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	check := &ServiceCheck{Name: "test", Healthy: true}
+	changed := check.Changed()
+	check.Drain()
+
+	select {
+	case <-changed:
+	default:
+		t.Error("expected draining to be a change")
+	}
+
+	if !check.IsDraining() || !check.IsHealthy() {
+		t.Errorf("expected draining and healthy got %v %v", check.IsDraining(), check.IsHealthy())
+	}
+
+	w := httptest.NewRecorder()
+	check.HTTPHandler(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 503 {
+		t.Errorf("expected 503 got %v", w.Code)
+	}
+}
+
+func TestBeginShutdown(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Minute)
+	docs := make(chan StatusDocument, 1)
+	cancel := check.OnStatusChange(func(ctx context.Context, doc StatusDocument) {
+		docs <- doc
+	})
+	defer cancel()
+
+	check.BeginShutdown()
+	select {
+	case doc := <-docs:
+		if !doc.Draining || !doc.ShuttingDown {
+			t.Errorf("expected the document to be flagged as shutting down got %+v", doc)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the callback to be called")
+	}
+
+	for _, test := range []struct {
+		handler  func(w http.ResponseWriter, r *http.Request)
+		expected int
+	}{
+		{check.ReadinessHandler, 503},
+		{check.LivenessHandler, 200},
+	} {
+		w := httptest.NewRecorder()
+		test.handler(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %d got %d", test.expected, w.Code)
+		}
+	}
+}
+
+func TestBeginShutdownDocument(t *testing.T) {
+	tests := []struct {
+		opts     []Option
+		expected string
+	}{
+		{nil, `"draining":true,"shuttingDown":true`},
+		{[]Option{WithFieldNames(SnakeCase)}, `"shutting_down":true`},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("test", time.Minute, test.opts...)
+		check.BeginShutdown()
+
+		var b bytes.Buffer
+		check.WriteStatus(&b)
+		if !strings.Contains(b.String(), test.expected) {
+			t.Errorf("expected %s in %s", test.expected, b.String())
+		}
+	}
+}
+
+func TestHandleSignals(t *testing.T) {
+	tests := []struct {
+		name     string
+		signals  int
+		delay    time.Duration
+		deadline time.Duration
+	}{
+		{"waits for the delay", 1, 100 * time.Millisecond, time.Second},
+		{"second signal skips the delay", 2, time.Hour, time.Second},
+	}
+
+	for _, test := range tests {
+		check := &ServiceCheck{Name: "test", Healthy: true}
+		ch := make(chan os.Signal, 2)
+		done := make(chan struct{})
+		go func() {
+			check.handleSignals(context.Background(), ch, test.delay)
+			close(done)
+		}()
+
+		for i := 0; i < test.signals; i++ {
+			ch <- os.Interrupt
+		}
+
+		select {
+		case <-done:
+		case <-time.After(test.deadline):
+			t.Fatalf("%s: expected to return", test.name)
+		}
+		if !check.IsDraining() {
+			t.Errorf("%s: expected to be draining", test.name)
+		}
+	}
+}
+
+func TestHandleSignalsCancelled(t *testing.T) {
+	check := &ServiceCheck{Name: "test", Healthy: true}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	shutdown := check.HandleSignals(ctx, os.Interrupt)
+	cancel()
+
+	select {
+	case <-shutdown.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected shutdown to be cancelled with the parent")
+	}
+	if check.IsDraining() {
+		t.Error("expected not to drain without a signal")
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+// DuplicatePolicy decides what RegisterDependency does when the name is
+// already registered, see WithDuplicatePolicy
+type DuplicatePolicy int
+
+const (
+	// DuplicateError returns ErrDependencyAlreadyRegistered, the default
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateReplace replaces the registered dependency, in its place, with
+	// the new one
+	DuplicateReplace
+	// DuplicateMerge gives the registered dependency the new check, level and
+	// options, keeping its health until the next check. Handles to it remain
+	// valid, and it is returned as the handle.
+	DuplicateMerge
+)
+
+// WithDuplicatePolicy sets what RegisterDependency does when the name is
+// already registered, for systems which register their dependencies again
+// on reload
+func WithDuplicatePolicy(policy DuplicatePolicy) Option {
+	return func(s *ServiceCheck) {
+		s.duplicatePolicy = policy
+	}
+}
+
+// registerDuplicate registers `dep` in place of the dependency at index `i`
+// as per the duplicate policy, s.mu must be held
+func (s *ServiceCheck) registerDuplicate(i int, dep *Dependency) (*Dependency, error) {
+	switch s.duplicatePolicy {
+	case DuplicateReplace:
+		s.forget(s.Dependencies[i])
+		s.Dependencies[i] = dep
+	case DuplicateMerge:
+		existing := s.Dependencies[i]
+		existing.Level = dep.Level
+		existing.URL = dep.URL
+		existing.check = dep.check
+		existing.errs = dep.errs
+		existing.probes = dep.probes
+		existing.interval = dep.interval
+		existing.schedule = dep.schedule
+		existing.timeout = dep.timeout
+		existing.maxLatency = dep.maxLatency
+		existing.weight = dep.weight
+		existing.failures, existing.successes = dep.failures, dep.successes
+		existing.remote = dep.remote
+		existing.async = dep.async
+		existing.group = dep.group
+		existing.breaker = dep.breaker
+		existing.adaptive = dep.adaptive
+		existing.ttl = dep.ttl
+		existing.releaseShared()
+		existing.sharedRef = dep.sharedRef
+		dep = existing
+	default:
+		dep.releaseShared()
+		return nil, s.dependencyError(dep.Name, ErrDependencyAlreadyRegistered)
+	}
+
+	s.recordHealth(true)
+	return dep, nil
+}
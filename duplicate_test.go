@@ -0,0 +1,60 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDuplicatePolicy(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	if _, err := check.RegisterDependency("db", LevelHard, func() bool { return true }); !errors.Is(err, ErrDependencyAlreadyRegistered) {
+		t.Errorf("expected %v got %v", ErrDependencyAlreadyRegistered, err)
+	}
+}
+
+func TestDuplicateReplace(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithDuplicatePolicy(DuplicateReplace))
+	original, _ := check.RegisterDependency("db", LevelSoft, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+
+	replacement, err := check.RegisterDependency("db", LevelHard, func() bool { return false })
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if replacement == original {
+		t.Error("expected a new handle")
+	}
+
+	states := check.DependencyStates()
+	if len(states) != 2 || states[0].Name != "db" || states[0].Level != LevelHard || states[0].Healthy {
+		t.Errorf("expected an unhealthy hard db in its original place got %+v", states)
+	}
+	if check.IsHealthy() {
+		t.Error("expected the replacement to make the service unhealthy")
+	}
+}
+
+func TestDuplicateMerge(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithDuplicatePolicy(DuplicateMerge))
+	original, _ := check.RegisterDependency("db", LevelSoft, func() bool { return true })
+	original.Pause()
+
+	merged, err := check.RegisterDependency("db", LevelHard, func() bool { return false }, WithURL("http://db/health"))
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if merged != original {
+		t.Error("expected the existing handle")
+	}
+	if merged.Level != LevelHard || merged.URL != "http://db/health" || !merged.IsHealthy() || !merged.IsPaused() {
+		t.Errorf("expected the new settings with the existing state got %+v", merged)
+	}
+
+	merged.Resume()
+	check.Update()
+	if merged.IsHealthy() || check.IsHealthy() {
+		t.Error("expected the next check to use the new check")
+	}
+}
@@ -0,0 +1,51 @@
+// Package echohealth adapts the health handlers and middleware to Echo,
+// mirroring the net/http surface of the health package.
+package echohealth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/fresh8/health"
+)
+
+// Router is satisfied by both *echo.Echo and *echo.Group
+type Router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// Handler returns an echo.HandlerFunc outputting the status of `check` with
+// the relevant response code
+func Handler(check *health.ServiceCheck) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		check.HTTPHandler(c.Response(), c.Request())
+		return nil
+	}
+}
+
+// Shed returns Echo middleware which responds 503 Service Unavailable whilst
+// `check` is unhealthy, as per ServiceCheck.ShedMiddleware
+func Shed(check *health.ServiceCheck, retryAfter time.Duration, exempt ...string) echo.MiddlewareFunc {
+	shed := check.ShedMiddleware(retryAfter, exempt...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var passed bool
+			shed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				passed = true
+			})).ServeHTTP(c.Response(), c.Request())
+
+			if !passed {
+				return nil
+			}
+			return next(c)
+		}
+	}
+}
+
+// Register mounts Handler on `r` at `path`
+func Register(r Router, path string, check *health.ServiceCheck) {
+	r.GET(path, Handler(check))
+}
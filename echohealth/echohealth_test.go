@@ -0,0 +1,66 @@
+package echohealth
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/fresh8/health"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		healthy  bool
+		expected int
+	}{
+		{true, 200},
+		{false, 503},
+	}
+
+	for _, test := range tests {
+		e := echo.New()
+		Register(e, "/health", &health.ServiceCheck{Name: "api", Healthy: test.healthy})
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v", test.expected, w.Code)
+		}
+	}
+}
+
+func TestShed(t *testing.T) {
+	check := &health.ServiceCheck{Name: "api"}
+
+	e := echo.New()
+	e.Use(Shed(check, time.Second, "/internal/"))
+	Register(e.Group("/internal"), "/health", check)
+	e.GET("/orders", func(c echo.Context) error { return c.String(200, "ok") })
+
+	tests := []struct {
+		path     string
+		healthy  bool
+		expected int
+		body     string
+	}{
+		{"/orders", true, 200, "ok"},
+		{"/orders", false, 503, "Service Unavailable"},
+		{"/internal/health", false, 503, `"healthy":false`},
+	}
+
+	for _, test := range tests {
+		check.Healthy = test.healthy
+
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, w.Code, test.path)
+		}
+		if !strings.Contains(w.Body.String(), test.body) {
+			t.Errorf("expected %q in %q for %s", test.body, w.Body.String(), test.path)
+		}
+	}
+}
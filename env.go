@@ -0,0 +1,108 @@
+package health
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variables overriding the options of every ServiceCheck, so
+// health can be tuned per environment without rebuilding. They take
+// precedence over the arguments and options the check and its dependencies
+// are given, unless WithoutEnv is used.
+const (
+	// EnvInterval overrides the interval between checks of the service
+	EnvInterval = "HEALTH_INTERVAL"
+	// EnvTimeout overrides the timeout of every dependency, see WithTimeout
+	EnvTimeout = "HEALTH_TIMEOUT"
+	// EnvFailureThreshold overrides the failure threshold of every
+	// dependency, see WithThreshold
+	EnvFailureThreshold = "HEALTH_FAILURE_THRESHOLD"
+	// EnvSuccessThreshold overrides the success threshold of every
+	// dependency, see WithThreshold
+	EnvSuccessThreshold = "HEALTH_SUCCESS_THRESHOLD"
+)
+
+// WithoutEnv ignores the Env environment variables, for checks which mustn't
+// be tuned outside the code, such as in tests
+func WithoutEnv() Option {
+	return func(s *ServiceCheck) {
+		s.ignoreEnv = true
+	}
+}
+
+// envOverrides are the service and dependency settings overridden by the
+// environment
+type envOverrides struct {
+	interval  time.Duration
+	timeout   time.Duration
+	failures  int
+	successes int
+}
+
+// loadEnv reads the Env environment variables, once every option is applied
+func (s *ServiceCheck) loadEnv() error {
+	if s.ignoreEnv {
+		return nil
+	}
+
+	if err := envDuration(EnvInterval, &s.env.interval); err != nil {
+		return err
+	}
+	if s.env.interval > 0 {
+		s.duration = s.env.interval
+	}
+
+	if err := envDuration(EnvTimeout, &s.env.timeout); err != nil {
+		return err
+	}
+	if err := envInt(EnvFailureThreshold, &s.env.failures); err != nil {
+		return err
+	}
+	return envInt(EnvSuccessThreshold, &s.env.successes)
+}
+
+// apply overrides the settings of `dep` with those of the environment, once
+// its options are applied
+func (e envOverrides) apply(dep *Dependency) {
+	if e.timeout > 0 {
+		dep.timeout = e.timeout
+	}
+	if e.failures > 0 {
+		dep.failures = e.failures
+	}
+	if e.successes > 0 {
+		dep.successes = e.successes
+	}
+}
+
+func envDuration(name string, d *time.Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("%w %s %q", ErrInvalidEnv, name, v)
+	}
+
+	*d = parsed
+	return nil
+}
+
+func envInt(name string, n *int) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("%w %s %q", ErrInvalidEnv, name, v)
+	}
+
+	*n = parsed
+	return nil
+}
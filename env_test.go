@@ -0,0 +1,53 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv(EnvInterval, "1m")
+	t.Setenv(EnvTimeout, "500ms")
+	t.Setenv(EnvFailureThreshold, "4")
+
+	check, err := InitialiseServiceCheck("api", time.Second)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if check.duration != time.Minute {
+		t.Errorf("expected 1m got %v", check.duration)
+	}
+
+	// ensure the environment takes precedence over the options
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true }, WithTimeout(time.Second), WithThreshold(2, 2))
+	if db.timeout != 500*time.Millisecond || db.failures != 4 || db.successes != 2 {
+		t.Errorf("expected 500ms, 4 and 2 got %v, %d and %d", db.timeout, db.failures, db.successes)
+	}
+
+	ignored, _ := InitialiseServiceCheck("api", time.Second, WithoutEnv())
+	if ignored.duration != time.Second {
+		t.Errorf("expected 1s got %v", ignored.duration)
+	}
+}
+
+func TestEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{EnvInterval, "often"},
+		{EnvTimeout, "-1s"},
+		{EnvFailureThreshold, "three"},
+		{EnvSuccessThreshold, "0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(test.name, test.value)
+			if _, err := InitialiseServiceCheck("api", time.Second); !errors.Is(err, ErrInvalidEnv) {
+				t.Errorf("expected %v got %v", ErrInvalidEnv, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package envoy reports the health of a ServiceCheck to Envoy using Envoy's own
+// conventions, so mesh deployments act on dependency-aware health rather than
+// shallow TCP checks:
+//
+//   - Handler answers Envoy active HTTP health checks, marking the host
+//     degraded with the x-envoy-degraded header whilst soft dependencies fail
+//   - Middleware adds x-envoy-immediate-health-check-fail to application
+//     responses whilst unhealthy, so Envoy ejects the host without waiting for
+//     the next health check
+//   - HealthStatus maps the service onto Envoy's core.HealthStatus, for
+//     control planes which set the health of endpoints they serve over EDS
+package envoy
+
+import (
+	"net/http"
+
+	"github.com/fresh8/health"
+)
+
+// HealthStatus mirrors the values of Envoy's envoy.config.core.v3.HealthStatus
+// enum, and converts directly to it
+type HealthStatus int32
+
+// Values of Envoy's envoy.config.core.v3.HealthStatus
+const (
+	HealthStatusUnknown   HealthStatus = 0
+	HealthStatusHealthy   HealthStatus = 1
+	HealthStatusUnhealthy HealthStatus = 2
+	HealthStatusDraining  HealthStatus = 3
+	HealthStatusTimeout   HealthStatus = 4
+	HealthStatusDegraded  HealthStatus = 5
+)
+
+// Headers understood by Envoy
+const (
+	HeaderDegraded                 = "x-envoy-degraded"
+	HeaderImmediateHealthCheckFail = "x-envoy-immediate-health-check-fail"
+)
+
+// Status returns the Envoy health status of `check`. A healthy service with
+// failing soft dependencies is degraded.
+func Status(check *health.ServiceCheck) HealthStatus {
+	if !check.IsHealthy() {
+		return HealthStatusUnhealthy
+	}
+	if softFailing(check) {
+		return HealthStatusDegraded
+	}
+	return HealthStatusHealthy
+}
+
+// Handler returns a http.Handler for Envoy active HTTP health checks against
+// `check`. Envoy treats the host as degraded when a healthy response carries
+// the x-envoy-degraded header.
+func Handler(check *health.ServiceCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Status(check) == HealthStatusDegraded {
+			w.Header().Set(HeaderDegraded, "true")
+		}
+
+		check.HTTPHandler(w, r)
+	})
+}
+
+// Middleware wraps application handlers, adding the
+// x-envoy-immediate-health-check-fail header to every response whilst `check`
+// is unhealthy. With Envoy's health check filter configured this fails the
+// host immediately, rather than after the next failed health check.
+func Middleware(check *health.ServiceCheck, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !check.IsHealthy() {
+			w.Header().Set(HeaderImmediateHealthCheckFail, "true")
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// softFailing returns whether any soft dependency of `check` is unhealthy
+func softFailing(check *health.ServiceCheck) bool {
+	for _, dependency := range check.DependencyStates() {
+		if dependency.Level == health.LevelSoft && !dependency.Healthy {
+			return true
+		}
+	}
+	return false
+}
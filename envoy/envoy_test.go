@@ -0,0 +1,79 @@
+package envoy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fresh8/health/healthtest"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		hard, soft bool
+		expected   HealthStatus
+	}{
+		{true, true, HealthStatusHealthy},
+		{true, false, HealthStatusDegraded},
+		{false, true, HealthStatusUnhealthy},
+	}
+
+	for _, test := range tests {
+		check := healthtest.NewServiceCheck("test", healthtest.Hard("db", test.hard), healthtest.Soft("cache", test.soft))
+		if status := Status(check); status != test.expected {
+			t.Errorf("expected %v got %v", test.expected, status)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		hard, soft       bool
+		expectedCode     int
+		expectedDegraded string
+	}{
+		{true, true, http.StatusOK, ""},
+		{true, false, http.StatusOK, "true"},
+		{false, false, http.StatusServiceUnavailable, ""},
+	}
+
+	for _, test := range tests {
+		check := healthtest.NewServiceCheck("test", healthtest.Hard("db", test.hard), healthtest.Soft("cache", test.soft))
+		rec := httptest.NewRecorder()
+		Handler(check).ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+
+		if rec.Code != test.expectedCode {
+			t.Errorf("expected %d got %d", test.expectedCode, rec.Code)
+		}
+		if degraded := rec.Header().Get(HeaderDegraded); degraded != test.expectedDegraded {
+			t.Errorf("expected %q got %q", test.expectedDegraded, degraded)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	app := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	tests := []struct {
+		healthy  bool
+		expected string
+	}{
+		{true, ""},
+		{false, "true"},
+	}
+
+	for _, test := range tests {
+		check := healthtest.NewServiceCheck("test", healthtest.Hard("db", test.healthy), healthtest.Soft("cache", true))
+		rec := httptest.NewRecorder()
+		Middleware(check, app).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		if fail := rec.Header().Get(HeaderImmediateHealthCheckFail); fail != test.expected {
+			t.Errorf("expected %q got %q", test.expected, fail)
+		}
+		if rec.Body.String() != "ok" {
+			t.Errorf("expected the application to still be served got %q", rec.Body.String())
+		}
+	}
+}
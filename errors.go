@@ -0,0 +1,89 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DependencyError is returned by operations on a dependency, recording which
+// service and dependency they were for. It matches the sentinel error it
+// wraps with errors.Is, such as ErrDependencyAlreadyRegistered.
+type DependencyError struct {
+	Service    string
+	Dependency string
+	Err        error
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("%s: dependency %q: %v", e.Service, e.Dependency, e.Err)
+}
+
+// Unwrap returns the sentinel error
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// ServiceError is returned by operations on a service within a Registry,
+// recording which service they were for. It matches the sentinel error it
+// wraps with errors.Is, such as ErrServiceAlreadyRegistered.
+type ServiceError struct {
+	Service string
+	Err     error
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("service %q: %v", e.Service, e.Err)
+}
+
+// Unwrap returns the sentinel error
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// ResponseError is returned when the response of another service's health
+// endpoint isn't a status document, recording its URL, status code and
+// Content-Type. It matches the error it wraps, ErrResponseTooLarge,
+// ErrUnexpectedContentType or the decoding error, with errors.Is and
+// errors.As.
+type ResponseError struct {
+	URL         string
+	StatusCode  int
+	ContentType string
+	Err         error
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s: status %d: %v", e.URL, e.StatusCode, e.Err)
+}
+
+// Unwrap returns the wrapped error
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// dependencyError returns a DependencyError for the named dependency of `s`
+func (s *ServiceCheck) dependencyError(name string, err error) error {
+	return &DependencyError{Service: s.Name, Dependency: name, Err: err}
+}
+
+// Err returns nil whilst the service is healthy. Otherwise it returns the
+// errors.Join of a DependencyError wrapping ErrUnhealthy for every failing
+// dependency, as of the last check.
+func (s *ServiceCheck) Err() error {
+	status := s.load().status
+	if status.Healthy {
+		return nil
+	}
+
+	var errs []error
+	for _, dependency := range status.Dependencies {
+		if !dependency.Healthy {
+			errs = append(errs, s.dependencyError(dependency.Name, ErrUnhealthy))
+		}
+	}
+	if len(errs) == 0 {
+		return ErrUnhealthy
+	}
+
+	return errors.Join(errs...)
+}
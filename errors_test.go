@@ -0,0 +1,70 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDependencyError(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	_, err := check.RegisterDependency("db", LevelHard, func() bool { return true })
+	if !errors.Is(err, ErrDependencyAlreadyRegistered) {
+		t.Errorf("expected %v got %v", ErrDependencyAlreadyRegistered, err)
+	}
+
+	var dependencyErr *DependencyError
+	if !errors.As(err, &dependencyErr) || dependencyErr.Service != "api" || dependencyErr.Dependency != "db" {
+		t.Errorf("expected a DependencyError for api and db got %#v", err)
+	}
+
+	expected := `api: dependency "db": dependent already registered`
+	if err.Error() != expected {
+		t.Errorf("expected %s got %s", expected, err)
+	}
+
+	if _, err := check.Dependency("cache"); !errors.Is(err, ErrNoDependency) {
+		t.Errorf("expected %v got %v", ErrNoDependency, err)
+	}
+}
+
+func TestServiceError(t *testing.T) {
+	registry := NewRegistry()
+	_, err := registry.ServiceCheck("orders")
+	if !errors.Is(err, ErrNoServiceCheck) {
+		t.Errorf("expected %v got %v", ErrNoServiceCheck, err)
+	}
+
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Service != "orders" {
+		t.Errorf("expected a ServiceError for orders got %#v", err)
+	}
+
+	expected := `service "orders": no service check registered`
+	if err.Error() != expected {
+		t.Errorf("expected %s got %s", expected, err)
+	}
+}
+
+func TestErr(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+	if err := check.Err(); err != nil {
+		t.Errorf("expected %v got %v whilst healthy", nil, err)
+	}
+
+	check.RegisterDependency("db", LevelHard, func() bool { return false })
+	check.Update()
+
+	err := check.Err()
+	if !errors.Is(err, ErrUnhealthy) {
+		t.Errorf("expected %v got %v", ErrUnhealthy, err)
+	}
+
+	expected := "api: dependency \"cache\": unhealthy\napi: dependency \"db\": unhealthy"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+}
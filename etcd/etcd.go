@@ -0,0 +1,274 @@
+// Package etcd shares health state through etcd, writing each instance's
+// status under a common prefix with a lease so that tooling can read the
+// health of the whole fleet without scraping every instance over HTTP.
+//
+// It talks to etcd through the v3 JSON gateway, so needs no etcd client
+// library.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+const (
+	// DefaultEndpoint is the address of a local etcd member
+	DefaultEndpoint = "http://127.0.0.1:2379"
+	// DefaultPrefix is the key prefix used when Store.Prefix is empty
+	DefaultPrefix = "/health/"
+	// DefaultTTL is the lease TTL used when Store.TTL is not set
+	DefaultTTL = 30 * time.Second
+)
+
+// Store writes and reads instance health under a prefix in etcd
+type Store struct {
+	// Endpoint of an etcd member, DefaultEndpoint if empty
+	Endpoint string
+	// Prefix every instance's key is written under, DefaultPrefix if empty
+	Prefix string
+	// TTL of the lease attached to an instance's key, so instances which stop
+	// publishing drop out of the fleet. DefaultTTL if not set
+	TTL time.Duration
+	// Client is used to make requests, a client with a 5 second timeout is
+	// used if nil
+	Client *http.Client
+
+	mu    sync.Mutex
+	lease string
+}
+
+var defaultClient = &http.Client{Timeout: 5 * time.Second}
+
+type kv struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	Lease string `json:"lease,omitempty"`
+}
+
+type rangeRequest struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end"`
+}
+
+type rangeResponse struct {
+	KVs []kv `json:"kvs"`
+}
+
+type leaseRequest struct {
+	ID  string `json:"ID,omitempty"`
+	TTL string `json:"TTL,omitempty"`
+}
+
+type leaseResponse struct {
+	ID     string `json:"ID"`
+	TTL    string `json:"TTL"`
+	Result *struct {
+		ID  string `json:"ID"`
+		TTL string `json:"TTL"`
+	} `json:"result"`
+}
+
+// Publish writes the status of `check` under the key for `instance`, attached
+// to the store's lease. The lease is granted on the first publish and kept
+// alive by every publish after it.
+func (s *Store) Publish(ctx context.Context, instance string, check *health.ServiceCheck) error {
+	var value bytes.Buffer
+	if err := check.WriteStatus(&value); err != nil {
+		return err
+	}
+
+	lease, err := s.keepAlive(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.call(ctx, "/v3/kv/put", kv{
+		Key:   encode(s.prefix() + instance),
+		Value: encode(strings.TrimSpace(value.String())),
+		Lease: lease,
+	}, nil)
+}
+
+// Fleet reads back the status of every instance currently published under
+// the prefix, keyed by instance
+func (s *Store) Fleet(ctx context.Context) (map[string]*health.ServiceCheck, error) {
+	prefix := s.prefix()
+
+	var resp rangeResponse
+	err := s.call(ctx, "/v3/kv/range", rangeRequest{
+		Key:      encode(prefix),
+		RangeEnd: encode(prefixEnd(prefix)),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	fleet := make(map[string]*health.ServiceCheck, len(resp.KVs))
+	for _, pair := range resp.KVs {
+		key, err := base64.StdEncoding.DecodeString(pair.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := base64.StdEncoding.DecodeString(pair.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		check := &health.ServiceCheck{}
+		if err := json.Unmarshal(value, check); err != nil {
+			return nil, fmt.Errorf("decoding %s: %v", key, err)
+		}
+		fleet[strings.TrimPrefix(string(key), prefix)] = check
+	}
+
+	return fleet, nil
+}
+
+// Start publishes the status of `check` every `interval` until ctx is
+// cancelled, at which point the lease is revoked and the instance drops out of
+// the fleet. `interval` should be comfortably shorter than the TTL.
+func (s *Store) Start(ctx context.Context, instance string, check *health.ServiceCheck, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.Publish(ctx, instance, check)
+
+			select {
+			case <-ctx.Done():
+				// ctx is already cancelled, give revocation its own deadline
+				revokeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				s.Revoke(revokeCtx)
+				cancel()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Revoke revokes the store's lease, deleting every key published with it
+func (s *Store) Revoke(ctx context.Context) error {
+	s.mu.Lock()
+	lease := s.lease
+	s.lease = ""
+	s.mu.Unlock()
+
+	if lease == "" {
+		return nil
+	}
+	return s.call(ctx, "/v3/lease/revoke", leaseRequest{ID: lease}, nil)
+}
+
+// keepAlive refreshes the store's lease, granting a new one if there is none
+// or it has already expired
+func (s *Store) keepAlive(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lease != "" {
+		var resp leaseResponse
+		err := s.call(ctx, "/v3/lease/keepalive", leaseRequest{ID: s.lease}, &resp)
+		if err == nil && resp.Result != nil && resp.Result.TTL != "" && resp.Result.TTL != "0" {
+			return s.lease, nil
+		}
+		s.lease = ""
+	}
+
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	var resp leaseResponse
+	err := s.call(ctx, "/v3/lease/grant", leaseRequest{
+		TTL: strconv.FormatInt(int64(ttl/time.Second), 10),
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("etcd granted no lease")
+	}
+
+	s.lease = resp.ID
+	return s.lease, nil
+}
+
+// call POSTs `body` to the gateway endpoint `path`, decoding the response into
+// `out` if it's not nil
+func (s *Store) call(ctx context.Context, path string, body, out interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(endpoint, "/")+path, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd responded to %s with status code %d", path, resp.StatusCode)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (s *Store) prefix() string {
+	if s.Prefix == "" {
+		return DefaultPrefix
+	}
+	return s.Prefix
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// prefixEnd returns the range end covering every key starting with prefix
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+
+	// every byte is 0xff, range to the end of the keyspace
+	return "\x00"
+}
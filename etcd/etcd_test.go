@@ -0,0 +1,168 @@
+package etcd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// fakeGateway is an in-memory stand in for the etcd v3 JSON gateway
+type fakeGateway struct {
+	mu     sync.Mutex
+	kvs    map[string]kv
+	leases map[string]bool
+	next   int
+}
+
+func newFakeGateway() *fakeGateway {
+	return &fakeGateway{kvs: map[string]kv{}, leases: map[string]bool{}}
+}
+
+func decode(s string) string {
+	b, _ := base64.StdEncoding.DecodeString(s)
+	return string(b)
+}
+
+func (g *fakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		g.next++
+		id := strconv.Itoa(g.next)
+		g.leases[id] = true
+		json.NewEncoder(w).Encode(map[string]string{"ID": id, "TTL": "30"})
+	case "/v3/lease/keepalive":
+		var req leaseRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		ttl := "0"
+		if g.leases[req.ID] {
+			ttl = "30"
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]string{"ID": req.ID, "TTL": ttl}})
+	case "/v3/lease/revoke":
+		var req leaseRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		delete(g.leases, req.ID)
+		for key, pair := range g.kvs {
+			if pair.Lease == req.ID {
+				delete(g.kvs, key)
+			}
+		}
+		w.Write([]byte("{}"))
+	case "/v3/kv/put":
+		var req kv
+		json.NewDecoder(r.Body).Decode(&req)
+		if !g.leases[req.Lease] {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		g.kvs[decode(req.Key)] = req
+		w.Write([]byte("{}"))
+	case "/v3/kv/range":
+		var req rangeRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		var resp rangeResponse
+		for key, pair := range g.kvs {
+			if strings.HasPrefix(key, decode(req.Key)) {
+				resp.KVs = append(resp.KVs, pair)
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestStore(t *testing.T) {
+	gateway := newFakeGateway()
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	store := &Store{Endpoint: server.URL, Prefix: "/health/users/"}
+	ctx := context.Background()
+
+	healthy := &health.ServiceCheck{Name: "users", Healthy: true}
+	unhealthy := &health.ServiceCheck{Name: "users", Healthy: false}
+
+	if err := store.Publish(ctx, "users-0", healthy); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if err := store.Publish(ctx, "users-1", unhealthy); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	fleet, err := store.Fleet(ctx)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if len(fleet) != 2 {
+		t.Fatalf("expected 2 instances got %d", len(fleet))
+	}
+	if !fleet["users-0"].Healthy || fleet["users-1"].Healthy {
+		t.Errorf("unexpected fleet health %v %v", fleet["users-0"].Healthy, fleet["users-1"].Healthy)
+	}
+
+	gateway.mu.Lock()
+	leases := len(gateway.leases)
+	gateway.mu.Unlock()
+	if leases != 1 {
+		t.Errorf("expected the lease to be reused got %d leases", leases)
+	}
+
+	if err := store.Revoke(ctx); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	fleet, _ = store.Fleet(ctx)
+	if len(fleet) != 0 {
+		t.Errorf("expected revoking the lease to remove the instances got %d", len(fleet))
+	}
+}
+
+func TestStoreExpiredLease(t *testing.T) {
+	gateway := newFakeGateway()
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	store := &Store{Endpoint: server.URL, TTL: time.Second}
+	ctx := context.Background()
+	check := &health.ServiceCheck{Name: "users", Healthy: true}
+
+	store.Publish(ctx, "users-0", check)
+
+	// expire the lease behind the store's back
+	gateway.mu.Lock()
+	gateway.leases = map[string]bool{}
+	gateway.mu.Unlock()
+
+	if err := store.Publish(ctx, "users-0", check); err != nil {
+		t.Errorf("expected a new lease to be granted got %v", err)
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		expected string
+	}{
+		{"/health/", "/health0"},
+		{"a\xff", "b"},
+		{"\xff", "\x00"},
+	}
+
+	for _, test := range tests {
+		if end := prefixEnd(test.prefix); end != test.expected {
+			t.Errorf("expected %q got %q", test.expected, end)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package health
+
+import "net/http"
+
+// FastHTTPHandler responds like HTTPHandler, but serves the status document
+// encoded once per change of state rather than once per request, so serving it
+// doesn't allocate. It suits probes polling many instances at a high rate. A
+// marshal hook, see WithMarshalHook, only runs when the document is encoded.
+func (s *ServiceCheck) FastHTTPHandler(w http.ResponseWriter, r *http.Request) {
+	s.refresh()
+	view := s.load()
+	setContentType(w)
+	w.WriteHeader(view.status.statusCode())
+	w.Write(view.body())
+}
+
+// body returns the encoded status document of the snapshot, or nil if it
+// can't be encoded
+func (v *snapshot) body() []byte {
+	v.encodedOnce.Do(func() {
+		b, err := v.status.marshal(v.status.document())
+		if err == nil {
+			v.encoded = append(b, '\n')
+		}
+	})
+
+	return v.encoded
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFastHTTPHandler(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+
+	for _, healthy := range []bool{true, false, true} {
+		db.SetHealthy(healthy)
+
+		expected := httptest.NewRecorder()
+		check.HTTPHandler(expected, httptest.NewRequest("GET", "/health", nil))
+		got := httptest.NewRecorder()
+		check.FastHTTPHandler(got, httptest.NewRequest("GET", "/health", nil))
+
+		if got.Code != expected.Code {
+			t.Errorf("expected %d got %d", expected.Code, got.Code)
+		}
+		if got.Body.String() != expected.Body.String() {
+			t.Errorf("expected %s got %s", expected.Body.String(), got.Body.String())
+		}
+	}
+}
+
+// discard is a ResponseWriter which doesn't allocate
+type discard struct {
+	header http.Header
+}
+
+func (d *discard) Header() http.Header         { return d.header }
+func (d *discard) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discard) WriteHeader(int)             {}
+
+func TestFastHTTPHandlerAllocations(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	w := &discard{header: http.Header{}}
+	r := httptest.NewRequest("GET", "/health", nil)
+	allocs := testing.AllocsPerRun(100, func() {
+		check.FastHTTPHandler(w, r)
+	})
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations got %v", allocs)
+	}
+}
+
+func BenchmarkHTTPHandler(b *testing.B) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+
+	w := &discard{header: http.Header{}}
+	r := httptest.NewRequest("GET", "/health", nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		check.HTTPHandler(w, r)
+	}
+}
+
+func BenchmarkFastHTTPHandler(b *testing.B) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+
+	w := &discard{header: http.Header{}}
+	r := httptest.NewRequest("GET", "/health", nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		check.FastHTTPHandler(w, r)
+	}
+}
@@ -0,0 +1,65 @@
+// Package fasthttphealth serves the health endpoint natively on fasthttp, for
+// high throughput gateways that can't afford net/http on every probe.
+package fasthttphealth
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/fresh8/health"
+)
+
+// Handler returns a fasthttp.RequestHandler outputting the status of `check`
+// with the relevant response code. The status document is encoded once and
+// the bytes are reused until the health of the service or one of its
+// dependencies changes, see ServiceCheck.Changed.
+func Handler(check *health.ServiceCheck) fasthttp.RequestHandler {
+	s := &snapshot{check: check}
+
+	return func(ctx *fasthttp.RequestCtx) {
+		body, healthy := s.get()
+		if healthy {
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		} else {
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		}
+
+		ctx.SetContentType("application/json")
+		ctx.SetBody(body)
+	}
+}
+
+// snapshot caches the encoded status of a ServiceCheck
+type snapshot struct {
+	check *health.ServiceCheck
+
+	body    []byte
+	healthy bool
+	changed <-chan struct{}
+	mu      sync.Mutex
+}
+
+// get returns the cached status, encoding it afresh if it has changed since
+func (s *snapshot) get() ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.changed != nil {
+		select {
+		case <-s.changed:
+		default:
+			return s.body, s.healthy
+		}
+	}
+
+	// fetch the channel first so a change whilst encoding isn't missed
+	s.changed = s.check.Changed()
+
+	var buf bytes.Buffer
+	s.check.WriteStatus(&buf)
+	s.body = buf.Bytes()
+	s.healthy = s.check.IsHealthy()
+	return s.body, s.healthy
+}
@@ -0,0 +1,64 @@
+package fasthttphealth
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/fresh8/health"
+)
+
+func serve(h fasthttp.RequestHandler) *fasthttp.RequestCtx {
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/health")
+	h(&ctx)
+	return &ctx
+}
+
+func TestHandler(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return healthy.Load() })
+	h := Handler(check)
+
+	ctx := serve(h)
+	if ctx.Response.StatusCode() != 200 {
+		t.Errorf("expected 200 got %v", ctx.Response.StatusCode())
+	}
+	if !bytes.Contains(ctx.Response.Body(), []byte(`"healthy":true`)) {
+		t.Errorf("expected healthy status got %s", ctx.Response.Body())
+	}
+
+	healthy.Store(false)
+	check.WaitForDependencies(50 * time.Millisecond)
+
+	ctx = serve(h)
+	if ctx.Response.StatusCode() != 503 {
+		t.Errorf("expected 503 got %v", ctx.Response.StatusCode())
+	}
+	if !bytes.Contains(ctx.Response.Body(), []byte(`"healthy":false`)) {
+		t.Errorf("expected unhealthy status got %s", ctx.Response.Body())
+	}
+}
+
+func TestHandlerReusesSnapshot(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	s := &snapshot{check: check}
+
+	first, _ := s.get()
+	second, _ := s.get()
+	if &first[0] != &second[0] {
+		t.Error("expected the encoded status to be reused whilst unchanged")
+	}
+
+	check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+	third, _ := s.get()
+	if !bytes.Contains(third, []byte(`"db"`)) {
+		t.Errorf("expected the status to be encoded afresh after a change got %s", third)
+	}
+}
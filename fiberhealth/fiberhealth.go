@@ -0,0 +1,30 @@
+// Package fiberhealth adapts the health handler and middleware to Fiber. The
+// fasthttp request is converted to a full *http.Request, so query parameters
+// and headers reach the native handler unchanged.
+package fiberhealth
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	"github.com/fresh8/health"
+)
+
+// Handler returns a fiber.Handler outputting the status of `check` with the
+// relevant response code
+func Handler(check *health.ServiceCheck) fiber.Handler {
+	return adaptor.HTTPHandlerFunc(check.HTTPHandler)
+}
+
+// Shed returns Fiber middleware which responds 503 Service Unavailable whilst
+// `check` is unhealthy, as per ServiceCheck.ShedMiddleware
+func Shed(check *health.ServiceCheck, retryAfter time.Duration, exempt ...string) fiber.Handler {
+	return adaptor.HTTPMiddleware(check.ShedMiddleware(retryAfter, exempt...))
+}
+
+// Register mounts Handler on `r` at `path`
+func Register(r fiber.Router, path string, check *health.ServiceCheck) {
+	r.Get(path, Handler(check))
+}
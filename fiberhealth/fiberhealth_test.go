@@ -0,0 +1,47 @@
+package fiberhealth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/fresh8/health"
+)
+
+func TestRegister(t *testing.T) {
+	check := &health.ServiceCheck{Name: "api"}
+
+	app := fiber.New()
+	app.Use(Shed(check, time.Second))
+	Register(app, "/health", check)
+	app.Get("/orders", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	tests := []struct {
+		path       string
+		healthy    bool
+		expected   int
+		retryAfter string
+	}{
+		{"/health", true, 200, ""},
+		{"/health", false, 503, ""},
+		{"/orders", true, 200, ""},
+		{"/orders", false, 503, "1"},
+	}
+
+	for _, test := range tests {
+		check.Healthy = test.healthy
+
+		resp, err := app.Test(httptest.NewRequest("GET", test.path, nil))
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if resp.StatusCode != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, resp.StatusCode, test.path)
+		}
+		if got := resp.Header.Get("Retry-After"); got != test.retryAfter {
+			t.Errorf("expected %q got %q for %s", test.retryAfter, got, test.path)
+		}
+	}
+}
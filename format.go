@@ -0,0 +1,186 @@
+package health
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is an encoding of the status, see WriteStatusFormat
+type Format uint8
+
+const (
+	// FormatJSON is the status document, the default
+	FormatJSON Format = iota
+	// FormatText is a plain text summary for terminals and scripts
+	FormatText
+	// FormatHTML is a simple status page for browsers
+	FormatHTML
+)
+
+// contentTypes are the Content-Types of each Format
+var contentTypes = [...][]string{
+	FormatJSON: jsonContentType,
+	FormatText: {"text/plain; charset=utf-8"},
+	FormatHTML: {"text/html; charset=utf-8"},
+}
+
+// WriteStatusFormat writes the status to any io.Writer in `format`
+func (s *ServiceCheck) WriteStatusFormat(w io.Writer, format Format) error {
+	return s.load().status.writeStatusFormat(w, format)
+}
+
+// writeStatusFormat writes the status of the snapshot `s` in `format`
+func (s *ServiceCheck) writeStatusFormat(w io.Writer, format Format) error {
+	switch format {
+	case FormatText:
+		return s.writeText(w)
+	case FormatHTML:
+		return s.writeHTML(w)
+	default:
+		return s.writeStatus(w)
+	}
+}
+
+// negotiate returns the Format requested by `r`, by its format query
+// parameter, "json", "text" or "html", or otherwise its Accept header
+func negotiate(r *http.Request) Format {
+	if r.URL.RawQuery != "" {
+		switch r.URL.Query().Get("format") {
+		case "json":
+			return FormatJSON
+		case "text":
+			return FormatText
+		case "html":
+			return FormatHTML
+		}
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return FormatJSON
+	}
+
+	// the first of the most preferred media types wins
+	var (
+		best    = FormatJSON
+		bestQ   = -1.0
+		matched bool
+	)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		var format Format
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			format = FormatJSON
+		case "text/plain":
+			format = FormatText
+		case "text/html", "text/*":
+			format = FormatHTML
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ, matched = format, q, true
+		}
+	}
+	if !matched || bestQ <= 0 {
+		return FormatJSON
+	}
+
+	return best
+}
+
+// redacted returns `v` redacted as per WithRedactor
+func (s *ServiceCheck) redacted(v string) string {
+	if s.redact == nil || v == "" {
+		return v
+	}
+	return s.redact(v)
+}
+
+// writeText writes a plain text summary of the snapshot `s`:
+//
+//	api: degraded: 1 soft dependency failing (cache)
+//	db     hard  healthy
+//	cache  soft  unhealthy  dial tcp: connection refused
+func (s *ServiceCheck) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s: %s\n", s.Name, s.message())
+	for _, dependency := range s.Dependencies {
+		state := "healthy"
+		if !dependency.Healthy {
+			state = "unhealthy"
+		}
+		line := dependency.Name + "\t" + dependency.Level.String() + "\t" + state
+		if dependency.LastError != "" {
+			line += "\t" + s.redacted(dependency.LastError)
+		}
+		fmt.Fprintln(tw, line)
+	}
+
+	return tw.Flush()
+}
+
+// statusPage is the status page written by writeHTML
+var statusPage = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Name}} health</title></head>
+<body>
+<h1>{{.Name}}</h1>
+<p>{{.Message}}</p>
+<table>
+<tr><th>Dependency</th><th>Level</th><th>Health</th><th>Error</th></tr>
+{{- range .Dependencies}}
+<tr><td>{{.Name}}</td><td>{{.Level}}</td><td>{{if .Healthy}}healthy{{else}}unhealthy{{end}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// statusPageDependency is a row of the status page
+type statusPageDependency struct {
+	Name    string
+	Level   Level
+	Healthy bool
+	Error   string
+}
+
+// writeHTML writes a simple status page of the snapshot `s`
+func (s *ServiceCheck) writeHTML(w io.Writer) error {
+	page := struct {
+		Name         string
+		Message      string
+		Dependencies []statusPageDependency
+	}{
+		Name:         s.Name,
+		Message:      s.message(),
+		Dependencies: make([]statusPageDependency, len(s.Dependencies)),
+	}
+	for i, dependency := range s.Dependencies {
+		page.Dependencies[i] = statusPageDependency{
+			Name:    dependency.Name,
+			Level:   dependency.Level,
+			Healthy: dependency.Healthy,
+			Error:   s.redacted(dependency.LastError),
+		}
+	}
+
+	return statusPage.Execute(w, page)
+}
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		target   string
+		accept   string
+		expected Format
+	}{
+		{"/health", "", FormatJSON},
+		{"/health", "*/*", FormatJSON},
+		{"/health", "application/json", FormatJSON},
+		{"/health", "text/plain", FormatText},
+		{"/health", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", FormatHTML},
+		{"/health", "text/plain;q=0.5, application/json", FormatJSON},
+		{"/health", "text/html;q=0", FormatJSON},
+		{"/health", "image/png", FormatJSON},
+		{"/health?format=text", "text/html", FormatText},
+		{"/health?format=html", "", FormatHTML},
+		{"/health?format=json", "text/plain", FormatJSON},
+		{"/health?format=yaml", "text/plain", FormatText},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", test.target, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		if format := negotiate(r); format != test.expected {
+			t.Errorf("expected %v got %v for %s %q", test.expected, format, test.target, test.accept)
+		}
+	}
+}
+
+func TestHTTPHandlerFormats(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("<cache>", LevelSoft, func() bool { return false })
+	check.Update()
+
+	tests := []struct {
+		format              string
+		expectedContentType string
+		expected            []string
+	}{
+		{"json", "application/json", []string{`"name":"api"`, `"status":"degraded"`}},
+		{"text", "text/plain; charset=utf-8", []string{
+			"api: degraded: 1 soft dependency failing (<cache>)\n",
+			"db       hard  healthy\n",
+			"<cache>  soft  unhealthy\n",
+		}},
+		{"html", "text/html; charset=utf-8", []string{
+			"<title>api health</title>",
+			"<td>&lt;cache&gt;</td><td>soft</td><td>unhealthy</td>",
+		}},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		check.HTTPHandler(w, httptest.NewRequest("GET", "/?format="+test.format, nil))
+
+		if w.Code != 200 {
+			t.Errorf("expected 200 got %d for %s", w.Code, test.format)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != test.expectedContentType {
+			t.Errorf("expected %s got %s", test.expectedContentType, contentType)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(w.Body.String(), expected) {
+				t.Errorf("expected %q in %s", expected, w.Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RemoteStatus is the status document of another service using this
+// package, as fetched by GetStatus
+type RemoteStatus struct {
+	StatusDocument
+	// StatusCode is the status code of the response, which is 503 for a
+	// service which is healthy but draining, for example
+	StatusCode int
+}
+
+// Dependency returns the entry for the named dependency, if the remote
+// service has one
+func (r *RemoteStatus) Dependency(name string) (DependencyDocument, bool) {
+	for _, dependency := range r.Dependencies {
+		if dependency.Name == name {
+			return dependency, true
+		}
+	}
+
+	return DependencyDocument{}, false
+}
+
+// StatusOption configures GetStatus
+type StatusOption func(*statusRequest)
+
+// statusRequest is the configuration of a GetStatus call
+type statusRequest struct {
+	client   *http.Client
+	retries  int
+	backoff  time.Duration
+	required []string
+}
+
+// WithStatusClient fetches the status with `client` rather than the default
+func WithStatusClient(client *http.Client) StatusOption {
+	return func(r *statusRequest) {
+		r.client = client
+	}
+}
+
+// WithStatusRetries retries a failed fetch up to `retries` times, waiting
+// `backoff`, DefaultWaitBackoff if zero, doubling with jitter after each.
+// A remote service reporting itself as unhealthy is not retried.
+func WithStatusRetries(retries int, backoff time.Duration) StatusOption {
+	return func(r *statusRequest) {
+		r.retries, r.backoff = retries, backoff
+	}
+}
+
+// WithRequiredDependencies makes GetStatus return an error unless each of
+// the named dependencies of the remote service is healthy
+func WithRequiredDependencies(names ...string) StatusOption {
+	return func(r *statusRequest) {
+		r.required = append(r.required, names...)
+	}
+}
+
+// GetStatus fetches and decodes the status document of another service
+// using this package at `url`, unlike Get keeping its dependencies. A service
+// responding 503 with its document isn't an error, its status is returned as
+// it reports it. With WithRequiredDependencies the status is returned along with
+// the errors.Join of a DependencyError, wrapping ErrUnhealthy or
+// ErrNoDependency, for each required dependency which isn't healthy.
+func GetStatus(ctx context.Context, url string, opts ...StatusOption) (*RemoteStatus, error) {
+	req := statusRequest{client: defaultHTTPClient, backoff: DefaultWaitBackoff}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	if req.backoff <= 0 {
+		req.backoff = DefaultWaitBackoff
+	}
+
+	status, err := fetchStatus(ctx, req.client, url)
+	for attempt, backoff := 1, req.backoff; err != nil && attempt <= req.retries; attempt, backoff = attempt+1, 2*backoff {
+		wait := time.NewTimer(jitter(backoff))
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return nil, ctx.Err()
+		case <-wait.C:
+		}
+		status, err = fetchStatus(ctx, req.client, url)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, name := range req.required {
+		dependency, ok := status.Dependency(name)
+		switch {
+		case !ok:
+			errs = append(errs, &DependencyError{Service: status.Name, Dependency: name, Err: ErrNoDependency})
+		case !dependency.Healthy:
+			errs = append(errs, &DependencyError{Service: status.Name, Dependency: name, Err: ErrUnhealthy})
+		}
+	}
+
+	return status, errors.Join(errs...)
+}
+
+// fetchStatus fetches the status document at `url` once
+func fetchStatus(ctx context.Context, client *http.Client, url string) (*RemoteStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	status := &RemoteStatus{StatusCode: resp.StatusCode}
+	if err := decodeResponse(resp, &status.StatusDocument); err != nil {
+		if resp.StatusCode != http.StatusOK && !errors.Is(err, ErrResponseTooLarge) {
+			return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetStatus(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+	check.Update()
+
+	server := httptest.NewServer(http.HandlerFunc(check.HTTPHandler))
+	defer server.Close()
+
+	status, err := GetStatus(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if status.Name != "api" || !status.Healthy || status.Status != StatusDegraded || status.StatusCode != 200 {
+		t.Errorf("expected a degraded api got %+v", status)
+	}
+	if db, ok := status.Dependency("db"); !ok || !db.Healthy || db.Level != LevelHard {
+		t.Errorf("expected a healthy hard db got %+v", db)
+	}
+
+	tests := []struct {
+		required []string
+		expected []error
+	}{
+		{[]string{"db"}, nil},
+		{[]string{"db", "cache"}, []error{ErrUnhealthy}},
+		{[]string{"queue", "cache"}, []error{ErrNoDependency, ErrUnhealthy}},
+	}
+
+	for _, test := range tests {
+		status, err := GetStatus(context.Background(), server.URL, WithRequiredDependencies(test.required...))
+		if status == nil {
+			t.Fatalf("expected the status got nil")
+		}
+		for _, expected := range test.expected {
+			if !errors.Is(err, expected) {
+				t.Errorf("expected %v got %v", expected, err)
+			}
+		}
+		if test.expected == nil && err != nil {
+			t.Errorf("expected nil got %v", err)
+		}
+	}
+}
+
+func TestGetStatusRetries(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		check.HTTPHandler(w, r)
+	}))
+	defer server.Close()
+
+	if _, err := GetStatus(context.Background(), server.URL, WithStatusRetries(1, time.Millisecond)); err == nil {
+		t.Errorf("expected an error after 1 retry")
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	status, err := GetStatus(context.Background(), server.URL, WithStatusRetries(2, time.Millisecond))
+	if err != nil || !status.Healthy {
+		t.Errorf("expected a healthy status after 2 retries got %v", err)
+	}
+
+	// ensure the retries stop with the context
+	atomic.StoreInt32(&requests, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := GetStatus(ctx, server.URL, WithStatusRetries(5, time.Minute)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,44 @@
+// Package ginhealth adapts the health handlers and middleware to Gin, so
+// services using Gin don't each write the same wrappers around gin.Context.
+package ginhealth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fresh8/health"
+)
+
+// Handler returns a gin.HandlerFunc outputting the status of `check` with the
+// relevant response code
+func Handler(check *health.ServiceCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		check.HTTPHandler(c.Writer, c.Request)
+	}
+}
+
+// Shed returns Gin middleware which aborts requests with 503 Service
+// Unavailable whilst `check` is unhealthy, as per ServiceCheck.ShedMiddleware
+func Shed(check *health.ServiceCheck, retryAfter time.Duration, exempt ...string) gin.HandlerFunc {
+	shed := check.ShedMiddleware(retryAfter, exempt...)
+
+	return func(c *gin.Context) {
+		var passed bool
+		shed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			passed = true
+		})).ServeHTTP(c.Writer, c.Request)
+
+		if !passed {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Register mounts Handler on `r` at `path`
+func Register(r gin.IRoutes, path string, check *health.ServiceCheck) {
+	r.GET(path, Handler(check))
+}
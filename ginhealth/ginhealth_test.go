@@ -0,0 +1,70 @@
+package ginhealth
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/fresh8/health"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		healthy  bool
+		expected int
+	}{
+		{true, 200},
+		{false, 503},
+	}
+
+	for _, test := range tests {
+		r := gin.New()
+		Register(r, "/health", &health.ServiceCheck{Name: "api", Healthy: test.healthy})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v", test.expected, w.Code)
+		}
+	}
+}
+
+func TestShed(t *testing.T) {
+	check := &health.ServiceCheck{Name: "api"}
+
+	r := gin.New()
+	r.Use(Shed(check, time.Second))
+	Register(r, "/health", check)
+	r.GET("/orders", func(c *gin.Context) { c.String(200, "ok") })
+
+	tests := []struct {
+		path     string
+		healthy  bool
+		expected int
+	}{
+		{"/orders", true, 200},
+		{"/orders", false, 503},
+		// the health endpoint is exempt, so the status document is served
+		{"/health", false, 503},
+	}
+
+	for _, test := range tests {
+		check.Healthy = test.healthy
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, w.Code, test.path)
+		}
+		if test.path == "/health" && !strings.Contains(w.Body.String(), `"healthy":false`) {
+			t.Errorf("expected status document got %q", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package health
+
+import "time"
+
+// WithStartupGrace keeps the service healthy for `grace` after it is
+// initialised, however its dependencies fare, so slow starting dependencies
+// don't fail it straight away. The dependencies themselves are still reported
+// as they are, and WaitForDependencies still waits for them. The service's
+// health reflects them again from the first check after the grace period.
+func WithStartupGrace(grace time.Duration) Option {
+	return func(s *ServiceCheck) {
+		s.startupGrace = grace
+	}
+}
+
+// WithInitialHealth sets the health of the service before its dependencies
+// are first checked, by StartCheck, WaitForDependencies or Update. With false
+// it is unhealthy until then, rather than healthy, so it receives no traffic
+// before anything is known of its dependencies.
+func WithInitialHealth(healthy bool) Option {
+	return func(s *ServiceCheck) {
+		s.Healthy, s.startUnhealthy = healthy, !healthy
+	}
+}
+
+// WithSkipInitialCheck registers the dependency without checking it, so
+// every dependency can be registered before traffic arrives. It is reported
+// healthy until its first check, by StartCheck, WaitForDependencies or
+// Update.
+func WithSkipInitialCheck() DependencyOption {
+	return func(d *Dependency) {
+		d.skipInitial = true
+	}
+}
+
+// inGrace returns whether the service is within its startup grace period,
+// see WithStartupGrace
+func (s *ServiceCheck) inGrace() bool {
+	return !s.graceUntil.IsZero() && s.getClock().Now().Before(s.graceUntil)
+}
+
+// initialCheck checks the dependency on registration, unless registered with
+// WithSkipInitialCheck
+func (d *Dependency) initialCheck() {
+	if d.skipInitial {
+		d.Healthy = true
+		return
+	}
+
+	d.update()
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithStartupGrace(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock), WithStartupGrace(time.Minute))
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return false })
+
+	check.Update()
+	if !check.IsHealthy() {
+		t.Error("expected the service to be healthy within the grace period")
+	}
+	if db.IsHealthy() {
+		t.Error("expected the dependency to be reported as it is")
+	}
+
+	clock.advance(time.Minute, 0)
+	check.Update()
+	if check.IsHealthy() {
+		t.Error("expected the service to be unhealthy after the grace period")
+	}
+}
+
+func TestWithSkipInitialCheck(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Second)
+
+	var runs int
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		runs++
+		return false
+	}, WithSkipInitialCheck())
+
+	if runs != 0 || !db.IsHealthy() {
+		t.Errorf("expected an unchecked healthy dependency got %d runs", runs)
+	}
+
+	check.Update()
+	if runs != 1 || db.IsHealthy() || check.IsHealthy() {
+		t.Errorf("expected the first check to fail the service got %d runs", runs)
+	}
+}
+
+func TestWithInitialHealth(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Second, WithInitialHealth(false))
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	if check.IsHealthy() || check.Clone().IsHealthy() {
+		t.Error("expected the service to be unhealthy before the first check")
+	}
+
+	check.Update()
+	if !check.IsHealthy() {
+		t.Error("expected the service to be healthy after the first check")
+	}
+}
@@ -0,0 +1,107 @@
+// Package graphqlhealth exposes the status of a ServiceCheck through an
+// existing GraphQL server. The schema is in schema.graphql and the types in
+// this package bind to it directly, so with gqlgen add the schema to the
+// schema sources, map the types in gqlgen.yml:
+//
+//	models:
+//	  HealthStatus:
+//	    model: github.com/fresh8/health/graphqlhealth.Status
+//	  Dependency:
+//	    model: github.com/fresh8/health/graphqlhealth.Dependency
+//	  DependencyLevel:
+//	    model: github.com/fresh8/health/graphqlhealth.DependencyLevel
+//
+// and delegate the generated query resolver to Resolver.Health.
+package graphqlhealth
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/fresh8/health"
+)
+
+// Schema is the contents of schema.graphql, for servers which take the schema
+// as a string
+//
+//go:embed schema.graphql
+var Schema string
+
+// DependencyLevel is the GraphQL enum of health.Level
+type DependencyLevel string
+
+// DependencyLevel values
+const (
+	DependencyLevelSoft          DependencyLevel = "SOFT"
+	DependencyLevelHard          DependencyLevel = "HARD"
+	DependencyLevelInformational DependencyLevel = "INFORMATIONAL"
+)
+
+// MarshalGQL writes the enum value
+func (l DependencyLevel) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(string(l)))
+}
+
+// UnmarshalGQL reads an enum value
+func (l *DependencyLevel) UnmarshalGQL(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("graphqlhealth: DependencyLevel must be a string")
+	}
+
+	switch DependencyLevel(s) {
+	case DependencyLevelSoft, DependencyLevelHard, DependencyLevelInformational:
+		*l = DependencyLevel(s)
+		return nil
+	default:
+		return fmt.Errorf("graphqlhealth: %q is not a valid DependencyLevel", s)
+	}
+}
+
+// Status is the HealthStatus type
+type Status struct {
+	Name         string        `json:"name"`
+	Healthy      bool          `json:"healthy"`
+	Dependencies []*Dependency `json:"dependencies"`
+}
+
+// Dependency is the Dependency type
+type Dependency struct {
+	Name    string          `json:"name"`
+	Healthy bool            `json:"healthy"`
+	Level   DependencyLevel `json:"level"`
+}
+
+// Resolver resolves the health query for a ServiceCheck
+type Resolver struct {
+	Check *health.ServiceCheck
+}
+
+// Health resolves the health query
+func (r *Resolver) Health(ctx context.Context) (*Status, error) {
+	dependencies := r.Check.DependencyStates()
+	status := &Status{
+		Name:         r.Check.Name,
+		Healthy:      r.Check.IsHealthy(),
+		Dependencies: make([]*Dependency, len(dependencies)),
+	}
+
+	for i, dependency := range dependencies {
+		status.Dependencies[i] = &Dependency{
+			Name:    dependency.Name,
+			Healthy: dependency.Healthy,
+			Level:   DependencyLevelSoft,
+		}
+		switch dependency.Level {
+		case health.LevelHard:
+			status.Dependencies[i].Level = DependencyLevelHard
+		case health.LevelInformational:
+			status.Dependencies[i].Level = DependencyLevelInformational
+		}
+	}
+
+	return status, nil
+}
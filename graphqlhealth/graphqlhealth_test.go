@@ -0,0 +1,69 @@
+package graphqlhealth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestHealth(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("bff", time.Second)
+	check.RegisterDependency("users", health.LevelHard, func() bool { return true })
+	check.RegisterDependency("recommendations", health.LevelSoft, func() bool { return false })
+	check.RegisterDependency("audit", health.LevelInformational, func() bool { return false })
+
+	status, err := (&Resolver{Check: check}).Health(context.Background())
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	if status.Name != "bff" || !status.Healthy {
+		t.Errorf("expected healthy bff got %s %v", status.Name, status.Healthy)
+	}
+	if len(status.Dependencies) != 3 {
+		t.Fatalf("expected 3 dependencies got %d", len(status.Dependencies))
+	}
+	// ensure an informational dependency isn't reported as soft
+	levels := []DependencyLevel{DependencyLevelHard, DependencyLevelSoft, DependencyLevelInformational}
+	for i, level := range levels {
+		if status.Dependencies[i].Level != level {
+			t.Errorf("expected %v got %v for %s", level, status.Dependencies[i].Level, status.Dependencies[i].Name)
+		}
+	}
+}
+
+func TestDependencyLevel(t *testing.T) {
+	var buf bytes.Buffer
+	DependencyLevelHard.MarshalGQL(&buf)
+	if buf.String() != `"HARD"` {
+		t.Errorf("expected %q got %q", `"HARD"`, buf.String())
+	}
+
+	tests := []struct {
+		value interface{}
+		err   bool
+	}{
+		{"SOFT", false},
+		{"HARD", false},
+		{"INFORMATIONAL", false},
+		{"MEDIUM", true},
+		{1, true},
+	}
+
+	for _, test := range tests {
+		var l DependencyLevel
+		if err := l.UnmarshalGQL(test.value); (err != nil) != test.err {
+			t.Errorf("expected error %v got %v for %v", test.err, err, test.value)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	if !strings.Contains(Schema, "health: HealthStatus!") {
+		t.Error("expected the embedded schema to define the health query")
+	}
+}
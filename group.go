@@ -0,0 +1,42 @@
+package health
+
+import "sync"
+
+// ResultGroup runs one check on behalf of several dependencies, so for
+// example a single probe of a cache can back both a soft "cache-read" and a
+// hard "cache-write" dependency. Use NewResultGroup to instantiate one and
+// register each dependency with a check from Check:
+//
+//	cache := health.NewResultGroup(pingCache)
+//	check.RegisterDependency("cache-read", health.LevelSoft, cache.Check())
+//	check.RegisterDependency("cache-write", health.LevelHard, cache.Check())
+type ResultGroup struct {
+	check func() bool
+
+	mu      sync.Mutex
+	runs    uint64
+	healthy bool
+}
+
+// NewResultGroup returns a ResultGroup sharing the result of `check`
+func NewResultGroup(check func() bool) *ResultGroup {
+	return &ResultGroup{check: check}
+}
+
+// Check returns a check for one dependency of the group. It runs the
+// underlying check only if this dependency has already seen the last result,
+// so the check runs once however many dependencies share it.
+func (g *ResultGroup) Check() func() bool {
+	var seen uint64
+	return func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if seen == g.runs {
+			g.healthy = g.check()
+			g.runs++
+		}
+		seen = g.runs
+		return g.healthy
+	}
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResultGroup(t *testing.T) {
+	var runs int
+	healthy := true
+	group := NewResultGroup(func() bool {
+		runs++
+		return healthy
+	})
+
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("cache-read", LevelSoft, group.Check())
+	check.RegisterDependency("cache-write", LevelHard, group.Check())
+	if runs != 1 {
+		t.Errorf("expected 1 run got %d", runs)
+	}
+
+	healthy = false
+	check.Update()
+	check.Update()
+	if runs != 3 {
+		t.Errorf("expected 3 runs got %d", runs)
+	}
+
+	for _, state := range check.DependencyStates() {
+		if state.Healthy {
+			t.Errorf("expected %s to share the result", state.Name)
+		}
+	}
+	if check.IsHealthy() {
+		t.Error("expected the hard dependency to fail the service")
+	}
+}
+
+func TestResultGroupUneven(t *testing.T) {
+	var runs int
+	group := NewResultGroup(func() bool {
+		runs++
+		return true
+	})
+	read, write := group.Check(), group.Check()
+
+	// a dependency checked more often than the others runs the check itself
+	read()
+	read()
+	write()
+	if runs != 2 {
+		t.Errorf("expected 2 runs got %d", runs)
+	}
+}
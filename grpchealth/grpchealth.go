@@ -0,0 +1,126 @@
+// Package grpchealth implements the gRPC Health Checking Protocol,
+// grpc.health.v1.Health, on top of a ServiceCheck so gRPC services get
+// standard health without running an extra HTTP port.
+//
+// The empty service name and the name of the ServiceCheck report the overall
+// health of the service, and each dependency is reported under its own name.
+package grpchealth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/fresh8/health"
+)
+
+// Server is a grpc.health.v1.Health server backed by a ServiceCheck. Use
+// NewServer to instantiate one
+type Server struct {
+	healthpb.UnimplementedHealthServer
+
+	check *health.ServiceCheck
+}
+
+// NewServer returns a Server reporting the health of `check`
+func NewServer(check *health.ServiceCheck) *Server {
+	return &Server{check: check}
+}
+
+// Register creates a Server for `check` and registers it on `gs`
+func Register(gs *grpc.Server, check *health.ServiceCheck) *Server {
+	s := NewServer(check)
+	healthpb.RegisterHealthServer(gs, s)
+	return s
+}
+
+// Check returns the serving status of the requested service, or NotFound if
+// it is neither the service itself nor one of its dependencies
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus, ok := s.servingStatus(req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// List returns the serving status of the service and every dependency
+func (s *Server) List(ctx context.Context, req *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	statuses := s.statuses()
+	resp := &healthpb.HealthListResponse{
+		Statuses: make(map[string]*healthpb.HealthCheckResponse, len(statuses)),
+	}
+	for service, servingStatus := range statuses {
+		resp.Statuses[service] = &healthpb.HealthCheckResponse{Status: servingStatus}
+	}
+
+	return resp, nil
+}
+
+// Watch streams the serving status of the requested service, sending the
+// current status straight away and again every time it changes. Unknown
+// services are reported as SERVICE_UNKNOWN, as they may be registered later.
+func (s *Server) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	var (
+		last healthpb.HealthCheckResponse_ServingStatus
+		sent bool
+	)
+	for {
+		// fetch the channel before reading the status so no change is missed
+		changed := s.check.Changed()
+
+		servingStatus, ok := s.servingStatus(req.GetService())
+		if !ok {
+			servingStatus = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+
+		if !sent || servingStatus != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+			sent = true
+			last = servingStatus
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		case <-changed:
+		}
+	}
+}
+
+// servingStatus returns the serving status of `service`, and whether it is
+// known
+func (s *Server) servingStatus(service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	servingStatus, ok := s.statuses()[service]
+	return servingStatus, ok
+}
+
+// statuses returns the serving status of the service, under both the empty
+// name and its own, and of each dependency. A service starting up or draining
+// is not serving.
+func (s *Server) statuses() map[string]healthpb.HealthCheckResponse_ServingStatus {
+	dependencies := s.check.DependencyStates()
+	statuses := make(map[string]healthpb.HealthCheckResponse_ServingStatus, len(dependencies)+2)
+
+	for _, dependency := range dependencies {
+		statuses[dependency.Name] = toServingStatus(dependency.Healthy)
+	}
+
+	overall := toServingStatus(s.check.IsServing())
+	statuses[""] = overall
+	statuses[s.check.Name] = overall
+	return statuses
+}
+
+func toServingStatus(healthy bool) healthpb.HealthCheckResponse_ServingStatus {
+	if healthy {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
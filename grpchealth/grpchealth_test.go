@@ -0,0 +1,136 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/fresh8/health"
+	"github.com/fresh8/health/healthtest"
+)
+
+func TestServerCheck(t *testing.T) {
+	s := NewServer(healthtest.NewServiceCheck("api", healthtest.Hard("db", false), healthtest.Soft("cache", true)))
+
+	tests := []struct {
+		service      string
+		expected     healthpb.HealthCheckResponse_ServingStatus
+		expectedCode codes.Code
+	}{
+		{"", healthpb.HealthCheckResponse_NOT_SERVING, codes.OK},
+		{"api", healthpb.HealthCheckResponse_NOT_SERVING, codes.OK},
+		{"db", healthpb.HealthCheckResponse_NOT_SERVING, codes.OK},
+		{"cache", healthpb.HealthCheckResponse_SERVING, codes.OK},
+		{"missing", healthpb.HealthCheckResponse_UNKNOWN, codes.NotFound},
+	}
+
+	for _, test := range tests {
+		resp, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: test.service})
+		if code := status.Code(err); code != test.expectedCode {
+			t.Errorf("expected %v got %v for %q", test.expectedCode, code, test.service)
+		}
+		if resp.GetStatus() != test.expected {
+			t.Errorf("expected %v got %v for %q", test.expected, resp.GetStatus(), test.service)
+		}
+	}
+}
+
+func TestServerCheckSoftFailure(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true), healthtest.Soft("cache", false))
+
+	// ensure only hard dependency failures take the service out of serving
+	resp, err := NewServer(check).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected %v got %v", healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	}
+}
+
+func TestServerList(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", false), healthtest.Soft("cache", true))
+	resp, err := NewServer(check).List(context.Background(), &healthpb.HealthListRequest{})
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	if len(resp.Statuses) != 4 {
+		t.Errorf("expected 4 statuses got %d", len(resp.Statuses))
+	}
+	if resp.Statuses["cache"].GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected cache to be serving got %v", resp.Statuses["cache"].GetStatus())
+	}
+}
+
+func TestServerWatch(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+
+	check, _ := health.InitialiseServiceCheck("api", 10*time.Millisecond)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return healthy.Load() })
+
+	lis := bufconn.Listen(1 << 16)
+	gs := grpc.NewServer()
+	Register(gs, check)
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{Service: "db"})
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("expected %v got %v", healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	}
+
+	healthy.Store(false)
+	check.StartCheck()
+
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected %v got %v", healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+	}
+}
+
+func TestServerCheckDraining(t *testing.T) {
+	check := &health.ServiceCheck{Name: "api", Healthy: true}
+	check.Drain()
+
+	resp, err := NewServer(check).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected %v got %v", healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+	}
+}
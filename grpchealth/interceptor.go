@@ -0,0 +1,69 @@
+package grpchealth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/fresh8/health"
+)
+
+// healthServicePrefix matches every method of the health service, which is
+// always exempt so probes keep working whilst traffic is being shed
+var healthServicePrefix = "/" + healthpb.Health_ServiceDesc.ServiceName + "/"
+
+// UnaryServerInterceptor returns an interceptor which rejects calls with
+// UNAVAILABLE whilst `check` isn't ready, see ServiceCheck.IsReady. `exempt`
+// lists full method names, such as "/pkg.Service/Method", or whole services,
+// such as "/pkg.Service/", which are served regardless. The health service
+// itself is always exempt.
+func UnaryServerInterceptor(check *health.ServiceCheck, exempt ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := gate(check, info.FullMethod, exempt); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming equivalent of
+// UnaryServerInterceptor
+func StreamServerInterceptor(check *health.ServiceCheck, exempt ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := gate(check, info.FullMethod, exempt); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// gate returns an UNAVAILABLE error if `method` should be rejected
+func gate(check *health.ServiceCheck, method string, exempt []string) error {
+	if check.IsReady() || isExempt(method, exempt) {
+		return nil
+	}
+
+	return status.Errorf(codes.Unavailable, "service %s isn't ready", check.Name)
+}
+
+// isExempt reports whether `method` matches the health service or one of the
+// exemptions
+func isExempt(method string, exempt []string) bool {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return true
+	}
+
+	for _, e := range exempt {
+		if method == e || (strings.HasSuffix(e, "/") && strings.HasPrefix(method, e)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package grpchealth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/fresh8/health/healthtest"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", false), healthtest.Soft("cache", true))
+	db, _ := check.Dependency("db")
+	interceptor := UnaryServerInterceptor(check, "/app.Admin/", "/app.Users/Ping")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		method   string
+		healthy  bool
+		expected codes.Code
+	}{
+		{"/app.Users/Get", true, codes.OK},
+		{"/app.Users/Get", false, codes.Unavailable},
+		{"/app.Users/Ping", false, codes.OK},
+		{"/app.Admin/Drain", false, codes.OK},
+		{"/app.AdminTools/Drain", false, codes.Unavailable},
+		{"/grpc.health.v1.Health/Check", false, codes.OK},
+	}
+
+	for _, test := range tests {
+		db.SetHealthy(test.healthy)
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: test.method}, handler)
+		if code := status.Code(err); code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, code, test.method)
+		}
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", false), healthtest.Soft("cache", true))
+	interceptor := StreamServerInterceptor(check)
+
+	var called bool
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		called = true
+		return nil
+	}
+
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/app.Users/List"}, handler)
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Errorf("expected %v got %v", codes.Unavailable, code)
+	}
+	if called {
+		t.Error("expected handler not to be called whilst unhealthy")
+	}
+}
+
+func TestInterceptorDraining(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true))
+	interceptor := UnaryServerInterceptor(check)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	check.Drain()
+
+	// ensure calls are rejected once the service isn't ready, though it's healthy
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/app.Users/Get"}, handler)
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Errorf("expected %v got %v", codes.Unavailable, code)
+	}
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// HandlerMode is when the handlers, such as HTTPHandler and ReadinessHandler,
+// check the dependencies, see WithHandlerMode
+type HandlerMode struct {
+	onDemand bool
+	ttl      time.Duration
+}
+
+var (
+	// HandlerModeBackground serves the results of the background checks
+	// started by StartCheck, the default
+	HandlerModeBackground = HandlerMode{}
+	// HandlerModeOnDemand checks the dependencies on every request.
+	// Concurrent requests share a single check, so a burst of probes can't
+	// stampede the dependencies.
+	HandlerModeOnDemand = HandlerMode{onDemand: true}
+)
+
+// HandlerModeCached serves the last results whilst they are younger than
+// `ttl`, checking the dependencies on a request once they are older. As with
+// HandlerModeOnDemand, concurrent requests share a single check.
+func HandlerModeCached(ttl time.Duration) HandlerMode {
+	return HandlerMode{ttl: ttl}
+}
+
+// WithHandlerMode sets when HTTPHandler, FastHTTPHandler, ReadinessHandler,
+// LivenessHandler and SSEHandler check the dependencies, by default they serve
+// the results of the background checks
+func WithHandlerMode(mode HandlerMode) Option {
+	return func(s *ServiceCheck) {
+		s.handlerMode = mode
+	}
+}
+
+// refreshes are the on demand checks of a ServiceCheck, only one running at
+// a time
+type refreshes struct {
+	sync.Mutex
+	running *flight
+}
+
+// refresh checks the dependencies before a request is served, as required by
+// the check's HandlerMode, waiting on a check already running if there is one
+func (s *ServiceCheck) refresh() {
+	mode := s.handlerMode
+	if !mode.onDemand && mode.ttl <= 0 {
+		return
+	}
+	if mode.ttl > 0 {
+		checked := s.LastChecked()
+		if !checked.IsZero() && s.getClock().Now().Sub(checked) < mode.ttl {
+			return
+		}
+	}
+
+	s.refreshes.Lock()
+	if f := s.refreshes.running; f != nil {
+		s.refreshes.Unlock()
+		<-f.done
+		return
+	}
+	f := &flight{done: make(chan struct{})}
+	s.refreshes.running = f
+	s.refreshes.Unlock()
+
+	// ensure the waiters are released even if a check panics
+	defer func() {
+		s.refreshes.Lock()
+		s.refreshes.running = nil
+		s.refreshes.Unlock()
+		close(f.done)
+	}()
+
+	s.updateStatus()
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandlerMode(t *testing.T) {
+	tests := []struct {
+		mode     HandlerMode
+		advance  time.Duration
+		expected int32
+	}{
+		// the background checks are served as they are
+		{HandlerModeBackground, time.Hour, 0},
+		{HandlerModeOnDemand, 0, 4},
+		{HandlerModeCached(time.Minute), 59 * time.Second, 2},
+		{HandlerModeCached(time.Minute), time.Minute, 4},
+	}
+
+	for _, test := range tests {
+		clock := &fakeClock{now: time.Now()}
+		check, _ := InitialiseServiceCheck("api", time.Hour, WithClock(clock), WithHandlerMode(test.mode))
+		var runs atomic.Int32
+		check.RegisterDependency("db", LevelHard, func() bool {
+			runs.Add(1)
+			return true
+		})
+		check.Update()
+		runs.Store(0)
+
+		// ensure every handler serving a snapshot follows the mode
+		for _, handler := range []func(http.ResponseWriter, *http.Request){check.HTTPHandler, check.FastHTTPHandler, check.ReadinessHandler, check.LivenessHandler} {
+			clock.advance(test.advance, 0)
+			handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}
+
+		if got := runs.Load(); got != test.expected {
+			t.Errorf("expected %d runs got %d for %+v", test.expected, got, test.mode)
+		}
+	}
+}
+
+func TestHandlerModeOnDemandShared(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Hour, WithHandlerMode(HandlerModeOnDemand))
+
+	release := make(chan struct{})
+	var runs atomic.Int32
+	check.RegisterDependency("db", LevelHard, func() bool {
+		if runs.Add(1) > 1 {
+			<-release
+		}
+		return true
+	})
+
+	// ensure concurrent requests share a single check
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check.HTTPHandler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// one check on registration and one shared by the requests, or a second
+	// if a request arrived after the first finished
+	if got := runs.Load(); got < 2 || got > 3 {
+		t.Errorf("expected 2 runs got %d", got)
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,26 +23,168 @@ const (
 	LevelSoft Level = 0
 	// LevelHard defines a hard dependency, one that's crucial to the service
 	LevelHard Level = 1
+	// LevelInformational defines a dependency which is only reported, its
+	// failure neither fails nor degrades the service
+	LevelInformational Level = 2
 )
 
-var (
-	// HTTPClient is used to make requests, it comes with sensible, pre-defined
-	// timeouts.
-	HTTPClient = &http.Client{
+// ParseLevel returns the Level named `name`, "soft", "hard" or
+// "informational"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "soft":
+		return LevelSoft, nil
+	case "hard":
+		return LevelHard, nil
+	case "informational":
+		return LevelInformational, nil
+	default:
+		return 0, ErrUnknownLevel
+	}
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelSoft:
+		return "soft"
+	case LevelHard:
+		return "hard"
+	case LevelInformational:
+		return "informational"
+	default:
+		return "Level(" + strconv.FormatUint(uint64(l), 10) + ")"
+	}
+}
+
+// MarshalJSON encodes the level by name
+func (l Level) MarshalJSON() ([]byte, error) {
+	if !l.valid() {
+		return nil, ErrUnknownLevel
+	}
+	return json.Marshal(l.String())
+}
+
+// valid returns whether the level is one of those defined
+func (l Level) valid() bool {
+	return l <= LevelInformational
+}
+
+// UnmarshalJSON decodes the level from its name, or from the number older
+// versions of this package encoded it as, so their status can still be read
+func (l *Level) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		var n uint32
+		if err := json.Unmarshal(b, &n); err != nil {
+			return ErrUnknownLevel
+		}
+		name = Level(n).String()
+	}
+
+	level, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// defaultHTTPClient is used to make requests when no client is supplied
+var defaultHTTPClient = NewHTTPClient()
+
+// NewHTTPClient returns a client for making health requests, it comes with
+// sensible, pre-defined timeouts. Use WithHTTPClient, or the optional client
+// argument of the helpers, to use a different one.
+func NewHTTPClient() *http.Client {
+	return &http.Client{
 		Timeout:   500 * time.Millisecond,
 		Transport: http.DefaultTransport,
 	}
-)
+}
 
 // ServiceCheck is the main struct in the package. Use InitialiseHealthCheck to
-// instantiate one
+// instantiate one. Its state is read from a snapshot published on every
+// change, so readers never wait on the checks; once in use it should only be
+// changed through its methods, and read through them or Snapshot.
 type ServiceCheck struct {
-	Name         string        `json:"name"`
+	Name string `json:"name"`
+	// Healthy and Dependencies are the state of the service as the checks
+	// record it.
+	//
+	// Deprecated: they're written whilst the check runs, so reading or
+	// changing them directly races with it. Read them from a Snapshot, or use
+	// IsHealthy and DependencyStates, instead.
 	Healthy      bool          `json:"healthy"`
 	Dependencies []*Dependency `json:"dependencies"`
-
-	duration time.Duration
-	mu       sync.RWMutex
+	// Draining is set once Drain is called, see HandleSignals
+	Draining bool `json:"draining,omitempty"`
+	// ShuttingDown is set once BeginShutdown is called
+	ShuttingDown bool `json:"shuttingDown,omitempty"`
+	// Starting is set whilst Startup waits for the dependencies
+	Starting bool `json:"starting,omitempty"`
+	// Maintenance is set whilst the service is under maintenance, see
+	// SetMaintenanceMode
+	Maintenance bool `json:"maintenance,omitempty"`
+
+	duration        time.Duration
+	clock           Clock
+	client          *http.Client
+	namePolicy      *NamePolicy
+	duplicatePolicy DuplicatePolicy
+	order           Order
+	schedule        SchedulePolicy
+	rename          func(string) string
+	marshalHook     func(map[string]interface{})
+	metadata        *Metadata
+	sseHeartbeat    time.Duration
+	redact          func(string) string
+	history         *history
+	store           Store
+	env             envOverrides
+	ignoreEnv       bool
+	restored        map[string]SavedDependency
+	limiter         *RateLimiter
+	tracer          Tracer
+	view            atomic.Pointer[snapshot]
+	state           atomic.Pointer[state]
+	version         atomic.Uint64
+	shards          [shards]sync.Mutex
+	running         atomic.Bool
+	stopGrace       time.Duration
+	checkTimeout    time.Duration
+	checkTimes      bool
+	degradedCode    int
+	aggregator      Aggregator
+	aggregated      Status
+	unhealthyCode   int
+	startUnhealthy  bool
+	handlerMode     HandlerMode
+	refreshes       refreshes
+	logger          *slog.Logger
+	waitBackoff     time.Duration
+	waitMaxBackoff  time.Duration
+	startupGrace    time.Duration
+	graceUntil      time.Time
+	concurrency     int
+	queueSize       int
+	overrun         OverrunPolicy
+	skipped         atomic.Uint64
+	notifyQueue     int
+	notifyTimeout   time.Duration
+	notifiers       []*notifier
+	reporters       []*reporter
+	dropped         atomic.Uint64
+	loops           *loops
+	watchdog        *watchdog
+	epoch           uint64
+	ctx             context.Context
+	cancel          context.CancelFunc
+	cycle           sync.Mutex
+	lastChecked     atomic.Pointer[time.Time]
+	cycles          int
+	changed         chan struct{}
+	mu              sync.RWMutex
 }
 
 // Dependency defines a dependency and it's status
@@ -47,47 +192,224 @@ type Dependency struct {
 	Name    string `json:"name"`
 	Healthy bool   `json:"healthy"`
 	Level   Level  `json:"level"`
+	URL     string `json:"url,omitempty"`
+	// Remote is the status document of a remote service registered with
+	// RegisterRemoteService and WithRemoteDetail, as of the last check
+	Remote *ServiceCheck `json:"remote,omitempty"`
+	// Stale is set whilst a remote dependency is reporting the last known
+	// status of the remote service, see WithRemoteStaleness, or whilst the
+	// last result of an asynchronous check is too old, see WithAsync
+	Stale bool `json:"stale,omitempty"`
+
+	// Paused is set whilst the dependency's checks are paused, see Pause
+	Paused bool `json:"paused,omitempty"`
+	// Override is the health the dependency is forced to report, see
+	// ForceDependencyState
+	Override *bool `json:"override,omitempty"`
+
+	// LastError is the error the last check failed with, for checks which
+	// return one, such as those registered with RegisterDependencyContext
+	LastError string `json:"lastError,omitempty"`
+	// LastChecked and LastSuccess are when the dependency was last checked
+	// and last found healthy, left out until it has been
+	LastChecked time.Time `json:"lastChecked,omitzero"`
+	LastSuccess time.Time `json:"lastSuccess,omitzero"`
+
+	// Members are the states of the members of a dependency group registered
+	// with RegisterDependencyGroup, as of the last check
+	Members []GroupMember `json:"members,omitempty"`
+
+	check       func() bool
+	errs        *checkErrors
+	panics      *checkErrors
+	probes      Probe
+	interval    time.Duration
+	schedule    CheckSchedule
+	timeout     time.Duration
+	maxLatency  time.Duration
+	weight      float64
+	waited      int
+	failures    int
+	successes   int
+	streak      int
+	remote      *remoteCheck
+	async       *asyncCheck
+	group       *dependencyGroup
+	summary     *HistorySummary
+	skipInitial bool
+	actual      bool
+	breaker     *breaker
+	adaptive    *adaptive
+	ttl         time.Duration
+	sharedRef   *sharedRef
+	owner       *ServiceCheck
+	checked     bool
+	started     time.Time
+	took        time.Duration
+}
+
+// Option configures optional behaviour of a ServiceCheck when it is
+// initialised
+type Option func(*ServiceCheck)
 
-	check func() bool
+// Clock tells the time and waits. It is used for all of a ServiceCheck's
+// timing, so tests can control time, see WithClock
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
 }
 
-// Check200Helper is a helper for checking a service's health endpoint.
-// Function supports passing an optional *http.Client to use a different
-// timeout for the health check.
-func Check200Helper(rawURL string, optionalClient ...*http.Client) (bool, error) {
-	client := getHTTPClient(optionalClient)
+// realClock is the Clock used by default
+type realClock struct{}
 
-	u, err := url.ParseRequestURI(rawURL)
-	if err != nil {
-		return false, err
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock sets the Clock used for the check's timing, for deterministic
+// tests
+func WithClock(clock Clock) Option {
+	return func(s *ServiceCheck) {
+		s.clock = clock
 	}
+}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return false, err
+// WithHTTPClient sets the client used to check the remote services registered
+// on the check, and to poll the peers of its Cluster
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *ServiceCheck) {
+		s.client = client
 	}
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, err
+// DependencyOption configures optional behaviour of a dependency when it is
+// registered
+type DependencyOption func(*Dependency)
+
+// WithURL records the health endpoint of a remote dependency which also uses
+// this package, allowing GetTree to follow it when building a nested health
+// tree
+func WithURL(rawURL string) DependencyOption {
+	return func(d *Dependency) {
+		d.URL = rawURL
 	}
+}
 
-	// ensure resp.Body is closed when function returns
-	defer resp.Body.Close()
+// update runs the dependency's check and records the result, unless it is
+// paused or its last result is still fresh. It returns whether the check ran.
+func (d *Dependency) update() bool {
+	if d.Paused || d.fresh() {
+		return false
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return false, nil
+	d.record(d.run(d.check))
+	return true
+}
+
+// run runs `check`, the dependency's check, once the owner's RateLimiter
+// allows and with pprof labels naming it, returning the result along with
+// when the check started and how long it took. It is unhealthy if it takes
+// longer than the dependency's timeout, see WithTimeout.
+func (d *Dependency) run(check func() bool) (bool, time.Time, time.Duration) {
+	clock := d.clock()
+	if d.owner != nil {
+		d.owner.limiter.wait(clock)
+	}
+	if d.panics != nil {
+		check = d.recovered(check)
+	}
+	// checks taking a context time out through it instead, see
+	// RegisterDependencyContext
+	if d.timeout > 0 && d.errs == nil {
+		check = timeout(check, d.timeout, clock)
+	}
+	check = d.traced(check)
+
+	start := clock.Now()
+	healthy := d.labelled(check)
+	return healthy, start, clock.Now().Sub(start)
+}
+
+// record records the result of the dependency's check
+func (d *Dependency) record(healthy bool, start time.Time, took time.Duration) {
+	previous, checked := d.Healthy, d.checked
+	slow := healthy && d.tooSlow(took)
+	if slow {
+		healthy = false
+	}
+	if d.Override != nil {
+		// thresholds apply to the checked health, not the forced
+		d.Healthy = d.actual
+	}
+	d.Healthy = d.threshold(healthy)
+	if d.Override != nil {
+		d.actual, d.Healthy = d.Healthy, *d.Override
+	}
+	d.checked, d.started, d.took = true, start, took
+	d.waited = 0
+	d.LastChecked = start
+	if healthy {
+		d.LastSuccess = start
+	}
+
+	err := d.checkError()
+	if slow {
+		err = ErrSlowCheck
 	}
+	d.LastError = ""
+	if err != nil && !healthy {
+		d.LastError = err.Error()
+	}
+
+	if d.remote != nil {
+		d.Remote = d.remote.detail()
+		d.Stale = d.remote.isStale()
+	}
+	if d.async != nil {
+		d.Stale = d.async.isStale()
+	}
+	if d.group != nil {
+		d.Members = d.group.members()
+	}
+	if d.breaker != nil {
+		d.breaker.record(d.Healthy, d.clock().Now())
+	}
+	d.logResult(previous, checked, took)
+}
 
-	return true, nil
+// checkError returns the error the dependency's last check failed with, if
+// known
+func (d *Dependency) checkError() error {
+	var err error
+	switch {
+	case d.panics != nil && d.panics.last() != nil:
+		err = d.panics.last()
+	case d.errs != nil:
+		err = d.errs.last()
+	case d.remote != nil:
+		err = d.remote.lastError()
+	}
+	if d.async != nil && err == nil {
+		err = d.async.lastError()
+	}
+
+	return err
+}
+
+// Check200Helper is a helper for checking a service's health endpoint.
+// Function supports passing an optional *http.Client to use a different
+// timeout for the health check. Only http and https URLs are requested, see
+// Check200HelperWithOptions for more control.
+func Check200Helper(rawURL string, optionalClient ...*http.Client) (bool, error) {
+	return Check200HelperWithOptions(rawURL, Check200Options{Client: getHTTPClient(optionalClient)})
 }
 
 // InitialiseServiceCheck returns an initialised check for the service `name`.
-// It's dependencies will be polled every `duration`.
+// It's dependencies will be polled every `duration`. The Env environment
+// variables override it and the settings of the dependencies, see WithoutEnv.
 //
 // Since v2.0.0 the user is required to start the check themselves by calling
 // StartCheck once all dependencies are registered
-func InitialiseServiceCheck(name string, duration time.Duration) (*ServiceCheck, error) {
+func InitialiseServiceCheck(name string, duration time.Duration, opts ...Option) (*ServiceCheck, error) {
 	if name == "" {
 		return nil, ErrNoServiceNameSupplied
 	}
@@ -97,116 +419,490 @@ func InitialiseServiceCheck(name string, duration time.Duration) (*ServiceCheck,
 		Healthy:  true,
 		duration: duration,
 	}
+	for _, opt := range opts {
+		opt(check)
+	}
+	if check.startupGrace > 0 {
+		check.graceUntil = check.getClock().Now().Add(check.startupGrace)
+	}
+	if err := check.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := check.loadState(); err != nil {
+		return nil, err
+	}
+	if err := check.registerWatchdog(); err != nil {
+		return nil, err
+	}
 
 	return check, nil
 }
 
 // WaitForDependencies blocks current thread until all dependencies are healthy
 // if it takes longer than `timeout` to ensure that all dependencies are
-// healthy it will return false
+// healthy it will return false. Once the check is started, by StartCheck or
+// otherwise, it waits on the check's results rather than checking the
+// dependencies itself, until then it checks them with a backoff, see
+// WithWaitBackoff.
 func (s *ServiceCheck) WaitForDependencies(timeout time.Duration) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	go func() {
-		for {
+	deadline := newTimer(s.getClock(), timeout)
+	defer deadline.stop()
+
+	return s.waitForDependencies(context.Background(), deadline.c)
+}
+
+// WaitForDependenciesContext blocks as WaitForDependencies, but until ctx is
+// done rather than for a timeout
+func (s *ServiceCheck) WaitForDependenciesContext(ctx context.Context) bool {
+	return s.waitForDependencies(ctx, nil)
+}
+
+// waitForDependencies waits for the dependencies to be healthy until ctx is
+// done or `deadline` fires. Nothing carries on checking them once it returns.
+func (s *ServiceCheck) waitForDependencies(ctx context.Context, deadline <-chan time.Time) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	checked := make(chan struct{})
+	go s.poll(ctx, checked)
+
+	// the dependencies are checked afresh before they're waited upon
+	select {
+	case <-checked:
+	case <-ctx.Done():
+		return s.load().status.dependenciesHealthy()
+	case <-deadline:
+		return s.load().status.dependenciesHealthy()
+	}
+
+	for {
+		changed := s.Changed()
+		if s.load().status.dependenciesHealthy() {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return s.load().status.dependenciesHealthy()
+		case <-deadline:
+			return s.load().status.dependenciesHealthy()
+		}
+	}
+}
+
+// poll checks the dependencies until ctx is done, whilst the check hasn't been
+// started, backing off between checks as per WithWaitBackoff and closing
+// `checked` after the first time
+func (s *ServiceCheck) poll(ctx context.Context, checked chan<- struct{}) {
+	backoff, maxBackoff := s.waitBackoffs()
+	retry := newTimer(s.getClock(), jitter(backoff))
+	defer retry.stop()
+
+	for {
+		if !s.running.Load() {
 			s.updateStatus()
-			if s.getHealth() {
-				cancel()
-				break
-			}
-			<-time.After(1 * time.Second)
 		}
-	}()
-	<-ctx.Done()
-	return s.getHealth()
+		if checked != nil {
+			close(checked)
+			checked = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-retry.c:
+			backoff = min(backoff*2, maxBackoff)
+			retry.reset(jitter(backoff))
+		}
+	}
 }
 
-// StartCheck will start checking the dependencies
+// StartCheck will start checking the dependencies, until StopCheck is called.
+// A cycle falls due every duration, those falling due whilst the last is still
+// running are queued as per WithCycleQueue.
 func (s *ServiceCheck) StartCheck() {
+	stop, done := s.start()
 	go func() {
+		defer done()
+		s.updateStatus()
+
+		cycles := make(chan struct{}, s.cycleQueue())
+		var ticker sync.WaitGroup
+		ticker.Add(1)
+		go func() {
+			defer ticker.Done()
+			s.tick(stop, cycles)
+		}()
+		defer ticker.Wait()
+
 		for {
-			s.updateStatus()
-			<-time.After(s.duration)
+			select {
+			case <-stop:
+				return
+			case <-cycles:
+				s.scheduledUpdate()
+			}
 		}
 	}()
 }
 
+// Update checks every dependency once and records the result, for callers
+// which drive the checks themselves rather than calling StartCheck
+func (s *ServiceCheck) Update() {
+	s.updateStatus()
+}
+
 // RegisterDependency registers a new dependency on the service. It checks that
 // dependency isn't a duplicate, performs an initial health check, and adds it
-// to be continually checked.
-func (s *ServiceCheck) RegisterDependency(name string, level Level, check func() bool) error {
+// to be continually checked. What happens when the name is already registered
+// depends on the check's DuplicatePolicy, by default it is an error. The
+// returned Dependency is a handle for pausing the dependency or setting its
+// health, see Pause and SetHealthy.
+func (s *ServiceCheck) RegisterDependency(name string, level Level, check func() bool, opts ...DependencyOption) (*Dependency, error) {
+	name, err := s.namePolicy.apply(name)
+	if err != nil {
+		return nil, s.dependencyError(name, err)
+	}
 	if name == "" {
-		return ErrNoDependency
+		return nil, s.dependencyError(name, ErrNoDependency)
+	}
+	// an unknown level couldn't be encoded in the status document
+	if !level.valid() {
+		return nil, s.dependencyError(name, ErrUnknownLevel)
 	}
 
-	for _, dependency := range s.Dependencies {
-		if dependency.Name == name {
-			return ErrDependencyAlreadyRegistered
-		}
+	if _, err := s.Dependency(name); err == nil && s.duplicatePolicy == DuplicateError {
+		return nil, s.dependencyError(name, ErrDependencyAlreadyRegistered)
 	}
 
 	dep := &Dependency{
-		Name:    name,
-		Level:   level,
-		Healthy: check(),
+		Name:  name,
+		Level: level,
 
-		check: check,
+		check:  check,
+		panics: &checkErrors{},
+		owner:  s,
 	}
+	for _, opt := range opts {
+		opt(dep)
+	}
+	s.env.apply(dep)
+	saved, restored := s.takeRestored(name)
+	if restored {
+		dep.restore(saved)
+	}
+	dep.initialCheck()
 
 	s.mu.Lock()
-	s.Dependencies = append(s.Dependencies, dep)
-	s.mu.Unlock()
+	defer s.mu.Unlock()
+	// the dependency may have been registered whilst it was being checked
+	if i := s.indexOf(name); i >= 0 {
+		return s.registerDuplicate(i, dep)
+	}
+
+	s.insert(dep)
+	if restored {
+		s.restoreHistory(dep, saved.History)
+	}
+	s.notifyChanged()
+	return dep, nil
+}
+
+// UnregisterDependency removes the named dependency, it is safe to call
+// whilst the check is running
+func (s *ServiceCheck) UnregisterDependency(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.indexOf(name)
+	if i < 0 {
+		return s.dependencyError(name, ErrNoDependency)
+	}
+
+	s.forget(s.Dependencies[i])
+	s.Dependencies = append(s.Dependencies[:i:i], s.Dependencies[i+1:]...)
+	s.notifyChanged()
+	return nil
+}
+
+// ReplaceDependency swaps the check, level and options of the named
+// dependency, in its place, it is safe to call whilst the check is running.
+// The new check is run before the old one is dropped.
+func (s *ServiceCheck) ReplaceDependency(name string, level Level, check func() bool, opts ...DependencyOption) error {
+	if _, err := s.Dependency(name); err != nil {
+		return err
+	}
+	if !level.valid() {
+		return s.dependencyError(name, ErrUnknownLevel)
+	}
+
+	dep := &Dependency{
+		Name:  name,
+		Level: level,
+
+		check:  check,
+		panics: &checkErrors{},
+		owner:  s,
+	}
+	for _, opt := range opts {
+		opt(dep)
+	}
+	s.env.apply(dep)
+	dep.initialCheck()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the dependency may have been unregistered whilst it was being checked
+	i := s.indexOf(name)
+	if i < 0 {
+		dep.releaseShared()
+		return s.dependencyError(name, ErrNoDependency)
+	}
+
+	dep.Name = s.Dependencies[i].Name
+	s.forget(s.Dependencies[i])
+	s.Dependencies[i] = dep
+	s.recordHealth(true)
 	return nil
 }
 
+// indexOf returns the index of the named dependency or -1, s.mu must be held
+func (s *ServiceCheck) indexOf(name string) int {
+	for i, dependency := range s.Dependencies {
+		if s.sameName(dependency.Name, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // Dependency finds and returns the named dependency
 func (s *ServiceCheck) Dependency(name string) (*Dependency, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, dependency := range s.Dependencies {
-		if dependency.Name == name {
+	for _, dependency := range s.load().handles {
+		if s.sameName(dependency.Name, name) {
 			return dependency, nil
 		}
 	}
 
-	return nil, ErrNoDependency
+	return nil, s.dependencyError(name, ErrNoDependency)
+}
+
+// DependencyStates returns a copy of every dependency as of the last check, in
+// the check's Order. Unlike reading Dependencies directly it is safe to call
+// whilst the check is running.
+func (s *ServiceCheck) DependencyStates() []Dependency {
+	dependencies := s.load().status.Dependencies
+	states := make([]Dependency, len(dependencies))
+	for i, dependency := range dependencies {
+		states[i] = *dependency
+	}
+
+	return states
 }
 
 func (s *ServiceCheck) getHealth() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.Healthy
+	return s.precomputed().healthy
 }
 
+// pending is a dependency being checked by updateStatus
+type pending struct {
+	dep     *Dependency
+	check   func() bool
+	skip    bool
+	level   Level
+	healthy bool
+	start   time.Time
+	took    time.Duration
+}
+
+// updateStatus checks the dependencies on demand, whether or not their
+// interval has passed, see update
 func (s *ServiceCheck) updateStatus() {
+	s.update(false)
+}
+
+// scheduledUpdate checks the dependencies due in a scheduled cycle, see update
+func (s *ServiceCheck) scheduledUpdate() {
+	s.update(true)
+}
+
+// update checks the due dependencies in turn, or concurrently with
+// WithConcurrency, without holding s.mu so readers and writers aren't held up
+// by slow checks, then records the results unless StopCheck was called in the
+// meantime. Dependencies whose interval hasn't passed are only checked if the
+// cycle isn't `scheduled`.
+func (s *ServiceCheck) update(scheduled bool) {
+	s.cycle.Lock()
+	defer s.cycle.Unlock()
+
+	s.mu.Lock()
+	epoch, now := s.epoch, s.getClock().Now()
+	due := s.due()
+	checks := make([]pending, len(due))
+	for i, dependency := range due {
+		checks[i] = pending{
+			dep:     dependency,
+			check:   dependency.check,
+			skip:    dependency.Paused || dependency.fresh() || scheduled && (!dependency.intervalPassed(s.duration) || !dependency.scheduleDue(now, s.duration)),
+			level:   dependency.Level,
+			healthy: dependency.Healthy,
+		}
+	}
+	s.mu.Unlock()
+
+	checks = s.runChecks(checks)
+
+	// ensure the state is saved once s.mu is released
+	defer s.saveState()
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	// loop through and change to unhealthy if any dependents are unhealthy
+	if s.epoch != epoch {
+		return
+	}
+
+	var changed bool
+	for _, c := range checks {
+		// the dependency may have been unregistered whilst it was being checked
+		if c.skip || !s.registered(c.dep) {
+			continue
+		}
+
+		previous := c.dep.Healthy
+		c.dep.record(c.healthy, c.start, c.took)
+		s.remember(c.dep)
+		if c.dep.Healthy != previous {
+			changed = true
+		}
+	}
+
+	s.setLastChecked(s.getClock().Now())
+	s.recordHealth(changed)
+	if scheduled {
+		s.report()
+	}
+}
+
+// registered returns whether `dep` is still registered, s.mu must be held
+func (s *ServiceCheck) registered(dep *Dependency) bool {
 	for _, dependency := range s.Dependencies {
-		dependency.Healthy = dependency.check()
+		if dependency == dep {
+			return true
+		}
+	}
+
+	return false
+}
 
+// recordHealth sets the health of the service from that of its dependencies,
+// as per its Aggregator, unless within its startup grace period, notifying
+// Changed if it or any dependency has `changed`. s.mu must be held
+func (s *ServiceCheck) recordHealth(changed bool) {
+	status := s.aggregate()
+	healthy := status != StatusUnhealthy || s.inGrace()
+	// whether it is degraded is otherwise decided by the dependencies which
+	// changed
+	if s.Healthy != healthy || s.aggregator != nil && s.aggregated != status {
+		changed = true
+	}
+	s.Healthy, s.aggregated = healthy, status
+
+	if changed {
+		s.notifyChanged()
+	} else {
+		s.publish()
+	}
+}
+
+// dependenciesHealthy returns whether every hard dependency is healthy, s.mu
+// must be held
+func (s *ServiceCheck) dependenciesHealthy() bool {
+	for _, dependency := range s.Dependencies {
 		if !dependency.Healthy && dependency.Level == LevelHard {
-			s.Healthy = false
-			return
+			return false
 		}
 	}
 
-	s.Healthy = true
+	return true
+}
+
+// LastChecked returns when the dependencies were last checked, or the zero
+// time if they haven't been checked since registration
+func (s *ServiceCheck) LastChecked() time.Time {
+	if checked := s.load().status.lastChecked.Load(); checked != nil {
+		return *checked
+	}
+
+	return time.Time{}
+}
+
+// Changed returns a channel which is closed the next time the health of the
+// service or one of its dependencies changes, or a dependency is registered.
+// Call it again after each change to keep watching.
+func (s *ServiceCheck) Changed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+
+	return s.changed
+}
+
+// notifyChanged publishes the state and wakes anything waiting on Changed or
+// OnStatusChange, s.mu must be held
+func (s *ServiceCheck) notifyChanged() {
+	s.publish()
+	s.notify()
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
 }
 
 // WriteStatus writes the status to any io.Writer
 func (s *ServiceCheck) WriteStatus(w io.Writer) error {
-	return json.NewEncoder(w).Encode(s)
+	return s.load().status.writeStatus(w)
 }
 
-// HTTPHandler outputs the status with the relevant response code to a ResponseWriter
-func (s *ServiceCheck) HTTPHandler(w http.ResponseWriter, r *http.Request) {
-	if s.getHealth() {
-		w.WriteHeader(200)
-	} else {
-		w.WriteHeader(503)
+// WriteResponse writes the status to `w` as WriteStatus, returning the status
+// code HTTPHandler would respond with, both taken from the same check, for
+// handlers serving the status other than over net/http
+func (s *ServiceCheck) WriteResponse(w io.Writer) (int, error) {
+	status := s.load().status
+	return status.statusCode(), status.writeStatus(w)
+}
+
+// writeStatus encodes the status of the snapshot `s`, streaming it unless the
+// document is transformed
+func (s *ServiceCheck) writeStatus(w io.Writer) error {
+	if s.rename == nil && s.redact == nil && s.marshalHook == nil {
+		return s.stream(w)
 	}
 
-	s.WriteStatus(w)
+	b, err := s.marshal(s.document())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// HTTPHandler outputs the status with the relevant response code to a
+// ResponseWriter. The response code is 200 whilst IsServing, or that set by
+// WithDegradedStatusCode whilst degraded, and 503, or that set by
+// WithUnhealthyStatusCode, otherwise. The dependencies are checked first if
+// the check's HandlerMode requires it. The status is written in the Format
+// requested by the format query parameter or the Accept header, JSON by
+// default.
+func (s *ServiceCheck) HTTPHandler(w http.ResponseWriter, r *http.Request) {
+	s.refresh()
+	status := s.load().status
+	format := negotiate(r)
+	w.Header()["Content-Type"] = contentTypes[format]
+	w.WriteHeader(status.statusCode())
+	status.writeStatusFormat(w, format)
 }
 
 // IsHealthy returns a bool whether this ServiceCheck is healthy
@@ -214,43 +910,75 @@ func (s *ServiceCheck) IsHealthy() bool {
 	return s.getHealth()
 }
 
+// IsServing returns a bool whether this ServiceCheck is healthy and neither
+// starting up, draining nor under maintenance, and so should receive traffic
+func (s *ServiceCheck) IsServing() bool {
+	return s.precomputed().serving
+}
+
+func (s *ServiceCheck) isServing() bool {
+	return s.Healthy && !s.Draining && !s.Starting && !s.Maintenance
+}
+
 // Get is a wrapper which checks whether the URL is healthy
 func Get(url string, optionalClient ...*http.Client) (bool, error) {
+	healthy, _, err := get(context.Background(), getHTTPClient(optionalClient), url)
+	return healthy, err
+}
+
+// get performs the request behind Get, additionally returning the status code
+// of the response
+func get(ctx context.Context, client *http.Client, url string) (bool, int, error) {
 	var (
 		response ServiceCheck
 	)
-	client := getHTTPClient(optionalClient)
 	r, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 
-	resp, err := client.Do(r)
+	resp, err := client.Do(r.WithContext(ctx))
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 
 	// ensure resp.Body is closed when function returns
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return false, nil
+		return false, resp.StatusCode, nil
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return false, err
+	if err := decodeResponse(resp, &response); err != nil {
+		return false, resp.StatusCode, err
 	}
 
-	return response.Healthy, nil
+	return response.Healthy, resp.StatusCode, nil
+}
+
+// getClock returns the Clock set by WithClock, or the real clock
+func (s *ServiceCheck) getClock() Clock {
+	if s.clock == nil {
+		return realClock{}
+	}
+	return s.clock
+}
+
+// getHTTPClient returns the client set by WithHTTPClient, or the default
+func (s *ServiceCheck) getHTTPClient() *http.Client {
+	if s.client == nil {
+		return defaultHTTPClient
+	}
+	return s.client
 }
 
 // getHTTPClient is a helper function to parse the optional argument
-// and either return the passed HTTP client or use the default HTTPClient
+// and either return the passed HTTP client or use the default client
 func getHTTPClient(optionalClient []*http.Client) *http.Client {
 	if len(optionalClient) > 0 {
 		return optionalClient[0]
 	}
-	return HTTPClient
+	return defaultHTTPClient
 }
 
 // Errors
@@ -258,4 +986,23 @@ var (
 	ErrNoServiceNameSupplied       = errors.New("no service name supplied")
 	ErrDependencyAlreadyRegistered = errors.New("dependent already registered")
 	ErrNoDependency                = errors.New("no dependency registered")
+	ErrServiceAlreadyRegistered    = errors.New("service already registered")
+	ErrNoServiceCheck              = errors.New("no service check registered")
+	ErrNoCertificate               = errors.New("no TLS certificate supplied")
+	ErrUnhealthy                   = errors.New("unhealthy")
+	ErrUnknownLevel                = errors.New("unknown level")
+	ErrNoCheck                     = errors.New("no check supplied")
+	ErrInvalidDependencyName       = errors.New("invalid dependency name")
+	ErrResponseTooLarge            = errors.New("response too large")
+	ErrUnexpectedContentType       = errors.New("unexpected content type")
+	ErrUnsupportedScheme           = errors.New("unsupported URL scheme")
+	ErrTooManyRedirects            = errors.New("too many redirects")
+	ErrResponseHeaderTimeout       = errors.New("timed out awaiting response headers")
+	ErrInvalidGroupSize            = errors.New("minimum healthy members out of range")
+	ErrCheckPanicked               = errors.New("check panicked")
+	ErrNoRecentResult              = errors.New("no result within the maximum age")
+	ErrSlowCheck                   = errors.New("check exceeded its maximum latency")
+	ErrInvalidCron                 = errors.New("invalid cron expression")
+	ErrInvalidEnv                  = errors.New("invalid environment variable")
+	ErrInvalidInterval             = errors.New("interval must be positive")
 )
@@ -1,10 +1,16 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -56,6 +62,8 @@ func TestRegisterDependency(t *testing.T) {
 
 		// Failing - no name
 		{Dependency{}, ErrNoDependency, true},
+		// Failing - unknown level
+		{Dependency{Name: "db", Level: Level(3), check: func() bool { return false }}, ErrUnknownLevel, true},
 	}
 
 	for i, test := range tests {
@@ -64,8 +72,8 @@ func TestRegisterDependency(t *testing.T) {
 			t.Errorf("expected nil got %v", err)
 		}
 
-		err = check.RegisterDependency(test.dependency.Name, test.dependency.Level, test.dependency.check)
-		if err != test.expectedErr {
+		_, err = check.RegisterDependency(test.dependency.Name, test.dependency.Level, test.dependency.check)
+		if !errors.Is(err, test.expectedErr) {
 			t.Errorf("expected %v got %v", test.expectedErr, err)
 		}
 
@@ -104,7 +112,7 @@ func TestDependency(t *testing.T) {
 
 	for _, test := range tests {
 		check := &ServiceCheck{}
-		if err := check.RegisterDependency(test.dependency.Name,
+		if _, err := check.RegisterDependency(test.dependency.Name,
 			test.dependency.Level, test.dependency.check); err != nil {
 			t.Errorf("expected nil got %v", err)
 		}
@@ -148,7 +156,7 @@ func TestEndpointHelper(t *testing.T) {
 		}))
 
 		resp, err := Check200Helper(server.URL)
-		if err != test.expectedErr {
+		if !errors.Is(err, test.expectedErr) {
 			t.Errorf("expected %v got %v", test.expectedErr, err)
 		}
 		if resp != test.expected {
@@ -156,7 +164,7 @@ func TestEndpointHelper(t *testing.T) {
 		}
 
 		resp, err = Check200Helper(server.URL, testHTTPClient)
-		if err != test.expectedErr {
+		if !errors.Is(err, test.expectedErr) {
 			t.Errorf("expected %v got %v", test.expectedErr, err)
 		}
 		if resp != test.expected {
@@ -291,6 +299,289 @@ func TestWaitForDependencies(t *testing.T) {
 	})
 }
 
+func TestUnregisterDependency(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+
+	if err := check.UnregisterDependency("redis"); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if err := check.UnregisterDependency("redis"); !errors.Is(err, ErrNoDependency) {
+		t.Errorf("expected %v got %v", ErrNoDependency, err)
+	}
+
+	states := check.DependencyStates()
+	if len(states) != 1 || states[0].Name != "cache" {
+		t.Errorf("expected only cache to remain got %+v", states)
+	}
+
+	// the name can be registered again
+	if _, err := check.RegisterDependency("redis", LevelHard, func() bool { return true }); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+}
+
+func TestReplaceDependency(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Millisecond)
+	check.RegisterDependency("redis", LevelSoft, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+	check.StartCheck()
+	defer check.StopCheck()
+
+	// ensure the dependency can be swapped whilst the check is running
+	if err := check.ReplaceDependency("redis", LevelHard, func() bool { return false }); err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if err := check.ReplaceDependency("missing", LevelHard, func() bool { return false }); !errors.Is(err, ErrNoDependency) {
+		t.Errorf("expected %v got %v", ErrNoDependency, err)
+	}
+	if err := check.ReplaceDependency("cache", Level(3), func() bool { return false }); !errors.Is(err, ErrUnknownLevel) {
+		t.Errorf("expected %v got %v", ErrUnknownLevel, err)
+	}
+
+	states := check.DependencyStates()
+	if len(states) != 2 || states[0].Name != "redis" || states[0].Level != LevelHard || states[0].Healthy {
+		t.Errorf("expected an unhealthy hard redis in its place got %+v", states)
+	}
+	if check.IsHealthy() {
+		t.Error("expected the service to be unhealthy")
+	}
+}
+
+func TestDependencyStates(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+
+	states := check.DependencyStates()
+	if len(states) != 2 {
+		t.Fatalf("expected 2 dependencies got %d", len(states))
+	}
+	if states[0].Name != "redis" || !states[0].Healthy {
+		t.Errorf("expected healthy redis got %s %v", states[0].Name, states[0].Healthy)
+	}
+	if states[1].Name != "cache" || states[1].Healthy {
+		t.Errorf("expected unhealthy cache got %s %v", states[1].Name, states[1].Healthy)
+	}
+
+	// mutating the copy must not affect the check
+	states[0].Healthy = false
+	if dep, _ := check.Dependency("redis"); !dep.Healthy {
+		t.Error("expected dependency to be unaffected by changes to its state")
+	}
+}
+
+func TestChanged(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return healthy.Load() })
+
+	changed := check.Changed()
+	check.updateStatus()
+	select {
+	case <-changed:
+		t.Fatal("expected no change whilst the dependency is healthy")
+	default:
+	}
+
+	healthy.Store(false)
+	check.updateStatus()
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected a change when the dependency became unhealthy")
+	}
+
+	if check.Changed() == changed {
+		t.Error("expected a fresh channel after a change")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return healthy.Load() })
+
+	healthy.Store(false)
+	check.Update()
+	if check.IsHealthy() {
+		t.Error("expected to be unhealthy after Update")
+	}
+}
+
+// fakeClock is a Clock which only moves when advanced
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{c.now.Add(d), ch})
+	return ch
+}
+
+// advance moves the clock on, firing any waiters which are due, once at
+// least `waiters` are waiting
+func (c *fakeClock) advance(d time.Duration, waiters int) {
+	for {
+		c.mu.Lock()
+		if len(c.waiters) >= waiters {
+			break
+		}
+		c.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+func TestWithClock(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("test", time.Minute, WithClock(clock))
+	check.RegisterDependency("redis", LevelHard, func() bool { return healthy.Load() })
+
+	changed := check.Changed()
+	check.StartCheck()
+	clock.advance(0, 1)
+	if !check.LastChecked().Equal(clock.Now()) {
+		t.Errorf("expected last checked %v got %v", clock.Now(), check.LastChecked())
+	}
+
+	healthy.Store(false)
+	clock.advance(time.Minute, 1)
+	<-changed
+	if check.IsHealthy() {
+		t.Error("expected to be unhealthy after the next check")
+	}
+}
+
+func TestWaitForDependenciesClock(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("test", time.Second, WithClock(clock))
+	check.RegisterDependency("redis", LevelHard, func() bool { return false })
+
+	done := make(chan bool)
+	go func() { done <- check.WaitForDependencies(time.Hour) }()
+
+	// the deadline and the first retry
+	clock.advance(time.Hour, 2)
+	if <-done {
+		t.Error("expected to time out")
+	}
+}
+
+func TestWaitForDependenciesLeak(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return false })
+
+	before := runtime.NumGoroutine()
+	if check.WaitForDependencies(100 * time.Millisecond) {
+		t.Error("expected to time out")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if check.WaitForDependenciesContext(ctx) {
+		t.Error("expected to time out")
+	}
+
+	// nothing is left checking the dependencies
+	for i := 0; runtime.NumGoroutine() > before; i++ {
+		if i == 100 {
+			t.Fatalf("expected at most %d goroutines got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWaitForDependenciesContext(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return true })
+
+	if !check.WaitForDependenciesContext(context.Background()) {
+		t.Error("expected to be healthy")
+	}
+}
+
+func TestWaitForDependenciesStarted(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("test", time.Minute, WithClock(clock))
+
+	var mu sync.Mutex
+	checks, healthy := 0, false
+	check.RegisterDependency("redis", LevelHard, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		checks++
+		return healthy
+	})
+	check.StartCheck()
+
+	done := make(chan bool)
+	go func() { done <- check.WaitForDependencies(time.Hour) }()
+
+	// the deadline, the retry and StartCheck's timer
+	clock.advance(time.Second, 3)
+	mu.Lock()
+	if checks != 2 {
+		t.Errorf("expected only StartCheck to check got %d checks", checks)
+	}
+	healthy = true
+	mu.Unlock()
+
+	// the next check by StartCheck is waited upon
+	clock.advance(time.Minute, 3)
+	if !<-done {
+		t.Error("expected to be healthy")
+	}
+}
+
+func TestLastChecked(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	if !check.LastChecked().IsZero() {
+		t.Errorf("expected zero time got %v", check.LastChecked())
+	}
+
+	before := time.Now()
+	check.updateStatus()
+	if check.LastChecked().Before(before) {
+		t.Errorf("expected last checked after %v got %v", before, check.LastChecked())
+	}
+}
+
 func TestGetHealth(t *testing.T) {
 	healthCheck := &ServiceCheck{
 		Name:     "test",
@@ -396,3 +687,43 @@ func BenchmarkCheck200Helper(b *testing.B) {
 		}
 	}
 }
+
+func TestLevel(t *testing.T) {
+	testCases := []struct {
+		json     string
+		expected Level
+		err      error
+	}{
+		{`"hard"`, LevelHard, nil},
+		{`"SOFT"`, LevelSoft, nil},
+		{`1`, LevelHard, nil},
+		{`0`, LevelSoft, nil},
+		{`"informational"`, LevelInformational, nil},
+		{`2`, LevelInformational, nil},
+		{`"medium"`, 0, ErrUnknownLevel},
+		{`3`, 0, ErrUnknownLevel},
+		{`true`, 0, ErrUnknownLevel},
+	}
+
+	for _, tc := range testCases {
+		var got Level
+		err := json.Unmarshal([]byte(tc.json), &got)
+		if err != tc.err {
+			t.Errorf("expected %v got %v for %s", tc.err, err, tc.json)
+		}
+		if err == nil && got != tc.expected {
+			t.Errorf("expected %v got %v for %s", tc.expected, got, tc.json)
+		}
+	}
+
+	b, err := json.Marshal(Dependency{Name: "db", Level: LevelHard})
+	if err != nil || !strings.Contains(string(b), `"level":"hard"`) {
+		t.Errorf("expected the level by name got %s %v", b, err)
+	}
+	if _, err := json.Marshal(Level(3)); err == nil {
+		t.Error("expected an error marshalling an unknown level")
+	}
+	if Level(3).String() != "Level(3)" {
+		t.Errorf("expected Level(3) got %s", Level(3))
+	}
+}
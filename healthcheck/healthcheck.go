@@ -0,0 +1,72 @@
+// Package healthcheck probes a local health endpoint and reports the outcome
+// as an exit code, sized for Docker's HEALTHCHECK CMD in images without curl.
+// Services can dispatch to it from their own main:
+//
+//	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+//		os.Exit(healthcheck.Run(os.Args[2:], os.Stdout))
+//	}
+//
+// and declare it in their Dockerfile:
+//
+//	HEALTHCHECK CMD ["/app", "healthcheck", "-url", "http://localhost:8080/health"]
+package healthcheck
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+const (
+	// DefaultURL is probed when no -url is given
+	DefaultURL = "http://localhost:8080/health"
+	// DefaultTimeout bounds the probe when no -timeout is given
+	DefaultTimeout = 3 * time.Second
+)
+
+// Exit codes as understood by Docker
+const (
+	ExitHealthy   = 0
+	ExitUnhealthy = 1
+)
+
+// Run parses `args`, probes the health endpoint and writes a one line summary
+// to `w`, returning ExitHealthy or ExitUnhealthy. Unparsable arguments are
+// reported as unhealthy.
+func Run(args []string, w io.Writer) int {
+	flags := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	flags.SetOutput(w)
+	url := flags.String("url", DefaultURL, "health endpoint to probe")
+	timeout := flags.Duration("timeout", DefaultTimeout, "timeout for the probe")
+	if err := flags.Parse(args); err != nil {
+		return ExitUnhealthy
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	message, code := Probe(ctx, *url, &http.Client{Timeout: *timeout})
+	fmt.Fprintln(w, message)
+	return code
+}
+
+// Probe requests the health endpoint at url and returns a concise message and
+// the exit code describing the outcome. Function supports passing an optional
+// *http.Client to use a different timeout for the probe.
+func Probe(ctx context.Context, url string, optionalClient ...*http.Client) (string, int) {
+	result := health.GetAll(ctx, []string{url}, optionalClient...)[url]
+
+	switch {
+	case result.Err != nil:
+		return fmt.Sprintf("unhealthy: %s: %v", url, result.Err), ExitUnhealthy
+	case !result.Healthy:
+		return fmt.Sprintf("unhealthy: %s returned %d", url, result.StatusCode), ExitUnhealthy
+	default:
+		return fmt.Sprintf("healthy: %s in %s", url, result.Latency.Round(time.Millisecond)), ExitHealthy
+	}
+}
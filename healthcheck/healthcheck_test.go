@@ -0,0 +1,39 @@
+package healthcheck
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fresh8/health"
+)
+
+func TestRun(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc((&health.ServiceCheck{Name: "api", Healthy: true}).HTTPHandler))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc((&health.ServiceCheck{Name: "api"}).HTTPHandler))
+	defer unhealthy.Close()
+
+	tests := []struct {
+		args     []string
+		expected int
+		output   string
+	}{
+		{[]string{"-url", healthy.URL}, ExitHealthy, "healthy: "},
+		{[]string{"-url", unhealthy.URL}, ExitUnhealthy, "returned 503"},
+		{[]string{"-url", "http://127.0.0.1:1"}, ExitUnhealthy, "unhealthy: "},
+		{[]string{"-bogus"}, ExitUnhealthy, "flag provided but not defined"},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		if code := Run(test.args, &out); code != test.expected {
+			t.Errorf("expected %v got %v for %v", test.expected, code, test.args)
+		}
+		if !strings.Contains(out.String(), test.output) {
+			t.Errorf("expected %q in %q", test.output, out.String())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package healthconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Environment variables overriding the config, so health can be tuned per
+// environment without rebuilding. They take precedence over config files. All
+// but EnvListen are also read by health.InitialiseServiceCheck.
+const (
+	// EnvInterval overrides the interval between checks of the service
+	EnvInterval = health.EnvInterval
+	// EnvTimeout overrides the timeout of every check
+	EnvTimeout = health.EnvTimeout
+	// EnvFailureThreshold overrides the failure threshold of every check
+	EnvFailureThreshold = health.EnvFailureThreshold
+	// EnvSuccessThreshold overrides the success threshold of every check
+	EnvSuccessThreshold = health.EnvSuccessThreshold
+	// EnvListen overrides the address health is served on, see Listen
+	EnvListen = "HEALTH_LISTEN"
+)
+
+// ApplyEnv overrides the config with any of the Env environment variables
+// which are set. Load calls it after reading the file.
+func (c *Config) ApplyEnv() error {
+	if err := envDuration(EnvInterval, &c.Interval); err != nil {
+		return err
+	}
+
+	var (
+		timeout   Duration
+		failures  int
+		successes int
+	)
+	if err := envDuration(EnvTimeout, &timeout); err != nil {
+		return err
+	}
+	if err := envInt(EnvFailureThreshold, &failures); err != nil {
+		return err
+	}
+	if err := envInt(EnvSuccessThreshold, &successes); err != nil {
+		return err
+	}
+
+	for i := range c.Checks {
+		if timeout > 0 {
+			c.Checks[i].Timeout = timeout
+		}
+		if failures > 0 {
+			c.Checks[i].FailureThreshold = failures
+		}
+		if successes > 0 {
+			c.Checks[i].SuccessThreshold = successes
+		}
+	}
+
+	return nil
+}
+
+// Listen returns the address set by EnvListen, or `fallback` if it isn't set
+func Listen(fallback string) string {
+	if addr := os.Getenv(EnvListen); addr != "" {
+		return addr
+	}
+	return fallback
+}
+
+func envDuration(name string, d *Duration) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("healthconfig: invalid %s %q", name, v)
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+func envInt(name string, n *int) error {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed <= 0 {
+		return fmt.Errorf("healthconfig: invalid %s %q", name, v)
+	}
+
+	*n = parsed
+	return nil
+}
@@ -0,0 +1,62 @@
+package healthconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv(EnvInterval, "1m")
+	t.Setenv(EnvTimeout, "500ms")
+	t.Setenv(EnvFailureThreshold, "4")
+	t.Setenv(EnvSuccessThreshold, "")
+
+	config := &Config{
+		Interval: Duration(time.Second),
+		Checks:   []Check{{Name: "db", Timeout: Duration(time.Second), SuccessThreshold: 2}},
+	}
+	if err := config.ApplyEnv(); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	if time.Duration(config.Interval) != time.Minute {
+		t.Errorf("expected 1m got %v", time.Duration(config.Interval))
+	}
+	check := config.Checks[0]
+	if time.Duration(check.Timeout) != 500*time.Millisecond || check.FailureThreshold != 4 || check.SuccessThreshold != 2 {
+		t.Errorf("unexpected check %+v", check)
+	}
+}
+
+func TestApplyEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{EnvInterval, "often"},
+		{EnvTimeout, "-1s"},
+		{EnvFailureThreshold, "three"},
+		{EnvSuccessThreshold, "0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(test.name, test.value)
+			if err := (&Config{}).ApplyEnv(); err == nil {
+				t.Errorf("expected an error for %s=%s", test.name, test.value)
+			}
+		})
+	}
+}
+
+func TestListen(t *testing.T) {
+	t.Setenv(EnvListen, "")
+	if addr := Listen(":8080"); addr != ":8080" {
+		t.Errorf("expected :8080 got %s", addr)
+	}
+
+	t.Setenv(EnvListen, ":9090")
+	if addr := Listen(":8080"); addr != ":9090" {
+		t.Errorf("expected :9090 got %s", addr)
+	}
+}
@@ -0,0 +1,343 @@
+// Package healthconfig builds a ServiceCheck from a JSON or YAML file
+// describing its dependencies, so standard checks can be tuned without code
+// changes:
+//
+//	name: billing
+//	interval: 10s
+//	checks:
+//	  - name: db
+//	    type: sql
+//	    driver: postgres
+//	    target: postgres://billing@db/billing
+//	    level: hard
+//	    interval: 30s
+//	    timeout: 2s
+//	    failure_threshold: 3
+//	  - name: cache
+//	    type: redis
+//	    target: cache:6379
+//
+// The interval, timeouts, thresholds and listen address can be overridden per
+// environment with the HEALTH_ environment variables, see ApplyEnv.
+//
+// YAML support is pluggable to avoid a dependency, set YAMLUnmarshal to
+// gopkg.in/yaml.v3's or sigs.k8s.io/yaml's Unmarshal. SQL checks require the
+// driver to be imported by the application.
+package healthconfig
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Defaults applied to unset config values
+const (
+	DefaultInterval = 10 * time.Second
+	DefaultTimeout  = 2 * time.Second
+)
+
+// YAMLUnmarshal decodes YAML config files, which are rejected whilst it is
+// nil. The decoded value is converted to JSON, so the unmarshaller must decode
+// mappings into map[string]interface{}.
+var YAMLUnmarshal func(b []byte, v interface{}) error
+
+// ErrNoYAML is returned when loading YAML whilst YAMLUnmarshal is nil
+var ErrNoYAML = errors.New("healthconfig: YAMLUnmarshal must be set to load YAML")
+
+// Duration is a time.Duration which is written as a string such as "10s" in
+// config files
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config describes a ServiceCheck
+type Config struct {
+	// Name of the service
+	Name string `json:"name"`
+	// Interval between checks, DefaultInterval if not set
+	Interval Duration `json:"interval"`
+	// Checks are the dependencies of the service
+	Checks []Check `json:"checks"`
+}
+
+// Check describes a single dependency
+type Check struct {
+	Name string `json:"name"`
+	// Type is one of:
+	//
+	//	http   healthy when a GET of Target responds 200
+	//	tcp    healthy when a connection to the Target host:port succeeds
+	//	sql    healthy when the database at the Target DSN can be pinged
+	//	redis  healthy when the Redis server at Target host:port answers PING
+	//	exec   healthy when the Target command exits 0
+	Type   string `json:"type"`
+	Target string `json:"target"`
+	// Driver is the database/sql driver of sql checks
+	Driver string `json:"driver"`
+	// Level is "hard" or "soft", soft if empty
+	Level string `json:"level"`
+	// Interval between checks of this dependency, if longer than that of the
+	// service. The last result is reported in between.
+	Interval Duration `json:"interval"`
+	// Timeout bounds the check, DefaultTimeout if not set
+	Timeout Duration `json:"timeout"`
+	// FailureThreshold is the number of consecutive failures before a
+	// healthy dependency is reported unhealthy, 1 if not set
+	FailureThreshold int `json:"failure_threshold"`
+	// SuccessThreshold is the number of consecutive successes before an
+	// unhealthy dependency is reported healthy, 1 if not set
+	SuccessThreshold int `json:"success_threshold"`
+}
+
+// Load reads a config file, decoding it as YAML if its extension is .yaml or
+// .yml and as JSON otherwise, and applies any environment overrides
+func Load(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(b, IsYAML(path))
+	if err != nil {
+		return nil, fmt.Errorf("healthconfig: %s: %v", path, err)
+	}
+
+	if err := config.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Parse decodes a config from JSON, or from YAML if `yaml` is set
+func Parse(b []byte, yaml bool) (*Config, error) {
+	var config Config
+	if err := Unmarshal(b, yaml, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Unmarshal decodes JSON, or YAML if `yaml` is set, into v using its json
+// struct tags, for config types embedding or extending Config
+func Unmarshal(b []byte, yaml bool, v interface{}) error {
+	if yaml {
+		if YAMLUnmarshal == nil {
+			return ErrNoYAML
+		}
+
+		var decoded interface{}
+		if err := YAMLUnmarshal(b, &decoded); err != nil {
+			return err
+		}
+
+		var err error
+		if b, err = json.Marshal(decoded); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// IsYAML reports whether the file at path should be decoded as YAML, going by
+// its extension
+func IsYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ServiceCheck builds a ServiceCheck with a dependency for every check in the
+// config. The check isn't started.
+func (c *Config) ServiceCheck() (*health.ServiceCheck, error) {
+	interval := time.Duration(c.Interval)
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	check, err := health.InitialiseServiceCheck(c.Name, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Register(check, c.Checks); err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
+// Register registers every check as a dependency of `check`
+func Register(check *health.ServiceCheck, checks []Check) error {
+	for _, cc := range checks {
+		fn, err := cc.Func()
+		if err != nil {
+			return err
+		}
+
+		level, err := cc.level()
+		if err != nil {
+			return err
+		}
+
+		if _, err := check.RegisterDependency(cc.Name, level, fn, cc.Options()...); err != nil {
+			return fmt.Errorf("healthconfig: check %q: %v", cc.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Func returns the function performing the check, for use with
+// RegisterDependency. The connection pool of a sql check is kept open for the
+// life of the process.
+func (cc Check) Func() (func() bool, error) {
+	fn, _, err := cc.check()
+	return fn, err
+}
+
+// Options returns the DependencyOptions the check is registered with, for use
+// with RegisterDependency along with Func
+func (cc Check) Options() []health.DependencyOption {
+	var opts []health.DependencyOption
+	if cc.Interval > 0 {
+		opts = append(opts, health.WithInterval(time.Duration(cc.Interval)))
+	}
+	if cc.Timeout > 0 {
+		opts = append(opts, health.WithTimeout(time.Duration(cc.Timeout)))
+	}
+	if cc.FailureThreshold > 1 || cc.SuccessThreshold > 1 {
+		opts = append(opts, health.WithThreshold(cc.FailureThreshold, cc.SuccessThreshold))
+	}
+
+	return opts
+}
+
+// registeredAs returns whether the check is registered with the same level
+// and DependencyOptions as `other`, so only its function differs
+func (cc Check) registeredAs(other Check) bool {
+	return cc.Level == other.Level &&
+		cc.Interval == other.Interval &&
+		cc.Timeout == other.Timeout &&
+		cc.FailureThreshold == other.FailureThreshold &&
+		cc.SuccessThreshold == other.SuccessThreshold
+}
+
+// level parses the configured level
+func (cc Check) level() (health.Level, error) {
+	if cc.Level == "" {
+		return health.LevelSoft, nil
+	}
+
+	level, err := health.ParseLevel(cc.Level)
+	if err != nil {
+		return 0, fmt.Errorf("healthconfig: check %q: unknown level %q", cc.Name, cc.Level)
+	}
+	return level, nil
+}
+
+// check returns the function performing the configured type of check, along
+// with a func closing what it holds open, if anything
+func (cc Check) check() (func() bool, func() error, error) {
+	timeout := time.Duration(cc.Timeout)
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	if strings.TrimSpace(cc.Target) == "" {
+		return nil, nil, fmt.Errorf("healthconfig: check %q: no target", cc.Name)
+	}
+
+	switch cc.Type {
+	case "http":
+		client := &http.Client{Timeout: timeout}
+		return func() bool {
+			healthy, _ := health.Check200Helper(cc.Target, client)
+			return healthy
+		}, nil, nil
+
+	case "tcp":
+		return func() bool {
+			conn, err := net.DialTimeout("tcp", cc.Target, timeout)
+			if err != nil {
+				return false
+			}
+			conn.Close()
+			return true
+		}, nil, nil
+
+	case "sql":
+		db, err := sql.Open(cc.Driver, cc.Target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("healthconfig: check %q: %v", cc.Name, err)
+		}
+		return func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			return db.PingContext(ctx) == nil
+		}, db.Close, nil
+
+	case "redis":
+		return func() bool {
+			return redisPing(cc.Target, timeout)
+		}, nil, nil
+
+	case "exec":
+		args := strings.Fields(cc.Target)
+		return func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			return exec.CommandContext(ctx, args[0], args[1:]...).Run() == nil
+		}, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("healthconfig: check %q: unknown type %q", cc.Name, cc.Type)
+	}
+}
+
+// redisPing reports whether the Redis server at addr answers PING
+func redisPing(addr string, timeout time.Duration) bool {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return false
+	}
+
+	// ensure conn is closed when function returns
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		return false
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	return err == nil && line == "+PONG\r\n"
+}
@@ -0,0 +1,169 @@
+package healthconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const document = `{
+	"name": "billing",
+	"interval": "5s",
+	"checks": [
+		{"name": "db", "type": "tcp", "target": "db:5432", "level": "hard", "interval": "30s", "failure_threshold": 3}
+	]
+}`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "health.json"), []byte(document), 0600)
+	os.WriteFile(filepath.Join(dir, "health.yaml"), []byte(document), 0600)
+
+	config, err := Load(filepath.Join(dir, "health.json"))
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if config.Name != "billing" || time.Duration(config.Interval) != 5*time.Second {
+		t.Errorf("expected billing 5s got %s %v", config.Name, time.Duration(config.Interval))
+	}
+	if len(config.Checks) != 1 || config.Checks[0].FailureThreshold != 3 || time.Duration(config.Checks[0].Interval) != 30*time.Second {
+		t.Errorf("unexpected checks %+v", config.Checks)
+	}
+
+	if _, err := Load(filepath.Join(dir, "health.yaml")); err == nil {
+		t.Error("expected an error loading YAML without YAMLUnmarshal")
+	}
+
+	// JSON is valid YAML, so stands in for a YAML library here
+	YAMLUnmarshal = json.Unmarshal
+	defer func() { YAMLUnmarshal = nil }()
+	if config, err := Load(filepath.Join(dir, "health.yaml")); err != nil || config.Name != "billing" {
+		t.Errorf("expected billing <nil> got %v %v", config, err)
+	}
+}
+
+// fakeRedis answers every line with `reply`
+func fakeRedis(t *testing.T, reply string) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for i := 0; i < 3; i++ {
+					r.ReadString('\n')
+				}
+				conn.Write([]byte(reply))
+			}()
+		}
+	}()
+
+	return lis.Addr().String()
+}
+
+func TestServiceCheck(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer app.Close()
+
+	config := &Config{
+		Name: "billing",
+		Checks: []Check{
+			{Name: "app", Type: "http", Target: app.URL, Level: "hard"},
+			{Name: "db", Type: "tcp", Target: "127.0.0.1:1"},
+			{Name: "cache", Type: "redis", Target: fakeRedis(t, "+PONG\r\n")},
+			{Name: "locked", Type: "redis", Target: fakeRedis(t, "-NOAUTH Authentication required.\r\n")},
+			{Name: "script", Type: "exec", Target: "true"},
+		},
+	}
+
+	check, err := config.ServiceCheck()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := map[string]bool{"app": true, "db": false, "cache": true, "locked": false, "script": true}
+	for _, dependency := range check.DependencyStates() {
+		if dependency.Healthy != expected[dependency.Name] {
+			t.Errorf("expected %v got %v for %s", expected[dependency.Name], dependency.Healthy, dependency.Name)
+		}
+	}
+}
+
+func TestServiceCheckInvalid(t *testing.T) {
+	tests := []Check{
+		{Name: "a", Type: "smtp", Target: "mail:25"},
+		{Name: "b", Type: "tcp", Target: "db:5432", Level: "critical"},
+		{Name: "c", Type: "exec", Target: " "},
+		{Name: "d", Type: "sql", Driver: "missing", Target: "dsn"},
+	}
+
+	for _, test := range tests {
+		config := &Config{Name: "billing", Checks: []Check{test}}
+		if _, err := config.ServiceCheck(); err == nil {
+			t.Errorf("expected an error for %+v", test)
+		}
+	}
+}
+
+func TestServiceCheckThreshold(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "up")
+	os.WriteFile(marker, nil, 0600)
+
+	config := &Config{Name: "billing", Checks: []Check{
+		{Name: "script", Type: "exec", Target: "test -e " + marker, FailureThreshold: 2},
+	}}
+	check, err := config.ServiceCheck()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	script, _ := check.Dependency("script")
+
+	// ensure the threshold applies to the dependency itself
+	os.Remove(marker)
+	expected := []bool{true, false}
+	for i, healthy := range expected {
+		check.Update()
+		if script.IsHealthy() != healthy {
+			t.Errorf("expected %v got %v after %d failures", healthy, script.IsHealthy(), i+1)
+		}
+	}
+}
+
+func TestServiceCheckInterval(t *testing.T) {
+	dir := t.TempDir()
+	runs, script := filepath.Join(dir, "runs"), filepath.Join(dir, "check.sh")
+	os.WriteFile(script, []byte("echo >> "+runs+"\n"), 0600)
+	config := &Config{Name: "billing", Interval: Duration(5 * time.Millisecond), Checks: []Check{
+		{Name: "script", Type: "exec", Target: "sh " + script, Interval: Duration(time.Hour)},
+	}}
+	check, err := config.ServiceCheck()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	// ensure the check's cycles hold back the interval, it is only checked on
+	// registration and as the check starts
+	check.StartCheck()
+	time.Sleep(50 * time.Millisecond)
+	check.StopCheck()
+
+	b, _ := os.ReadFile(runs)
+	if n := len(b); n != 2 {
+		t.Errorf("expected 2 runs got %d", n)
+	}
+}
@@ -0,0 +1,247 @@
+package healthconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Reloader keeps a ServiceCheck in line with a config file at runtime. Checks
+// added to the file are registered, removed ones unregistered and retuned ones
+// swapped in place, keeping the state of the dependency. Changing the level,
+// interval, timeout or thresholds of a check replaces it. What a replaced
+// check holds open, such as the connection pool of a sql check, is closed.
+// The name and interval of the service are only read when the Reloader is
+// created. Use NewReloader to instantiate one
+type Reloader struct {
+	path  string
+	check *health.ServiceCheck
+
+	checks map[string]*reloadable
+	mu     sync.Mutex
+}
+
+// reloadable is a registered check whose function can be swapped
+type reloadable struct {
+	config Check
+	fn     atomic.Value
+	// close releases what the function holds open, such as the connection
+	// pool of a sql check, if anything
+	close func() error
+}
+
+func (r *reloadable) run() bool {
+	return r.fn.Load().(func() bool)()
+}
+
+// release closes what the function holds open, once it is no longer used
+func (r *reloadable) release() {
+	if r.close != nil {
+		r.close()
+	}
+}
+
+// NewReloader loads the config file at path and builds its ServiceCheck,
+// which isn't started
+func NewReloader(path string) (*Reloader, error) {
+	config, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := time.Duration(config.Interval)
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	check, err := health.InitialiseServiceCheck(config.Name, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{
+		path:   path,
+		check:  check,
+		checks: make(map[string]*reloadable),
+	}
+	if err := r.apply(config.Checks); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ServiceCheck returns the ServiceCheck kept in line with the config file
+func (r *Reloader) ServiceCheck() *health.ServiceCheck {
+	return r.check
+}
+
+// Reload reads the config file again and applies any changes. Nothing is
+// applied if the file is invalid.
+func (r *Reloader) Reload() error {
+	config, err := Load(r.path)
+	if err != nil {
+		return err
+	}
+
+	return r.apply(config.Checks)
+}
+
+// Watch reloads the config file whenever its modification time or size
+// changes, checking every `interval`, until ctx is cancelled. Errors from
+// reloading are passed to onError if it is set.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last, _ := os.Stat(r.path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(r.path)
+		if err != nil || (last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+			continue
+		}
+		last = info
+
+		if err := r.Reload(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// ReloadOnSignal reloads the config file whenever one of `signals`, SIGHUP if
+// none are given, is received, until ctx is cancelled. Errors from reloading
+// are passed to onError if it is set.
+func (r *Reloader) ReloadOnSignal(ctx context.Context, onError func(error), signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+		}
+
+		if err := r.Reload(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// apply diffs `checks` against those registered and applies the changes.
+// Every check is built and its name validated before anything is changed, so
+// nothing is applied if any is invalid.
+func (r *Reloader) apply(checks []Check) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	built := make(map[string]*reloadable, len(checks))
+	wanted := make(map[string]bool, len(checks))
+	err := r.build(checks, built, wanted)
+	if err != nil {
+		for _, rc := range built {
+			rc.release()
+		}
+		return err
+	}
+
+	for name, existing := range r.checks {
+		if !wanted[name] {
+			r.check.UnregisterDependency(name)
+			existing.release()
+			delete(r.checks, name)
+		}
+	}
+
+	for _, cc := range checks {
+		rc, changed := built[cc.Name]
+		if !changed {
+			continue
+		}
+
+		existing, ok := r.checks[cc.Name]
+		level, _ := cc.level()
+		switch {
+		case ok && existing.config.registeredAs(cc):
+			// retuned in place, keeping the state of the dependency
+			previous := existing.close
+			existing.config, existing.close = cc, rc.close
+			existing.fn.Store(rc.fn.Load())
+			rc = existing
+			if previous != nil {
+				previous()
+			}
+		case ok:
+			err = r.check.ReplaceDependency(cc.Name, level, rc.run, cc.Options()...)
+			if err == nil {
+				existing.release()
+			}
+		default:
+			_, err = r.check.RegisterDependency(cc.Name, level, rc.run, cc.Options()...)
+		}
+		if err != nil {
+			rc.release()
+			return fmt.Errorf("healthconfig: check %q: %v", cc.Name, err)
+		}
+		r.checks[cc.Name] = rc
+	}
+
+	return nil
+}
+
+// build validates `checks` and builds those which changed into `built`,
+// marking every name `wanted`
+func (r *Reloader) build(checks []Check, built map[string]*reloadable, wanted map[string]bool) error {
+	for _, cc := range checks {
+		if cc.Name == "" {
+			return fmt.Errorf("healthconfig: check %q: %v", cc.Name, health.ErrNoDependency)
+		}
+		if wanted[cc.Name] {
+			return fmt.Errorf("healthconfig: check %q: %v", cc.Name, health.ErrDependencyAlreadyRegistered)
+		}
+		wanted[cc.Name] = true
+
+		if _, err := cc.level(); err != nil {
+			return err
+		}
+
+		existing, ok := r.checks[cc.Name]
+		if ok && reflect.DeepEqual(existing.config, cc) {
+			continue
+		}
+		// ensure a new check doesn't clash with a dependency registered
+		// on the ServiceCheck otherwise
+		if _, err := r.check.Dependency(cc.Name); !ok && err == nil {
+			return fmt.Errorf("healthconfig: check %q: %v", cc.Name, health.ErrDependencyAlreadyRegistered)
+		}
+
+		fn, closer, err := cc.check()
+		if err != nil {
+			return err
+		}
+		rc := &reloadable{config: cc, close: closer}
+		rc.fn.Store(fn)
+		built[cc.Name] = rc
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package healthconfig
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestReloader(t *testing.T) {
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer lis.Close()
+	up := lis.Addr().String()
+
+	path := filepath.Join(t.TempDir(), "health.json")
+	write := func(checks string) {
+		os.WriteFile(path, []byte(`{"name": "billing", "checks": [`+checks+`]}`), 0600)
+	}
+
+	write(`{"name": "db", "type": "tcp", "target": "` + up + `", "level": "hard"},
+		{"name": "cache", "type": "tcp", "target": "` + up + `"}`)
+	r, err := NewReloader(path)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	check := r.ServiceCheck()
+	db, _ := check.Dependency("db")
+
+	// retune db, remove cache and add queue
+	write(`{"name": "db", "type": "tcp", "target": "127.0.0.1:1", "level": "hard"},
+		{"name": "queue", "type": "tcp", "target": "` + up + `"}`)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	var names []string
+	for _, dependency := range check.DependencyStates() {
+		names = append(names, dependency.Name)
+	}
+	if strings.Join(names, ",") != "db,queue" {
+		t.Errorf("expected db,queue got %v", names)
+	}
+	if retuned, _ := check.Dependency("db"); retuned != db {
+		t.Error("expected db to be retuned in place")
+	}
+
+	check.Update()
+	if check.IsHealthy() {
+		t.Error("expected the retuned db check to be used")
+	}
+
+	// an invalid file changes nothing
+	write(`{"name": "db", "type": "smtp", "target": "mail:25"}`)
+	if err := r.Reload(); err == nil {
+		t.Error("expected an error for an invalid file")
+	}
+	if len(check.DependencyStates()) != 2 {
+		t.Errorf("expected 2 dependencies got %d", len(check.DependencyStates()))
+	}
+}
+
+func TestReloaderWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.json")
+	os.WriteFile(path, []byte(`{"name": "billing", "checks": []}`), 0600)
+
+	r, err := NewReloader(path)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond, nil)
+
+	time.Sleep(20 * time.Millisecond)
+	os.WriteFile(path, []byte(`{"name": "billing", "checks": [{"name": "script", "type": "exec", "target": "true"}]}`), 0600)
+
+	for i := 0; i < 100 && len(r.ServiceCheck().DependencyStates()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(r.ServiceCheck().DependencyStates()) != 1 {
+		t.Error("expected the change to be picked up")
+	}
+}
+
+// openConns counts the connections of countingDriver left open
+var openConns atomic.Int64
+
+type countingDriver struct{}
+
+func (countingDriver) Open(name string) (driver.Conn, error) {
+	openConns.Add(1)
+	return countingConn{}, nil
+}
+
+type countingConn struct{}
+
+func (countingConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("unsupported") }
+func (countingConn) Begin() (driver.Tx, error)                 { return nil, errors.New("unsupported") }
+func (countingConn) Close() error {
+	openConns.Add(-1)
+	return nil
+}
+
+func init() {
+	sql.Register("healthconfig-counting", countingDriver{})
+}
+
+func TestReloaderClosesReplaced(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.json")
+	write := func(checks string) {
+		os.WriteFile(path, []byte(`{"name": "billing", "checks": [`+checks+`]}`), 0600)
+	}
+
+	write(`{"name": "db", "type": "sql", "driver": "healthconfig-counting", "target": "a"}`)
+	r, err := NewReloader(path)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	check := r.ServiceCheck()
+	check.Update()
+	if n := openConns.Load(); n != 1 {
+		t.Fatalf("expected 1 connection got %d", n)
+	}
+
+	// ensure each reload closes the pool of the check it replaces
+	for _, checks := range []string{
+		`{"name": "db", "type": "sql", "driver": "healthconfig-counting", "target": "b"}`,
+		`{"name": "db", "type": "sql", "driver": "healthconfig-counting", "target": "b", "level": "hard"}`,
+		`{"name": "queue", "type": "exec", "target": "true"}`,
+	} {
+		write(checks)
+		if err := r.Reload(); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		check.Update()
+		if n := openConns.Load(); n > 1 {
+			t.Errorf("expected at most 1 connection got %d", n)
+		}
+	}
+	if n := openConns.Load(); n != 0 {
+		t.Errorf("expected 0 connections got %d", n)
+	}
+}
+
+func TestReloaderAllOrNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.json")
+	write := func(checks string) {
+		os.WriteFile(path, []byte(`{"name": "billing", "checks": [`+checks+`]}`), 0600)
+	}
+
+	write(`{"name": "db", "type": "exec", "target": "true", "level": "hard"},
+		{"name": "cache", "type": "exec", "target": "true"}`)
+	r, err := NewReloader(path)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	check := r.ServiceCheck()
+	check.RegisterDependency("queue", health.LevelSoft, func() bool { return true })
+
+	tests := []string{
+		// a nameless check
+		`{"name": "db", "type": "exec", "target": "false", "level": "soft"},
+			{"name": "", "type": "exec", "target": "true"}`,
+		// a check clashing with a dependency registered otherwise
+		`{"name": "db", "type": "exec", "target": "false", "level": "soft"},
+			{"name": "queue", "type": "exec", "target": "true"}`,
+	}
+	for _, checks := range tests {
+		write(checks)
+		if err := r.Reload(); err == nil {
+			t.Errorf("expected an error for %s", checks)
+		}
+
+		var names []string
+		for _, dependency := range check.DependencyStates() {
+			names = append(names, dependency.Name+":"+dependency.Level.String())
+		}
+		if strings.Join(names, ",") != "db:hard,cache:soft,queue:soft" {
+			t.Errorf("expected nothing to be applied got %v", names)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// AssertHealthy reports an error on `t` unless `check` is healthy, returning
+// whether it is
+func AssertHealthy(t testing.TB, check *health.ServiceCheck) bool {
+	t.Helper()
+	if !check.IsHealthy() {
+		t.Errorf("expected %s to be healthy", check.Name)
+		return false
+	}
+	return true
+}
+
+// AssertUnhealthy reports an error on `t` unless `check` is unhealthy,
+// returning whether it is
+func AssertUnhealthy(t testing.TB, check *health.ServiceCheck) bool {
+	t.Helper()
+	if check.IsHealthy() {
+		t.Errorf("expected %s to be unhealthy", check.Name)
+		return false
+	}
+	return true
+}
+
+// AssertDependencyHealthy reports an error on `t` unless the named dependency
+// of `check` is registered and healthy, returning whether it is
+func AssertDependencyHealthy(t testing.TB, check *health.ServiceCheck, name string) bool {
+	t.Helper()
+	return assertDependency(t, check, name, true)
+}
+
+// AssertDependencyUnhealthy reports an error on `t` unless the named
+// dependency of `check` is registered and unhealthy, returning whether it is
+func AssertDependencyUnhealthy(t testing.TB, check *health.ServiceCheck, name string) bool {
+	t.Helper()
+	return assertDependency(t, check, name, false)
+}
+
+func assertDependency(t testing.TB, check *health.ServiceCheck, name string, healthy bool) bool {
+	t.Helper()
+	for _, dependency := range check.DependencyStates() {
+		if dependency.Name != name {
+			continue
+		}
+
+		if dependency.Healthy != healthy {
+			t.Errorf("expected dependency %s of %s to have healthy %v got %v", name, check.Name, healthy, dependency.Healthy)
+			return false
+		}
+		return true
+	}
+
+	t.Errorf("expected dependency %s to be registered on %s", name, check.Name)
+	return false
+}
+
+// AssertEventuallyHealthy waits up to `timeout` of real time for `check` to
+// become healthy, reporting an error on `t` if it doesn't. It doesn't check
+// the dependencies itself, so something must be running the check, such as
+// StartCheck.
+func AssertEventuallyHealthy(t testing.TB, check *health.ServiceCheck, timeout time.Duration) bool {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		// fetch the channel before reading the health so no change is missed
+		changed := check.Changed()
+		if check.IsHealthy() {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			t.Errorf("expected %s to be healthy within %v", check.Name, timeout)
+			return false
+		}
+	}
+}
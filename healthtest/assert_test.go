@@ -0,0 +1,72 @@
+package healthtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// recorder is a testing.TB recording errors rather than failing the test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", health.LevelSoft, func() bool { return false })
+
+	testCases := []struct {
+		name     string
+		assert   func(testing.TB) bool
+		expected bool
+	}{
+		{"healthy", func(t testing.TB) bool { return AssertHealthy(t, check) }, true},
+		{"unhealthy", func(t testing.TB) bool { return AssertUnhealthy(t, check) }, false},
+		{"dependency healthy", func(t testing.TB) bool { return AssertDependencyHealthy(t, check, "db") }, true},
+		{"dependency not healthy", func(t testing.TB) bool { return AssertDependencyHealthy(t, check, "cache") }, false},
+		{"dependency unhealthy", func(t testing.TB) bool { return AssertDependencyUnhealthy(t, check, "cache") }, true},
+		{"dependency not unhealthy", func(t testing.TB) bool { return AssertDependencyUnhealthy(t, check, "db") }, false},
+		{"dependency missing", func(t testing.TB) bool { return AssertDependencyUnhealthy(t, check, "queue") }, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			if got := tc.assert(r); got != tc.expected {
+				t.Errorf("expected %v got %v", tc.expected, got)
+			}
+			if (len(r.errors) == 0) != tc.expected {
+				t.Errorf("expected errors only on failure got %v", r.errors)
+			}
+		})
+	}
+}
+
+func TestAssertEventuallyHealthy(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", health.LevelHard, Flaky(false, false, true))
+	check.Update()
+
+	r := &recorder{TB: t}
+	if AssertEventuallyHealthy(r, check, 10*time.Millisecond) {
+		t.Error("expected to time out whilst nothing runs the check")
+	}
+	if len(r.errors) != 1 {
+		t.Errorf("expected 1 error got %v", r.errors)
+	}
+
+	go check.Update()
+	if !AssertEventuallyHealthy(t, check, time.Second) {
+		t.Error("expected to become healthy once checked")
+	}
+}
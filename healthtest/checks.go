@@ -0,0 +1,71 @@
+package healthtest
+
+import (
+	"sync"
+	"time"
+)
+
+// AlwaysHealthy returns a check which is always healthy
+func AlwaysHealthy() func() bool {
+	return func() bool { return true }
+}
+
+// AlwaysUnhealthy returns a check which is always unhealthy
+func AlwaysUnhealthy() func() bool {
+	return func() bool { return false }
+}
+
+// FailNTimesThenRecover returns a check which is unhealthy for its first `n`
+// calls and healthy from then on, for exercising thresholds and backoff.
+// Remember that RegisterDependency makes the first call.
+func FailNTimesThenRecover(n int) func() bool {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+
+	return func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return calls > n
+	}
+}
+
+// Flaky returns a check which reports each of `results` in turn, starting
+// again from the first once they are exhausted. With no results it is always
+// healthy.
+func Flaky(results ...bool) func() bool {
+	var (
+		mu sync.Mutex
+		i  int
+	)
+
+	return func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(results) == 0 {
+			return true
+		}
+
+		result := results[i%len(results)]
+		i++
+		return result
+	}
+}
+
+// Slow returns a check which takes `d` of real time before reporting
+// `healthy`, for exercising timeouts
+func Slow(d time.Duration, healthy bool) func() bool {
+	return func() bool {
+		time.Sleep(d)
+		return healthy
+	}
+}
+
+// Panics returns a check which panics with `v`
+func Panics(v interface{}) func() bool {
+	return func() bool {
+		panic(v)
+	}
+}
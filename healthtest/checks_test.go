@@ -0,0 +1,58 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlways(t *testing.T) {
+	if !AlwaysHealthy()() {
+		t.Error("expected healthy")
+	}
+	if AlwaysUnhealthy()() {
+		t.Error("expected unhealthy")
+	}
+}
+
+func TestFailNTimesThenRecover(t *testing.T) {
+	check := FailNTimesThenRecover(2)
+	expected := []bool{false, false, true, true}
+	for i, e := range expected {
+		if got := check(); got != e {
+			t.Errorf("expected %v got %v for call %d", e, got, i)
+		}
+	}
+}
+
+func TestFlaky(t *testing.T) {
+	check := Flaky(true, false)
+	expected := []bool{true, false, true, false}
+	for i, e := range expected {
+		if got := check(); got != e {
+			t.Errorf("expected %v got %v for call %d", e, got, i)
+		}
+	}
+
+	if !Flaky()() {
+		t.Error("expected a check without results to be healthy")
+	}
+}
+
+func TestSlow(t *testing.T) {
+	start := time.Now()
+	if Slow(10*time.Millisecond, false)() {
+		t.Error("expected unhealthy")
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("expected the check to take at least 10ms")
+	}
+}
+
+func TestPanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("expected boom got %v", r)
+		}
+	}()
+	Panics("boom")()
+}
@@ -0,0 +1,90 @@
+// Package healthtest helps test code wired up to a ServiceCheck without
+// goroutines or real time: a fake Clock, a Scheduler stepping the check one
+// cycle at a time, and canned check functions which misbehave on demand.
+//
+//	clock := healthtest.NewClock(time.Time{})
+//	check, _ := health.InitialiseServiceCheck("api", time.Minute, health.WithClock(clock))
+//	check.RegisterDependency("db", health.LevelHard, healthtest.Flaky(true, false))
+//
+//	s := healthtest.NewScheduler(check, clock, time.Minute)
+//	s.Tick()
+package healthtest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a health.Clock which only moves when advanced. Use NewClock to
+// instantiate one
+type Clock struct {
+	now     time.Time
+	waiters []waiter
+	mu      sync.Mutex
+}
+
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewClock returns a Clock set to `start`
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel which receives the time once the clock has been
+// advanced by at least `d`
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, waiter{c.now.Add(d), ch})
+	return ch
+}
+
+// Advance moves the clock on by `d`, firing every After which is due
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+// Waiters returns the number of calls to After which haven't fired yet
+func (c *Clock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until at least `n` calls to After are waiting, for
+// synchronising with code running in another goroutine, such as StartCheck,
+// before advancing the clock
+func (c *Clock) BlockUntil(n int) {
+	for c.Waiters() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,54 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+
+	soon, later := clock.After(time.Second), clock.After(time.Minute)
+	if clock.Waiters() != 2 {
+		t.Errorf("expected 2 waiters got %d", clock.Waiters())
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case now := <-soon:
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("expected %v got %v", start.Add(time.Second), now)
+		}
+	default:
+		t.Error("expected the first After to fire")
+	}
+
+	select {
+	case <-later:
+		t.Error("expected the second After not to fire yet")
+	default:
+	}
+
+	if clock.Waiters() != 1 {
+		t.Errorf("expected 1 waiter got %d", clock.Waiters())
+	}
+}
+
+func TestClockStartCheck(t *testing.T) {
+	clock := NewClock(time.Time{})
+	check, _ := health.InitialiseServiceCheck("api", time.Minute, health.WithClock(clock))
+	check.RegisterDependency("db", health.LevelHard, Flaky(true, false))
+
+	changed := check.Changed()
+	check.StartCheck()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-changed
+
+	if check.IsHealthy() {
+		t.Error("expected the second check to fail")
+	}
+}
@@ -0,0 +1,52 @@
+package healthtest
+
+import (
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Scheduler steps a ServiceCheck through its check cycles synchronously, in
+// place of StartCheck. Use NewScheduler to instantiate one
+type Scheduler struct {
+	check    *health.ServiceCheck
+	clock    *Clock
+	interval time.Duration
+	ticks    int
+}
+
+// NewScheduler returns a Scheduler for `check`. If `clock` is set, it should
+// be the check's clock and is advanced by `interval` before every cycle.
+func NewScheduler(check *health.ServiceCheck, clock *Clock, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		check:    check,
+		clock:    clock,
+		interval: interval,
+	}
+}
+
+// Tick runs one check cycle, returning whether the service is healthy
+// afterwards
+func (s *Scheduler) Tick() bool {
+	if s.clock != nil {
+		s.clock.Advance(s.interval)
+	}
+
+	s.check.Update()
+	s.ticks++
+	return s.check.IsHealthy()
+}
+
+// TickN runs `n` check cycles, returning whether the service is healthy
+// afterwards
+func (s *Scheduler) TickN(n int) bool {
+	for i := 0; i < n; i++ {
+		s.Tick()
+	}
+	return s.check.IsHealthy()
+}
+
+// Ticks returns the number of cycles run
+func (s *Scheduler) Ticks() int {
+	return s.ticks
+}
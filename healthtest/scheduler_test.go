@@ -0,0 +1,31 @@
+package healthtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestScheduler(t *testing.T) {
+	clock := NewClock(time.Time{})
+	check, _ := health.InitialiseServiceCheck("api", time.Minute, health.WithClock(clock))
+	// the first result is used at registration
+	check.RegisterDependency("db", health.LevelHard, Flaky(true, true, false))
+
+	s := NewScheduler(check, clock, time.Minute)
+	if !s.Tick() {
+		t.Error("expected to be healthy after the first tick")
+	}
+	if s.Tick() {
+		t.Error("expected to be unhealthy after the second tick")
+	}
+	if !check.LastChecked().Equal(time.Time{}.Add(2 * time.Minute)) {
+		t.Errorf("expected last checked at 2m got %v", check.LastChecked())
+	}
+
+	s.TickN(3)
+	if s.Ticks() != 5 {
+		t.Errorf("expected 5 ticks got %d", s.Ticks())
+	}
+}
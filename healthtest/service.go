@@ -0,0 +1,38 @@
+package healthtest
+
+import (
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Dependency is a dependency registered by NewServiceCheck, reporting Healthy
+type Dependency struct {
+	Name    string
+	Level   health.Level
+	Healthy bool
+}
+
+// Hard returns a hard dependency named `name` reporting `healthy`
+func Hard(name string, healthy bool) Dependency {
+	return Dependency{Name: name, Level: health.LevelHard, Healthy: healthy}
+}
+
+// Soft returns a soft dependency named `name` reporting `healthy`
+func Soft(name string, healthy bool) Dependency {
+	return Dependency{Name: name, Level: health.LevelSoft, Healthy: healthy}
+}
+
+// NewServiceCheck returns a ServiceCheck named `name` with `dependencies`
+// registered and checked, for tests of code serving or reporting its status
+//
+//	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true), healthtest.Soft("cache", false))
+func NewServiceCheck(name string, dependencies ...Dependency) *health.ServiceCheck {
+	check, _ := health.InitialiseServiceCheck(name, time.Second)
+	for _, dependency := range dependencies {
+		healthy := dependency.Healthy
+		check.RegisterDependency(dependency.Name, dependency.Level, func() bool { return healthy })
+	}
+	check.Update()
+	return check
+}
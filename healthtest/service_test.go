@@ -0,0 +1,36 @@
+package healthtest
+
+import (
+	"testing"
+
+	"github.com/fresh8/health"
+)
+
+func TestNewServiceCheck(t *testing.T) {
+	tests := []struct {
+		dependencies []Dependency
+		healthy      bool
+		status       health.Status
+	}{
+		{nil, true, health.StatusHealthy},
+		{[]Dependency{Hard("db", true), Soft("cache", false)}, true, health.StatusDegraded},
+		{[]Dependency{Hard("db", false), Soft("cache", true)}, false, health.StatusUnhealthy},
+	}
+
+	for _, test := range tests {
+		check := NewServiceCheck("api", test.dependencies...)
+		if healthy := check.IsHealthy(); healthy != test.healthy {
+			t.Errorf("expected %v got %v", test.healthy, healthy)
+		}
+		if status := check.Document().Status; status != test.status {
+			t.Errorf("expected %v got %v", test.status, status)
+		}
+		// ensure each dependency is registered as given
+		for i, dependency := range test.dependencies {
+			state := check.DependencyStates()[i]
+			if state.Name != dependency.Name || state.Level != dependency.Level || state.Healthy != dependency.Healthy {
+				t.Errorf("expected %+v got %+v", dependency, state)
+			}
+		}
+	}
+}
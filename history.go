@@ -0,0 +1,252 @@
+package health
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+)
+
+const (
+	// DefaultHistorySize is the number of results kept per dependency when
+	// HistoryConfig.Size is zero
+	DefaultHistorySize = 100
+)
+
+// CheckResult is the outcome of a single check of a dependency
+type CheckResult struct {
+	Time     time.Time     `json:"time"`
+	Healthy  bool          `json:"healthy"`
+	Duration time.Duration `json:"duration"`
+}
+
+// checkResultSize is the memory a CheckResult takes up in the history
+const checkResultSize = int(unsafe.Sizeof(CheckResult{}))
+
+// HistoryConfig bounds the results kept by WithHistory
+type HistoryConfig struct {
+	// Size is the most results kept per dependency, DefaultHistorySize if
+	// zero
+	Size int
+	// Budget is the most bytes of results kept across every dependency, or
+	// unlimited if zero. When it is reached each dependency keeps an equal
+	// share, at least one result.
+	Budget int
+	// Summary includes each dependency's uptimePercent and
+	// avgCheckDurationMs, over the results held, in the status document
+	Summary bool
+}
+
+// HistoryStats describes the results held by WithHistory
+type HistoryStats struct {
+	// Results is the number of results held
+	Results int
+	// Bytes is the memory the results take up
+	Bytes int
+	// Evictions is the number of results discarded to stay within the
+	// bounds
+	Evictions uint64
+}
+
+// WithHistory keeps the results of each dependency's checks after
+// registration, discarding the oldest to stay within `config`, so a flapping
+// dependency can't grow the process unbounded
+func WithHistory(config HistoryConfig) Option {
+	return func(s *ServiceCheck) {
+		if config.Size <= 0 {
+			config.Size = DefaultHistorySize
+		}
+		s.history = &history{config: config, rings: make(map[*Dependency]*ring)}
+	}
+}
+
+// HistoryStats returns the number of results held by WithHistory and how many
+// have been evicted, or the zero HistoryStats without it
+func (s *ServiceCheck) HistoryStats() HistoryStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.history == nil {
+		return HistoryStats{}
+	}
+
+	return s.history.stats()
+}
+
+// remember adds the result of checking `dep` to the history, if kept. s.mu
+// must be held, if only for reading
+func (s *ServiceCheck) remember(dep *Dependency) {
+	if s.history == nil {
+		return
+	}
+
+	s.history.add(dep, CheckResult{Time: dep.started, Healthy: dep.Healthy, Duration: dep.took})
+	if s.history.config.Summary {
+		summary := summarise(s.history.results(dep))
+		dep.summary = &summary
+	}
+}
+
+// forget discards the history and shared result of `dep` as it is no longer
+// registered, s.mu must be held
+func (s *ServiceCheck) forget(dep *Dependency) {
+	dep.releaseShared()
+	if s.history == nil {
+		return
+	}
+
+	s.history.forget(dep)
+}
+
+// history holds a bounded ring of results per dependency
+type history struct {
+	config HistoryConfig
+
+	mu        sync.Mutex
+	rings     map[*Dependency]*ring
+	total     int
+	evictions uint64
+}
+
+func (h *history) stats() HistoryStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return HistoryStats{
+		Results:   h.total,
+		Bytes:     h.total * checkResultSize,
+		Evictions: h.evictions,
+	}
+}
+
+// clone returns an empty history with the same bounds
+func (h *history) clone() *history {
+	if h == nil {
+		return nil
+	}
+
+	return &history{config: h.config, rings: make(map[*Dependency]*ring)}
+}
+
+// limit returns the most results kept per dependency
+func (h *history) limit() int {
+	limit := h.config.Size
+	if h.config.Budget > 0 && len(h.rings) > 0 {
+		if share := h.config.Budget / checkResultSize / len(h.rings); share < limit {
+			limit = share
+		}
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	return limit
+}
+
+func (h *history) add(dep *Dependency, result CheckResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rings[dep]
+	if !ok {
+		r = &ring{}
+		h.rings[dep] = r
+
+		// every dependency's share shrinks to make room
+		limit := h.limit()
+		for _, other := range h.rings {
+			h.evict(other, limit)
+			other.shrink(limit)
+		}
+	}
+
+	limit := h.limit()
+	h.evict(r, limit-1)
+	r.push(result, limit)
+	h.total++
+}
+
+// evict discards the oldest results of `r` until at most `keep` remain
+func (h *history) evict(r *ring, keep int) {
+	for r.n > keep {
+		r.pop()
+		h.total--
+		h.evictions++
+	}
+}
+
+func (h *history) forget(dep *Dependency) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r, ok := h.rings[dep]; ok {
+		h.total -= r.n
+		delete(h.rings, dep)
+	}
+}
+
+// results returns the history of `dep`, oldest first
+func (h *history) results(dep *Dependency) []CheckResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rings[dep]
+	if !ok {
+		return nil
+	}
+
+	return r.results()
+}
+
+// ring is a circular buffer of results which grows on demand
+type ring struct {
+	buf   []CheckResult
+	start int
+	n     int
+}
+
+// push appends `result`, growing the buffer up to `limit` results if it is
+// full. The caller makes room once it is at the limit.
+func (r *ring) push(result CheckResult, limit int) {
+	if r.n == len(r.buf) {
+		size := 2 * len(r.buf)
+		if size < 4 {
+			size = 4
+		}
+		if size > limit {
+			size = limit
+		}
+
+		buf := make([]CheckResult, size)
+		copy(buf, r.results())
+		r.buf, r.start = buf, 0
+	}
+
+	r.buf[(r.start+r.n)%len(r.buf)] = result
+	r.n++
+}
+
+// pop discards the oldest result
+func (r *ring) pop() {
+	r.buf[r.start] = CheckResult{}
+	r.start = (r.start + 1) % len(r.buf)
+	r.n--
+}
+
+// shrink releases the buffer beyond `limit` results, which mustn't be fewer
+// than those held
+func (r *ring) shrink(limit int) {
+	if len(r.buf) > limit {
+		buf := make([]CheckResult, limit)
+		copy(buf, r.results())
+		r.buf, r.start = buf, 0
+	}
+}
+
+// results returns a copy of the results, oldest first
+func (r *ring) results() []CheckResult {
+	results := make([]CheckResult, r.n)
+	for i := range results {
+		results[i] = r.buf[(r.start+i)%len(r.buf)]
+	}
+
+	return results
+}
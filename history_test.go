@@ -0,0 +1,87 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock), WithHistory(HistoryConfig{Size: 3}))
+	healthy := true
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		healthy = !healthy
+		return healthy
+	})
+
+	for i := 0; i < 5; i++ {
+		check.Update()
+	}
+
+	results := check.history.results(db)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results got %d", len(results))
+	}
+	for i, expected := range []bool{true, false, true} {
+		if results[i].Healthy != expected {
+			t.Errorf("expected %v got %v at %d", expected, results[i].Healthy, i)
+		}
+	}
+
+	stats := check.HistoryStats()
+	if stats.Results != 3 || stats.Evictions != 2 || stats.Bytes != 3*checkResultSize {
+		t.Errorf("expected 3 results and 2 evictions got %+v", stats)
+	}
+
+	check.UnregisterDependency("db")
+	if stats := check.HistoryStats(); stats.Results != 0 {
+		t.Errorf("expected 0 results got %d", stats.Results)
+	}
+}
+
+func TestHistoryBudget(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithHistory(HistoryConfig{
+		Size:   10,
+		Budget: 8 * checkResultSize,
+	}))
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	for i := 0; i < 10; i++ {
+		check.Update()
+	}
+	if results := check.history.results(db); len(results) != 8 {
+		t.Errorf("expected 8 results got %d", len(results))
+	}
+
+	// the budget is shared once another dependency is checked
+	cache, _ := check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+	check.Update()
+
+	for name, dep := range map[string]*Dependency{"db": db, "cache": cache} {
+		expected := 4
+		if name == "cache" {
+			expected = 1
+		}
+		if results := check.history.results(dep); len(results) != expected {
+			t.Errorf("expected %d results for %s got %d", expected, name, len(results))
+		}
+	}
+
+	stats := check.HistoryStats()
+	if stats.Bytes > 8*checkResultSize {
+		t.Errorf("expected at most %d bytes got %d", 8*checkResultSize, stats.Bytes)
+	}
+	if stats.Evictions != 2+5 {
+		t.Errorf("expected 7 evictions got %d", stats.Evictions)
+	}
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.Update()
+
+	if stats := check.HistoryStats(); stats != (HistoryStats{}) {
+		t.Errorf("expected no history got %+v", stats)
+	}
+}
@@ -0,0 +1,52 @@
+package health
+
+import "time"
+
+// WithInterval checks the dependency at most once every `interval` in the
+// check's cycles, reporting the last result in between, so expensive checks
+// such as a full database query can run less often than cheap ones. It is
+// rounded up to a whole number of cycles. Update still checks it on demand.
+func WithInterval(interval time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		d.interval = interval
+	}
+}
+
+// WithTimeout reports the dependency unhealthy if its check takes longer than
+// `timeout`, leaving the check to finish in the background. It overrides
+// WithCheckTimeout for checks taking a context.
+func WithTimeout(timeout time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		d.timeout = timeout
+	}
+}
+
+// intervalPassed returns whether the dependency's interval has passed, and so
+// it is due in a scheduled cycle every `duration`, counting the cycles it
+// waits. s.mu must be held.
+func (d *Dependency) intervalPassed(duration time.Duration) bool {
+	if d.interval <= duration || duration <= 0 || !d.checked {
+		return true
+	}
+
+	d.waited++
+	return time.Duration(d.waited)*duration >= d.interval
+}
+
+// timeout reports false if fn takes longer than `d`. fn is left to finish in
+// the background, and its result is discarded.
+func timeout(fn func() bool, d time.Duration, clock Clock) func() bool {
+	return func() bool {
+		result := make(chan bool, 1)
+		go func() {
+			result <- fn()
+		}()
+
+		select {
+		case healthy := <-result:
+			return healthy
+		case <-clock.After(d):
+			return false
+		}
+	}
+}
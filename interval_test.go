@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithInterval(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+
+	var runs int
+	check.RegisterDependency("db", LevelHard, func() bool {
+		runs++
+		return true
+	}, WithInterval(3*time.Second))
+
+	// the dependency is checked on registration, then every third cycle
+	expected := []int{1, 1, 2, 2, 2, 3}
+	for i, want := range expected {
+		check.scheduledUpdate()
+		if runs != want {
+			t.Errorf("expected %d runs got %d after cycle %d", want, runs, i+1)
+		}
+	}
+
+	// ensure Update checks it on demand
+	check.Update()
+	if runs != 4 {
+		t.Errorf("expected 4 runs got %d", runs)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithCheckTimeout(time.Minute))
+
+	release := make(chan struct{})
+	defer close(release)
+	start := time.Now()
+	slow, _ := check.RegisterDependency("slow", LevelHard, func() bool {
+		<-release
+		return true
+	}, WithTimeout(10*time.Millisecond))
+	ctx, _ := check.RegisterDependencyContext("ctx", LevelHard, func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	if slow.IsHealthy() || ctx.IsHealthy() {
+		t.Errorf("expected the slow dependencies to time out")
+	}
+	if got := check.Document().Dependencies[1].LastError; got != context.DeadlineExceeded.Error() {
+		t.Errorf("expected %v got %s", context.DeadlineExceeded, got)
+	}
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("expected the checks to time out got %v", took)
+	}
+}
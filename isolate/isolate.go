@@ -0,0 +1,246 @@
+// Package isolate runs risky health checks, such as those using cgo drivers
+// or executing probes, in a supervised child process, so a check which
+// crashes takes down the child rather than the service. The child is the
+// service's own binary executed again, which serves the checks registered
+// with Register over its stdin and a file descriptor of its own, so anything
+// the checks write to stdout, even from cgo, can't be mistaken for a result.
+// Passing the descriptor isn't supported on Windows.
+//
+//	func main() {
+//		isolate.Register("oracle", pingOracle)
+//		isolate.Main() // serves the checks and exits in the child
+//
+//		supervisor := &isolate.Supervisor{}
+//		defer supervisor.Close()
+//		check.RegisterDependency("oracle", health.LevelHard, supervisor.Check("oracle"))
+//		...
+//	}
+package isolate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long a check has to finish in the child unless set by
+// Supervisor.Timeout
+const DefaultTimeout = 5 * time.Second
+
+// ErrTimeout is the error of a check which didn't finish within the timeout
+var ErrTimeout = errors.New("isolate: check timed out")
+
+const (
+	// env is set to "1" in the environment of the child, telling Main to
+	// serve checks
+	env = "HEALTH_ISOLATE_CHILD"
+	// resultsEnv is the file descriptor the child writes its results to
+	resultsEnv = "HEALTH_ISOLATE_RESULTS_FD"
+)
+
+var (
+	checksMu sync.RWMutex
+	checks   = map[string]func() bool{}
+)
+
+// Register makes `check` available to run in the child as `name`. It must be
+// called in both processes before Main, for example at the start of main.
+func Register(name string, check func() bool) {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	checks[name] = check
+}
+
+// Main serves the registered checks and exits when called in a child started
+// by a Supervisor, and returns straight away otherwise. It should be called at
+// the start of main, once the checks are registered.
+func Main() {
+	if os.Getenv(env) != "1" {
+		return
+	}
+
+	fd, err := strconv.Atoi(os.Getenv(resultsEnv))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "isolate: no results file descriptor:", err)
+		os.Exit(1)
+	}
+
+	serve(os.Stdin, os.NewFile(uintptr(fd), "results"))
+	os.Exit(0)
+}
+
+// serve reads the name of a check per line from r, writing whether it was
+// healthy to w, until r is closed
+func serve(r io.Reader, w io.Writer) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		checksMu.RLock()
+		check, ok := checks[scanner.Text()]
+		checksMu.RUnlock()
+
+		result := "unhealthy\n"
+		if ok && check() {
+			result = "healthy\n"
+		}
+		if _, err := io.WriteString(w, result); err != nil {
+			return
+		}
+	}
+}
+
+// Supervisor runs checks in a child process, starting it on the first check
+// and again after it exits or a check times out. Checks are run one at a time.
+type Supervisor struct {
+	// Timeout is how long a check has to finish before the child is killed
+	// and the check reported unhealthy, DefaultTimeout if zero
+	Timeout time.Duration
+	// Command returns the command to start the child with, which must call
+	// Main. The service's own binary and arguments are used if nil.
+	Command func() *exec.Cmd
+
+	mu    sync.Mutex
+	child *child
+}
+
+// child is a running child process
+type child struct {
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	out     *bufio.Reader
+	results *os.File
+	exited  chan struct{}
+}
+
+// Check returns a check which runs the registered check `name` in the child.
+// It is unhealthy if the child crashes, times out or doesn't know the check.
+func (s *Supervisor) Check(name string) func() bool {
+	return func() bool {
+		healthy, _ := s.run(name)
+		return healthy
+	}
+}
+
+// Close kills the child, if it is running
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.child != nil {
+		s.child.kill()
+		s.child = nil
+	}
+
+	return nil
+}
+
+// run runs the check `name` in the child, starting it if need be
+func (s *Supervisor) run(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.child == nil {
+		c, err := s.start()
+		if err != nil {
+			return false, err
+		}
+		s.child = c
+	}
+
+	results := make(chan string, 1)
+	errs := make(chan error, 1)
+	go func(c *child) {
+		if _, err := io.WriteString(c.in, name+"\n"); err != nil {
+			errs <- err
+			return
+		}
+		result, err := c.out.ReadString('\n')
+		if err != nil {
+			errs <- err
+			return
+		}
+		results <- result
+	}(s.child)
+
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		return result == "healthy\n", nil
+	case err := <-errs:
+		// the child crashed, so start another for the next check
+		s.child.kill()
+		s.child = nil
+		return false, err
+	case <-timer.C:
+		s.child.kill()
+		s.child = nil
+		return false, ErrTimeout
+	}
+}
+
+// start starts the child
+func (s *Supervisor) start() (*child, error) {
+	var cmd *exec.Cmd
+	if s.Command != nil {
+		cmd = s.Command()
+	} else {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.Command(exe, os.Args[1:]...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	}
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	// Wait closes the pipes it makes as soon as the child exits, so the
+	// results are piped by hand to read them whilst waiting
+	out, w, err := os.Pipe()
+	if err != nil {
+		in.Close()
+		return nil, err
+	}
+	// extra files follow stdin, stdout and stderr
+	fd := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env+"=1", resultsEnv+"="+strconv.Itoa(fd))
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		in.Close()
+		out.Close()
+		return nil, err
+	}
+
+	c := &child{cmd: cmd, in: in, out: bufio.NewReader(out), results: out, exited: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(c.exited)
+	}()
+
+	return c, nil
+}
+
+// kill kills the child and waits for it to exit
+func (c *child) kill() {
+	c.in.Close()
+	c.cmd.Process.Kill()
+	<-c.exited
+	c.results.Close()
+}
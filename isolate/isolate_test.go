@@ -0,0 +1,70 @@
+package isolate
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	Register("healthy", func() bool { return true })
+	Register("unhealthy", func() bool { return false })
+	Register("noisy", func() bool {
+		fmt.Println("printed by the check")
+		return true
+	})
+	Register("raw", func() bool {
+		// written to fd 1 directly, as cgo would, bypassing os.Stdout
+		os.NewFile(1, "stdout").WriteString("unhealthy\n")
+		return true
+	})
+	Register("crash", func() bool {
+		os.Exit(2)
+		return true
+	})
+	Register("slow", func() bool {
+		time.Sleep(time.Minute)
+		return true
+	})
+	Main()
+
+	os.Exit(m.Run())
+}
+
+func TestSupervisor(t *testing.T) {
+	supervisor := &Supervisor{Timeout: time.Second}
+	defer supervisor.Close()
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"healthy", true},
+		{"unhealthy", false},
+		{"noisy", true},
+		{"raw", true},
+		{"missing", false},
+		{"crash", false},
+		// ensure the child is started again after crashing
+		{"healthy", true},
+	}
+
+	for _, test := range tests {
+		if got := supervisor.Check(test.name)(); got != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, got, test.name)
+		}
+	}
+}
+
+func TestSupervisorTimeout(t *testing.T) {
+	supervisor := &Supervisor{Timeout: 100 * time.Millisecond}
+	defer supervisor.Close()
+
+	if _, err := supervisor.run("slow"); err != ErrTimeout {
+		t.Errorf("expected %v got %v", ErrTimeout, err)
+	}
+	if healthy, err := supervisor.run("healthy"); !healthy || err != nil {
+		t.Errorf("expected true got %v (%v)", healthy, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package k8s provides dependency checks against the Kubernetes API, so a
+// downstream with zero ready pods is treated as a dependency failure before
+// requests to it start timing out.
+package k8s
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Timeout bounds every request made to the Kubernetes API by a check
+const Timeout = 2 * time.Second
+
+// Deployment returns a check which is healthy whilst the named Deployment has
+// at least one available replica
+func Deployment(client kubernetes.Interface, namespace, name string) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+
+		return deployment.Status.AvailableReplicas > 0
+	}
+}
+
+// Service returns a check which is healthy whilst the named Service has at
+// least one ready endpoint, according to its EndpointSlices
+func Service(client kubernetes.Interface, namespace, name string) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+
+		slices, err := client.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: "kubernetes.io/service-name=" + name,
+		})
+		if err != nil {
+			return false
+		}
+
+		for _, slice := range slices.Items {
+			for _, endpoint := range slice.Endpoints {
+				// a nil ready condition is to be interpreted as ready
+				if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+}
+
+// Endpoints returns a check which is healthy whilst the named Endpoints object
+// has at least one ready address, for clusters without EndpointSlices
+func Endpoints(client kubernetes.Interface, namespace, name string) func() bool {
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+
+		endpoints, err := client.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				return true
+			}
+		}
+
+		return false
+	}
+}
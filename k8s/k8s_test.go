@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeployment(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "up"},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "down"},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"up", true},
+		{"down", false},
+		{"missing", false},
+	}
+
+	for _, test := range tests {
+		if healthy := Deployment(client, "default", test.name)(); healthy != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, healthy, test.name)
+		}
+	}
+}
+
+func TestService(t *testing.T) {
+	ready, notReady := true, false
+	slice := func(service, name string, ready *bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels:    map[string]string{"kubernetes.io/service-name": service},
+			},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ready}},
+			},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		slice("up", "up-1", &notReady),
+		slice("up", "up-2", &ready),
+		slice("implicit", "implicit-1", nil),
+		slice("down", "down-1", &notReady),
+	)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"up", true},
+		{"implicit", true},
+		{"down", false},
+		{"missing", false},
+	}
+
+	for _, test := range tests {
+		if healthy := Service(client, "default", test.name)(); healthy != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, healthy, test.name)
+		}
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "up"},
+			Subsets: []corev1.EndpointSubset{
+				{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+			},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "down"},
+			Subsets: []corev1.EndpointSubset{
+				{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}},
+			},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"up", true},
+		{"down", false},
+		{"missing", false},
+	}
+
+	for _, test := range tests {
+		if healthy := Endpoints(client, "default", test.name)(); healthy != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, healthy, test.name)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package health
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// labelled runs `check` with pprof labels naming the service and the
+// dependency, so CPU and block profiles attribute the cost of the check, and
+// of any goroutines it starts, to the dependency
+func (d *Dependency) labelled(check func() bool) bool {
+	service := ""
+	if d.owner != nil {
+		service = d.owner.Name
+	}
+
+	var healthy bool
+	pprof.Do(context.Background(), pprof.Labels("health.service", service, "health.dependency", d.Name), func(context.Context) {
+		healthy = check()
+	})
+
+	return healthy
+}
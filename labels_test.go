@@ -0,0 +1,26 @@
+package health
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLabels(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	var profile bytes.Buffer
+	check.RegisterDependency("db", LevelHard, func() bool {
+		pprof.Lookup("goroutine").WriteTo(&profile, 1)
+		return true
+	})
+	check.Update()
+
+	for _, label := range []string{`"health.service":"api"`, `"health.dependency":"db"`} {
+		if !strings.Contains(profile.String(), label) {
+			t.Errorf("expected the profile to contain %s", label)
+		}
+	}
+}
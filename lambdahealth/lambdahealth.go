@@ -0,0 +1,70 @@
+// Package lambdahealth exposes the status of a ServiceCheck through an AWS
+// Lambda handler for API Gateway, without depending on the AWS SDK. Request
+// and Response are JSON compatible with the REST (v1) and HTTP (v2) API proxy
+// integrations, so the handlers can be passed straight to lambda.Start:
+//
+//	lambda.Start(lambdahealth.OnDemandHandler(check, 30*time.Second))
+package lambdahealth
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Request is the subset of an API Gateway proxy request used by the handlers
+type Request struct {
+	HTTPMethod string `json:"httpMethod"`
+	Path       string `json:"path"`
+	RawPath    string `json:"rawPath"`
+}
+
+// Response is an API Gateway proxy response
+type Response struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// HandlerFunc is the signature of a Lambda handler for API Gateway
+type HandlerFunc func(ctx context.Context, req Request) (Response, error)
+
+// Handler returns a HandlerFunc responding with the status of `check` as of
+// its last check, for functions which also run StartCheck
+func Handler(check *health.ServiceCheck) HandlerFunc {
+	return func(ctx context.Context, req Request) (Response, error) {
+		return respond(check)
+	}
+}
+
+// OnDemandHandler returns a HandlerFunc which checks the dependencies during
+// the invocation when they were last checked more than `maxAge` ago, so no
+// background goroutine is needed between invocations. A `maxAge` of zero
+// checks on every invocation.
+func OnDemandHandler(check *health.ServiceCheck, maxAge time.Duration) HandlerFunc {
+	return func(ctx context.Context, req Request) (Response, error) {
+		if time.Since(check.LastChecked()) >= maxAge {
+			check.Update()
+		}
+
+		return respond(check)
+	}
+}
+
+// respond encodes the status of `check` with the relevant response code
+func respond(check *health.ServiceCheck) (Response, error) {
+	var body bytes.Buffer
+	statusCode, err := check.WriteResponse(&body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		StatusCode: statusCode,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       body.String(),
+	}, nil
+}
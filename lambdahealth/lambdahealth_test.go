@@ -0,0 +1,95 @@
+package lambdahealth
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		healthy  bool
+		expected int
+	}{
+		{true, 200},
+		{false, 503},
+	}
+
+	for _, test := range tests {
+		resp, err := Handler(&health.ServiceCheck{Name: "fn", Healthy: test.healthy})(context.Background(), Request{})
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if resp.StatusCode != test.expected {
+			t.Errorf("expected %v got %v", test.expected, resp.StatusCode)
+		}
+		if !strings.Contains(resp.Body, `"name":"fn"`) {
+			t.Errorf("expected status document got %s", resp.Body)
+		}
+	}
+}
+
+func TestHandlerStatusCode(t *testing.T) {
+	tests := []struct {
+		options  []health.Option
+		drain    bool
+		expected int
+	}{
+		{nil, true, 503},
+		{[]health.Option{health.WithUnhealthyStatusCode(429)}, true, 429},
+		{[]health.Option{health.WithDegradedStatusCode(207)}, false, 207},
+	}
+
+	for _, test := range tests {
+		check, _ := health.InitialiseServiceCheck("fn", time.Minute, test.options...)
+		check.RegisterDependency("cache", health.LevelSoft, func() bool { return false })
+		check.Update()
+		if test.drain {
+			check.Drain()
+		}
+
+		// ensure the response code matches HTTPHandler's
+		resp, err := Handler(check)(context.Background(), Request{})
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if resp.StatusCode != test.expected {
+			t.Errorf("expected %v got %v", test.expected, resp.StatusCode)
+		}
+	}
+}
+
+func TestOnDemandHandler(t *testing.T) {
+	var calls int32
+	check, _ := health.InitialiseServiceCheck("fn", 0)
+	check.RegisterDependency("db", health.LevelHard, func() bool {
+		return atomic.AddInt32(&calls, 1) > 1
+	})
+
+	h := OnDemandHandler(check, time.Minute)
+
+	resp, _ := h(context.Background(), Request{})
+	if resp.StatusCode != 200 {
+		t.Errorf("expected 200 got %v", resp.StatusCode)
+	}
+	if calls != 2 {
+		t.Errorf("expected the check to run on the first invocation got %d calls", calls)
+	}
+
+	h(context.Background(), Request{})
+	if calls != 2 {
+		t.Errorf("expected the result to be reused within maxAge got %d calls", calls)
+	}
+}
+
+func TestResponseJSON(t *testing.T) {
+	b, _ := json.Marshal(Response{StatusCode: 200, Body: "{}"})
+	if !strings.Contains(string(b), `"statusCode":200`) || !strings.Contains(string(b), `"isBase64Encoded":false`) {
+		t.Errorf("expected an API Gateway response got %s", b)
+	}
+}
@@ -0,0 +1,18 @@
+package health
+
+import "time"
+
+// WithMaxLatency marks the dependency unhealthy, with ErrSlowCheck, whenever
+// its check takes longer than `max`, even if it passes, so slow dependencies
+// are spotted before they fail. Unlike WithTimeout the check isn't cut short.
+func WithMaxLatency(max time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		d.maxLatency = max
+	}
+}
+
+// tooSlow returns whether a check which took `took` exceeded the dependency's
+// maximum latency, see WithMaxLatency
+func (d *Dependency) tooSlow(took time.Duration) bool {
+	return d.maxLatency > 0 && took > d.maxLatency
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithMaxLatency(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock), WithCheckTimes())
+
+	var latency time.Duration
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		clock.advance(latency, 0)
+		return true
+	}, WithMaxLatency(time.Second))
+
+	tests := []struct {
+		latency  time.Duration
+		expected bool
+		err      string
+	}{
+		{500 * time.Millisecond, true, ""},
+		{time.Second, true, ""},
+		{1500 * time.Millisecond, false, ErrSlowCheck.Error()},
+		{100 * time.Millisecond, true, ""},
+	}
+
+	for _, test := range tests {
+		latency = test.latency
+		check.Update()
+
+		doc := check.Document().Dependencies[0]
+		if db.IsHealthy() != test.expected || doc.LastError != test.err {
+			t.Errorf("expected %v %q got %v %q", test.expected, test.err, db.IsHealthy(), doc.LastError)
+		}
+		expected := float64(test.latency) / float64(time.Millisecond)
+		if doc.LatencyMs == nil || *doc.LatencyMs != expected {
+			t.Errorf("expected %v got %v", expected, doc.LatencyMs)
+		}
+	}
+}
+
+func TestLatencyOnlyWithCheckTimes(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	doc := check.Document().Dependencies[0]
+	if doc.LatencyMs != nil {
+		t.Errorf("expected no latency got %v", *doc.LatencyMs)
+	}
+	// ensure the times are included regardless
+	if doc.LastChecked.IsZero() {
+		t.Error("expected lastChecked to be included")
+	}
+}
+
+func TestWithMaxLatencySpread(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock))
+	slow := false
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		if slow {
+			clock.advance(time.Second, 0)
+		}
+		return true
+	}, WithMaxLatency(100*time.Millisecond))
+
+	// ensure a slow pass checked on its own recomputes the service's health
+	slow = true
+	check.checkDependency(db)
+	if db.IsHealthy() || check.IsHealthy() {
+		t.Errorf("expected db and the service to be unhealthy got %v and %v", db.IsHealthy(), check.IsHealthy())
+	}
+}
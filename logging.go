@@ -0,0 +1,52 @@
+package health
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithLogger logs to `logger` when a dependency's check fails, at warn, when
+// it recovers, at info, and when it panics, at error, with the dependency's
+// name, level, how long the check took and the error it failed with
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *ServiceCheck) {
+		s.logger = logger
+	}
+}
+
+// logger returns the logger of the ServiceCheck the dependency is registered
+// on, or nil if there is none
+func (d *Dependency) logger() *slog.Logger {
+	if d.owner == nil {
+		return nil
+	}
+	return d.owner.logger
+}
+
+// logAttrs returns the attributes describing a check of the dependency
+func (d *Dependency) logAttrs(took time.Duration) []interface{} {
+	attrs := []interface{}{
+		slog.String("service", d.owner.Name),
+		slog.String("dependency", d.Name),
+		// "level" is the level of the log entry
+		slog.String("dependencyLevel", d.Level.String()),
+		slog.Duration("duration", took),
+	}
+	if d.LastError != "" {
+		attrs = append(attrs, slog.String("error", d.LastError))
+	}
+	return attrs
+}
+
+// logResult logs the dependency failing or recovering, given its health
+// before the check and whether it had been checked before
+func (d *Dependency) logResult(previous, checked bool, took time.Duration) {
+	logger := d.logger()
+	switch {
+	case logger == nil:
+	case !d.Healthy && (previous || !checked):
+		logger.Warn("health check failed", d.logAttrs(took)...)
+	case d.Healthy && !previous && checked:
+		logger.Info("health check recovered", d.logAttrs(took)...)
+	}
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithLogger(logger))
+
+	healthy := false
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+	check.Update()
+	healthy = true
+	check.Update()
+	check.Update()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	// the failure on registration is logged once, then the recovery
+	expected := []struct{ level, msg string }{
+		{"WARN", "health check failed"},
+		{"INFO", "health check recovered"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries got %d: %s", len(expected), len(entries), buf.String())
+	}
+	for i, e := range expected {
+		if entries[i]["level"] != e.level || entries[i]["msg"] != e.msg {
+			t.Errorf("expected %s %s got %v", e.level, e.msg, entries[i])
+		}
+		if entries[i]["service"] != "api" || entries[i]["dependency"] != "db" || entries[i]["dependencyLevel"] != "hard" {
+			t.Errorf("expected the dependency's attributes got %v", entries[i])
+		}
+	}
+}
+
+func TestWithLoggerPanic(t *testing.T) {
+	var buf bytes.Buffer
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+	check.RegisterDependency("db", LevelHard, func() bool { panic("boom") })
+
+	if !strings.Contains(buf.String(), `"msg":"health check panicked"`) || !strings.Contains(buf.String(), `"panic":"boom"`) {
+		t.Errorf("expected the panic to be logged got %s", buf.String())
+	}
+}
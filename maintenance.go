@@ -0,0 +1,61 @@
+package health
+
+// SetMaintenanceMode marks the service as under maintenance, or not, so whilst
+// it is HTTPHandler responds 503 and load balancers drain it, whilst IsHealthy
+// is unaffected. Unlike Drain it can be undone.
+func (s *ServiceCheck) SetMaintenanceMode(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Maintenance != on {
+		s.Maintenance = on
+		s.notifyChanged()
+	}
+}
+
+// InMaintenance returns a bool whether the service is under maintenance, see
+// SetMaintenanceMode
+func (s *ServiceCheck) InMaintenance() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Maintenance
+}
+
+// ForceDependencyState reports the named dependency as `healthy` regardless of
+// its checks until ClearOverride is called, such as to keep a known flaky
+// dependency from failing the service. The dependency is still checked, and
+// is flagged as overridden in the status document.
+func (s *ServiceCheck) ForceDependencyState(name string, healthy bool) error {
+	dependency, err := s.Dependency(name)
+	if err != nil {
+		return err
+	}
+
+	dependency.lock()
+	defer dependency.unlock()
+	if dependency.Override == nil {
+		dependency.actual = dependency.Healthy
+	}
+	changed := dependency.Healthy != healthy
+	dependency.Override, dependency.Healthy = &healthy, healthy
+	s.recordHealth(changed)
+	return nil
+}
+
+// ClearOverride reports the named dependency as per its checks again, after
+// ForceDependencyState
+func (s *ServiceCheck) ClearOverride(name string) error {
+	dependency, err := s.Dependency(name)
+	if err != nil {
+		return err
+	}
+
+	dependency.lock()
+	defer dependency.unlock()
+	if dependency.Override == nil {
+		return nil
+	}
+	changed := dependency.Healthy != dependency.actual
+	dependency.Override, dependency.Healthy = nil, dependency.actual
+	s.recordHealth(changed)
+	return nil
+}
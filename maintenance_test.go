@@ -0,0 +1,87 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetMaintenanceMode(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.Update()
+
+	check.SetMaintenanceMode(true)
+	if !check.InMaintenance() || check.IsServing() || !check.IsHealthy() {
+		t.Errorf("expected a healthy service not serving under maintenance")
+	}
+
+	w := httptest.NewRecorder()
+	check.HTTPHandler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 got %d", w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"maintenance":true`) || !strings.Contains(body, `"message":"maintenance, healthy"`) {
+		t.Errorf("expected maintenance to be flagged got %s", body)
+	}
+
+	check.SetMaintenanceMode(false)
+	if check.InMaintenance() || !check.IsServing() {
+		t.Errorf("expected the service to be serving after maintenance")
+	}
+}
+
+func TestForceDependencyState(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	healthy := false
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+	check.Update()
+
+	if err := check.ForceDependencyState("db", true); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if !db.IsHealthy() || !check.IsHealthy() {
+		t.Errorf("expected the forced dependency to be healthy")
+	}
+
+	// ensure the checks don't replace the forced state
+	check.Update()
+	if !db.IsHealthy() || !check.IsHealthy() {
+		t.Errorf("expected the forced dependency to stay healthy")
+	}
+	doc := check.Document()
+	if o := doc.Dependencies[0].Override; o == nil || !*o {
+		t.Errorf("expected the override to be flagged got %v", o)
+	}
+
+	if err := check.ClearOverride("db"); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if db.IsHealthy() || check.IsHealthy() {
+		t.Errorf("expected the checked state after clearing the override")
+	}
+	if o := check.Document().Dependencies[0].Override; o != nil {
+		t.Errorf("expected no override got %v", *o)
+	}
+
+	// the checks carried on whilst the dependency was forced
+	if err := check.ForceDependencyState("db", false); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	healthy = true
+	check.Update()
+	check.ClearOverride("db")
+	if !db.IsHealthy() {
+		t.Errorf("expected the dependency's last check to be restored")
+	}
+
+	if err := check.ForceDependencyState("cache", true); !errors.Is(err, ErrNoDependency) {
+		t.Errorf("expected %v got %v", ErrNoDependency, err)
+	}
+	if err := check.ClearOverride("cache"); !errors.Is(err, ErrNoDependency) {
+		t.Errorf("expected %v got %v", ErrNoDependency, err)
+	}
+}
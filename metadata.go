@@ -0,0 +1,46 @@
+package health
+
+import (
+	"maps"
+	"os"
+	"runtime/debug"
+)
+
+// Metadata describes the running instance of a service, included in its
+// status document as metadata, so a fleet's documents say which build is
+// running where
+type Metadata struct {
+	Version     string            `json:"version,omitempty"`
+	Revision    string            `json:"revision,omitempty"`
+	Hostname    string            `json:"hostname,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+// WithMetadata includes `metadata` in the status document
+func WithMetadata(metadata Metadata) Option {
+	return func(s *ServiceCheck) {
+		metadata.Extra = maps.Clone(metadata.Extra)
+		s.metadata = &metadata
+	}
+}
+
+// BuildMetadata returns the Metadata of the running binary: the version of
+// its main module, the VCS revision it was built from, and the hostname, each
+// left empty if unknown. The environment is left for the caller to set.
+func BuildMetadata() Metadata {
+	var metadata Metadata
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Version != "(devel)" {
+			metadata.Version = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				metadata.Revision = setting.Value
+			}
+		}
+	}
+	metadata.Hostname, _ = os.Hostname()
+
+	return metadata
+}
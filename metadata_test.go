@@ -0,0 +1,56 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMetadata(t *testing.T) {
+	extra := map[string]string{"region": "eu-west-1"}
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock), WithMetadata(Metadata{
+		Version:     "v1.2.3",
+		Revision:    "abc123",
+		Environment: "production",
+		Extra:       extra,
+	}))
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	// ensure the caller's map can't change the document
+	extra["region"] = "us-east-1"
+
+	var b bytes.Buffer
+	if err := check.WriteStatus(&b); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := `{"schemaVersion":1,"name":"api","healthy":true,"status":"healthy","dependencies":[{"name":"db","healthy":true,"level":"hard","lastChecked":"2020-01-01T00:00:00Z","lastSuccess":"2020-01-01T00:00:00Z"}],"message":"healthy","metadata":{"version":"v1.2.3","revision":"abc123","environment":"production","extra":{"region":"eu-west-1"}}}`
+	if got := strings.TrimSpace(b.String()); got != expected {
+		t.Errorf("expected %s got %s", expected, got)
+	}
+
+	// ensure the streamed document matches the encoded one
+	encoded, err := json.Marshal(check.Document())
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if string(encoded) != expected {
+		t.Errorf("expected %s got %s", expected, encoded)
+	}
+}
+
+func TestBuildMetadata(t *testing.T) {
+	hostname, _ := os.Hostname()
+
+	metadata := BuildMetadata()
+	if metadata.Hostname != hostname {
+		t.Errorf("expected %s got %s", hostname, metadata.Hostname)
+	}
+	if metadata.Environment != "" {
+		t.Errorf("expected no environment got %s", metadata.Environment)
+	}
+}
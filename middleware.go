@@ -0,0 +1,50 @@
+package health
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultShedExempt are the paths served regardless of health by
+// ShedMiddleware when no exemptions are given, so probes keep working whilst
+// traffic is being shed, including the routes Mount registers at the root
+var DefaultShedExempt = []string{"/health", "/healthz", "/readyz", "/livez"}
+
+// ShedMiddleware returns middleware which responds 503 Service Unavailable,
+// with a Retry-After header of `retryAfter`, whilst the service isn't ready, see
+// IsReady, so a service that knows it's broken stops accepting work. `exempt` lists paths,
+// or path prefixes ending in "/", which are passed through regardless;
+// DefaultShedExempt is used if none are given.
+func (s *ServiceCheck) ShedMiddleware(retryAfter time.Duration, exempt ...string) func(http.Handler) http.Handler {
+	if len(exempt) == 0 {
+		exempt = DefaultShedExempt
+	}
+	retryAfterSeconds := strconv.Itoa(int((retryAfter + time.Second - 1) / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.IsReady() || isExemptPath(r.URL.Path, exempt) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if retryAfter > 0 {
+				w.Header().Set("Retry-After", retryAfterSeconds)
+			}
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		})
+	}
+}
+
+// isExemptPath reports whether `path` matches one of the exemptions
+func isExemptPath(path string, exempt []string) bool {
+	for _, e := range exempt {
+		if path == e || (strings.HasSuffix(e, "/") && strings.HasPrefix(path, e)) {
+			return true
+		}
+	}
+
+	return false
+}
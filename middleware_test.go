@@ -0,0 +1,62 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShedMiddleware(t *testing.T) {
+	check := &ServiceCheck{Name: "test"}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	})
+
+	tests := []struct {
+		path       string
+		healthy    bool
+		exempt     []string
+		expected   int
+		retryAfter string
+	}{
+		{"/orders", true, nil, 200, ""},
+		{"/orders", false, nil, 503, "2"},
+		{"/health", false, nil, 200, ""},
+		{"/readyz", false, nil, 200, ""},
+		{"/livez", false, nil, 200, ""},
+		{"/health", false, []string{"/admin/"}, 503, "2"},
+		{"/admin/drain", false, []string{"/admin/"}, 200, ""},
+		{"/administrator", false, []string{"/admin/"}, 503, "2"},
+	}
+
+	for _, test := range tests {
+		check.Healthy = test.healthy
+		h := check.ShedMiddleware(1500*time.Millisecond, test.exempt...)(ok)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, w.Code, test.path)
+		}
+		if got := w.Header().Get("Retry-After"); got != test.retryAfter {
+			t.Errorf("expected %q got %q for %s", test.retryAfter, got, test.path)
+		}
+	}
+}
+
+func TestShedMiddlewareDraining(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", time.Minute)
+	h := check.ShedMiddleware(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	check.Drain()
+
+	// ensure traffic is shed once the service isn't ready, though it's healthy
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/orders", nil))
+	if w.Code != 503 {
+		t.Errorf("expected %v got %v", 503, w.Code)
+	}
+}
@@ -0,0 +1,137 @@
+// Package nagios formats the health of a ServiceCheck following the Nagios
+// plugin conventions, status text, exit codes and perfdata, so existing
+// Nagios/Icinga estates can consume it directly.
+package nagios
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fresh8/health"
+)
+
+// defaultClient is used by Check when no client is supplied
+var defaultClient = health.NewHTTPClient()
+
+// Code is a Nagios plugin exit code
+type Code int
+
+// Nagios plugin exit codes
+const (
+	OK       Code = 0
+	Warning  Code = 1
+	Critical Code = 2
+	Unknown  Code = 3
+)
+
+// String returns the Nagios name of the code
+func (c Code) String() string {
+	switch c {
+	case OK:
+		return "OK"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Result is the outcome of a check in Nagios terms
+type Result struct {
+	Code Code
+	// Output is the single line of plugin output, including perfdata
+	Output string
+}
+
+// Evaluate returns the result for `check`: critical whilst unhealthy, warning
+// whilst soft dependencies fail and OK otherwise. Every dependency is included
+// in the perfdata as 1 when healthy and 0 when not.
+func Evaluate(check *health.ServiceCheck) Result {
+	return evaluate(check.Name, check.IsHealthy(), check.DependencyStates())
+}
+
+// Check fetches the status document served by the ServiceCheck at url and
+// evaluates it as per Evaluate. Failures to fetch or decode the document are
+// unknown. Function supports passing an optional *http.Client to use a
+// different timeout for the check.
+func Check(ctx context.Context, url string, optionalClient ...*http.Client) Result {
+	client := defaultClient
+	if len(optionalClient) > 0 {
+		client = optionalClient[0]
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return unknown(err)
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return unknown(err)
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	// unhealthy services respond with a 503 but still include the document
+	var doc struct {
+		Name         string              `json:"name"`
+		Healthy      bool                `json:"healthy"`
+		Dependencies []health.Dependency `json:"dependencies"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return unknown(fmt.Errorf("decoding response with status code %d: %v", resp.StatusCode, err))
+	}
+
+	return evaluate(doc.Name, doc.Healthy, doc.Dependencies)
+}
+
+func evaluate(name string, healthy bool, dependencies []health.Dependency) Result {
+	var hard, soft, perfdata []string
+	for _, dependency := range dependencies {
+		value := 1
+		if !dependency.Healthy {
+			value = 0
+			// informational dependencies only show in the perfdata
+			switch dependency.Level {
+			case health.LevelHard:
+				hard = append(hard, dependency.Name)
+			case health.LevelSoft:
+				soft = append(soft, dependency.Name)
+			}
+		}
+		perfdata = append(perfdata, fmt.Sprintf("'%s'=%d;;;0;1", strings.Replace(dependency.Name, "'", "''", -1), value))
+	}
+
+	var (
+		code    = OK
+		summary = "all dependencies healthy"
+	)
+	switch {
+	case !healthy:
+		code = Critical
+		summary = "hard dependencies failing: " + strings.Join(hard, ", ")
+		if len(hard) == 0 {
+			summary = "service unhealthy"
+		}
+	case len(soft) > 0:
+		code = Warning
+		summary = "soft dependencies failing: " + strings.Join(soft, ", ")
+	}
+
+	output := fmt.Sprintf("%s %s - %s", strings.ToUpper(name), code, summary)
+	if len(perfdata) > 0 {
+		output += " | " + strings.Join(perfdata, " ")
+	}
+
+	return Result{Code: code, Output: output}
+}
+
+func unknown(err error) Result {
+	return Result{Code: Unknown, Output: "HEALTH UNKNOWN - " + err.Error()}
+}
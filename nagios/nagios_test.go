@@ -0,0 +1,62 @@
+package nagios
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fresh8/health"
+	"github.com/fresh8/health/healthtest"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		hard, soft     bool
+		expectedCode   Code
+		expectedOutput string
+	}{
+		{true, true, OK, "API OK - all dependencies healthy | 'db'=1;;;0;1 'cache'=1;;;0;1"},
+		{true, false, Warning, "API WARNING - soft dependencies failing: cache | 'db'=1;;;0;1 'cache'=0;;;0;1"},
+		{false, false, Critical, "API CRITICAL - hard dependencies failing: db | 'db'=0;;;0;1 'cache'=0;;;0;1"},
+	}
+
+	for _, test := range tests {
+		check := healthtest.NewServiceCheck("api", healthtest.Hard("db", test.hard), healthtest.Soft("cache", test.soft))
+		result := Evaluate(check)
+		if result.Code != test.expectedCode {
+			t.Errorf("expected %v got %v", test.expectedCode, result.Code)
+		}
+		if result.Output != test.expectedOutput {
+			t.Errorf("expected %q got %q", test.expectedOutput, result.Output)
+		}
+	}
+}
+
+func TestEvaluateInformational(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true), healthtest.Dependency{Name: "audit", Level: health.LevelInformational})
+
+	// ensure a failing informational dependency is neither critical nor a warning
+	result := Evaluate(check)
+	if result.Code != OK {
+		t.Errorf("expected %v got %v", OK, result.Code)
+	}
+	expected := "API OK - all dependencies healthy | 'db'=1;;;0;1 'audit'=0;;;0;1"
+	if result.Output != expected {
+		t.Errorf("expected %q got %q", expected, result.Output)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", false), healthtest.Soft("cache", true))
+	server := httptest.NewServer(http.HandlerFunc(check.HTTPHandler))
+	defer server.Close()
+
+	if result := Check(context.Background(), server.URL); result.Code != Critical {
+		t.Errorf("expected %v got %v (%s)", Critical, result.Code, result.Output)
+	}
+
+	if result := Check(context.Background(), "http://127.0.0.1:0"); result.Code != Unknown {
+		t.Errorf("expected %v got %v (%s)", Unknown, result.Code, result.Output)
+	}
+}
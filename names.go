@@ -0,0 +1,81 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamePolicy is a naming policy for dependencies enforced at registration, see
+// WithNamePolicy, so aggregated dashboards don't show "Redis", "redis " and
+// "REDIS" as three dependencies
+type NamePolicy struct {
+	// Lowercase rejects names containing upper case letters
+	Lowercase bool
+	// NoSpaces rejects names containing whitespace
+	NoSpaces bool
+	// MaxLength rejects names longer than the number of characters, if set
+	MaxLength int
+	// CaseInsensitive treats names differing only in case as duplicates
+	CaseInsensitive bool
+	// Normalise rewrites names to meet the policy where it can, rather than
+	// rejecting them: surrounding whitespace is trimmed, letters are lower
+	// cased and whitespace is replaced with "-"
+	Normalise bool
+}
+
+// DefaultNamePolicy lower cases and trims names, replacing whitespace with "-"
+var DefaultNamePolicy = NamePolicy{
+	Lowercase:       true,
+	NoSpaces:        true,
+	MaxLength:       63,
+	CaseInsensitive: true,
+	Normalise:       true,
+}
+
+// WithNamePolicy enforces `policy` on the names of dependencies registered on
+// the check. Names which break it are rejected with ErrInvalidDependencyName.
+func WithNamePolicy(policy NamePolicy) Option {
+	return func(s *ServiceCheck) {
+		s.namePolicy = &policy
+	}
+}
+
+// apply returns `name` as normalised by the policy, or an error if it breaks
+// the policy
+func (p *NamePolicy) apply(name string) (string, error) {
+	if p == nil {
+		return name, nil
+	}
+
+	if p.Normalise {
+		name = strings.TrimSpace(name)
+		if p.Lowercase {
+			name = strings.ToLower(name)
+		}
+		if p.NoSpaces {
+			name = strings.Join(strings.Fields(name), "-")
+		}
+	}
+
+	switch {
+	case p.Lowercase && name != strings.ToLower(name):
+		return name, fmt.Errorf("%w: must be lower case", ErrInvalidDependencyName)
+	case p.NoSpaces && strings.IndexFunc(name, unicode.IsSpace) >= 0:
+		return name, fmt.Errorf("%w: must not contain spaces", ErrInvalidDependencyName)
+	case p.MaxLength > 0 && utf8.RuneCountInString(name) > p.MaxLength:
+		return name, fmt.Errorf("%w: must be at most %d characters", ErrInvalidDependencyName, p.MaxLength)
+	}
+
+	return name, nil
+}
+
+// sameName returns whether two dependency names refer to the same dependency
+// under the check's naming policy
+func (s *ServiceCheck) sameName(a, b string) bool {
+	if s.namePolicy != nil && s.namePolicy.CaseInsensitive {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
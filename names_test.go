@@ -0,0 +1,58 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNamePolicy(t *testing.T) {
+	strict := NamePolicy{Lowercase: true, NoSpaces: true, MaxLength: 8}
+	tests := []struct {
+		policy   NamePolicy
+		name     string
+		expected string
+		err      bool
+	}{
+		{strict, "redis", "redis", false},
+		{strict, "Redis", "Redis", true},
+		{strict, "redis eu", "redis eu", true},
+		{strict, "postgresql", "postgresql", true},
+		{DefaultNamePolicy, " Redis EU ", "redis-eu", false},
+		{NamePolicy{}, "Redis EU", "Redis EU", false},
+	}
+
+	for i, test := range tests {
+		policy := test.policy
+		name, err := policy.apply(test.name)
+		if name != test.expected {
+			t.Errorf("expected %q got %q on test case #%d", test.expected, name, i)
+		}
+		if (err != nil) != test.err || (err != nil && !errors.Is(err, ErrInvalidDependencyName)) {
+			t.Errorf("expected error %v got %v on test case #%d", test.err, err, i)
+		}
+	}
+}
+
+func TestWithNamePolicy(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithNamePolicy(DefaultNamePolicy))
+
+	dep, err := check.RegisterDependency("Redis ", LevelHard, func() bool { return true })
+	if err != nil || dep.Name != "redis" {
+		t.Fatalf("expected redis got %v (%v)", dep, err)
+	}
+
+	if _, err := check.RegisterDependency("REDIS", LevelHard, func() bool { return true }); !errors.Is(err, ErrDependencyAlreadyRegistered) {
+		t.Errorf("expected %v got %v", ErrDependencyAlreadyRegistered, err)
+	}
+	if _, err := check.Dependency("ReDiS"); err != nil {
+		t.Errorf("expected to find the dependency ignoring case got %v", err)
+	}
+
+	check, _ = InitialiseServiceCheck("api", time.Minute, WithNamePolicy(NamePolicy{Lowercase: true}))
+	_, err = check.RegisterDependency("Redis", LevelHard, func() bool { return true })
+	expected := `api: dependency "Redis": invalid dependency name: must be lower case`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s got %v", expected, err)
+	}
+}
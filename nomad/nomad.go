@@ -0,0 +1,76 @@
+// Package nomad answers HashiCorp Nomad service checks for a ServiceCheck
+// with response codes matching Nomad's check_restart semantics, so Nomad only
+// restarts a task for failures a restart can fix:
+//
+//	service {
+//	  provider = "consul"
+//	  check {
+//	    type     = "http"
+//	    path     = "/health/nomad"
+//	    interval = "10s"
+//	    timeout  = "2s"
+//	    check_restart {
+//	      limit           = 3
+//	      grace           = "30s"
+//	      ignore_warnings = true
+//	    }
+//	  }
+//	}
+//
+// Nomad has no API for setting the status of a check directly. Services using
+// the Consul provider which prefer push based checks can use a TTL check, see
+// consul.TTLCheck.
+package nomad
+
+import (
+	"net/http"
+
+	"github.com/fresh8/health"
+)
+
+// Provider is the service provider of the Nomad service block, which decides
+// how response codes are interpreted
+type Provider int
+
+const (
+	// ProviderConsul checks are run by Consul, which treats 429 as warning
+	ProviderConsul Provider = iota
+	// ProviderNomad checks are run by Nomad itself, which treats anything but
+	// 2xx as failing
+	ProviderNomad
+)
+
+// Handler returns a http.Handler for Nomad service checks against `check`.
+// It responds:
+//
+//	200  healthy
+//	429  healthy whilst soft dependencies fail, warning in Consul; 200 when
+//	     `provider` is ProviderNomad so soft failures never restart the task
+//	503  unhealthy, starting up or draining, critical
+//
+// The status document is written as the body, which Nomad records as the
+// check output.
+func Handler(check *health.ServiceCheck, provider Provider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		switch {
+		case !check.IsServing():
+			code = http.StatusServiceUnavailable
+		case provider == ProviderConsul && softFailing(check):
+			code = http.StatusTooManyRequests
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		check.WriteStatus(w)
+	})
+}
+
+func softFailing(check *health.ServiceCheck) bool {
+	for _, dependency := range check.DependencyStates() {
+		if dependency.Level == health.LevelSoft && !dependency.Healthy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package nomad
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fresh8/health/healthtest"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		hard     bool
+		soft     bool
+		provider Provider
+		expected int
+	}{
+		{true, true, ProviderConsul, 200},
+		{true, false, ProviderConsul, 429},
+		{false, true, ProviderConsul, 503},
+		{true, true, ProviderNomad, 200},
+		{true, false, ProviderNomad, 200},
+		{false, false, ProviderNomad, 503},
+	}
+
+	for _, test := range tests {
+		check := healthtest.NewServiceCheck("api", healthtest.Hard("db", test.hard), healthtest.Soft("cache", test.soft))
+		w := httptest.NewRecorder()
+		Handler(check, test.provider).ServeHTTP(w, httptest.NewRequest("GET", "/health/nomad", nil))
+		if w.Code != test.expected {
+			t.Errorf("expected %v got %v for %+v", test.expected, w.Code, test)
+		}
+	}
+}
+
+func TestHandlerDraining(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true), healthtest.Soft("cache", true))
+	check.Drain()
+
+	w := httptest.NewRecorder()
+	Handler(check, ProviderNomad).ServeHTTP(w, httptest.NewRequest("GET", "/health/nomad", nil))
+	if w.Code != 503 {
+		t.Errorf("expected 503 got %v", w.Code)
+	}
+}
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultNotifyQueue is the number of changes queued for each
+	// OnStatusChange callback unless set by WithNotifyQueue
+	DefaultNotifyQueue = 16
+	// DefaultNotifyTimeout is how long an OnStatusChange callback has before
+	// its context is cancelled unless set by WithNotifyQueue
+	DefaultNotifyTimeout = 10 * time.Second
+)
+
+// WithNotifyQueue bounds the changes queued for each OnStatusChange callback
+// to `size`, DefaultNotifyQueue if zero, and cancels the context of a call
+// after `timeout`, DefaultNotifyTimeout if zero
+func WithNotifyQueue(size int, timeout time.Duration) Option {
+	return func(s *ServiceCheck) {
+		s.notifyQueue, s.notifyTimeout = size, timeout
+	}
+}
+
+// notifier runs an OnStatusChange callback on a worker of its own
+type notifier struct {
+	fn      func(context.Context, StatusDocument)
+	timeout time.Duration
+	docs    chan StatusDocument
+	stop    chan struct{}
+}
+
+// OnStatusChange calls fn with the status document whenever the health of
+// the service or one of its dependencies changes, or a dependency is
+// registered. Calls are made in order on a worker of their own, with a context
+// cancelled after the timeout, so a slow callback such as a webhook never
+// stalls the checks. Once the queue is full the oldest change is dropped and
+// counted by DroppedNotifications, so the callback always sees the latest
+// status. The returned func unregisters fn and stops its worker.
+func (s *ServiceCheck) OnStatusChange(fn func(ctx context.Context, doc StatusDocument)) func() {
+	size, timeout := s.notifyQueue, s.notifyTimeout
+	if size <= 0 {
+		size = DefaultNotifyQueue
+	}
+	if timeout <= 0 {
+		timeout = DefaultNotifyTimeout
+	}
+
+	n := &notifier{
+		fn:      fn,
+		timeout: timeout,
+		docs:    make(chan StatusDocument, size),
+		stop:    make(chan struct{}),
+	}
+	go n.run()
+
+	s.mu.Lock()
+	s.notifiers = append(s.notifiers, n)
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, registered := range s.notifiers {
+			if registered == n {
+				s.notifiers = append(s.notifiers[:i:i], s.notifiers[i+1:]...)
+				close(n.stop)
+				return
+			}
+		}
+	}
+}
+
+// DroppedNotifications returns the number of changes dropped because an
+// OnStatusChange callback's queue was full
+func (s *ServiceCheck) DroppedNotifications() uint64 {
+	return s.dropped.Load()
+}
+
+// notify queues the published status for every callback, s.mu must be held
+func (s *ServiceCheck) notify() {
+	if len(s.notifiers) == 0 {
+		return
+	}
+
+	status := s.view.Load().status
+	for _, n := range s.notifiers {
+		// each callback has its own copy, so it can change it freely, redacted
+		// as HTTPHandler serves it
+		doc := redactDocument(status.document(), status.redact)
+		select {
+		case n.docs <- doc:
+			continue
+		default:
+		}
+
+		// the queue is full, notify is only called with s.mu held so
+		// nothing else can fill it once the oldest change is dropped
+		select {
+		case <-n.docs:
+			s.dropped.Add(1)
+		default:
+		}
+		n.docs <- doc
+	}
+}
+
+// run calls the callback with each queued change until stopped
+func (n *notifier) run() {
+	for {
+		select {
+		case <-n.stop:
+			return
+		case doc := <-n.docs:
+			ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+			n.fn(ctx, doc)
+			cancel()
+		}
+	}
+}
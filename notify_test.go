@@ -0,0 +1,98 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOnStatusChange(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	healthy := true
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+
+	docs := make(chan StatusDocument, 1)
+	cancel := check.OnStatusChange(func(ctx context.Context, doc StatusDocument) {
+		docs <- doc
+	})
+	defer cancel()
+
+	healthy = false
+	check.Update()
+
+	select {
+	case doc := <-docs:
+		if doc.Healthy || doc.Dependencies[0].Healthy {
+			t.Errorf("expected an unhealthy document got %+v", doc)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the callback to be called")
+	}
+
+	// nothing changed, so there is nothing to notify
+	check.Update()
+	select {
+	case doc := <-docs:
+		t.Errorf("expected no callback got %+v", doc)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOnStatusChangeSlow(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithNotifyQueue(1, 10*time.Millisecond))
+	healthy := true
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+
+	release := make(chan struct{})
+	docs := make(chan StatusDocument, 10)
+	cancel := check.OnStatusChange(func(ctx context.Context, doc StatusDocument) {
+		// ensure the callback's context times out
+		<-ctx.Done()
+		<-release
+		docs <- doc
+	})
+
+	// ensure a blocked callback doesn't stall the checks
+	updated := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			healthy = !healthy
+			check.Update()
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(updated)
+	}()
+
+	select {
+	case <-updated:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the checks not to be stalled")
+	}
+	if check.DroppedNotifications() == 0 {
+		t.Errorf("expected dropped notifications got 0")
+	}
+
+	// the latest status is delivered once the callback is released
+	close(release)
+	var last *StatusDocument
+	for {
+		select {
+		case doc := <-docs:
+			last = &doc
+			continue
+		case <-time.After(100 * time.Millisecond):
+		}
+		break
+	}
+	if last == nil || last.Healthy {
+		t.Errorf("expected the latest status to be unhealthy got %+v", last)
+	}
+
+	cancel()
+	cancel()
+	check.mu.RLock()
+	defer check.mu.RUnlock()
+	if len(check.notifiers) != 0 {
+		t.Errorf("expected 0 notifiers got %d", len(check.notifiers))
+	}
+}
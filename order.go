@@ -0,0 +1,38 @@
+package health
+
+import "sort"
+
+// Order is the order dependencies are kept, checked and reported in, see
+// WithOrder. Either way it is stable, so consecutive status documents can be
+// diffed.
+type Order int
+
+const (
+	// OrderRegistration keeps dependencies in the order they were registered,
+	// the default
+	OrderRegistration Order = iota
+	// OrderAlphabetical keeps dependencies sorted by name
+	OrderAlphabetical
+)
+
+// WithOrder sets the order dependencies are kept in
+func WithOrder(order Order) Option {
+	return func(s *ServiceCheck) {
+		s.order = order
+	}
+}
+
+// insert adds `dep` to the dependencies in the check's order, s.mu must be
+// held
+func (s *ServiceCheck) insert(dep *Dependency) {
+	i := len(s.Dependencies)
+	if s.order == OrderAlphabetical {
+		i = sort.Search(len(s.Dependencies), func(i int) bool {
+			return s.Dependencies[i].Name > dep.Name
+		})
+	}
+
+	s.Dependencies = append(s.Dependencies, nil)
+	copy(s.Dependencies[i+1:], s.Dependencies[i:])
+	s.Dependencies[i] = dep
+}
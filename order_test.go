@@ -0,0 +1,43 @@
+package health
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		order    Order
+		expected []string
+	}{
+		{OrderRegistration, []string{"redis", "db", "queue", "cache"}},
+		{OrderAlphabetical, []string{"cache", "db", "queue", "redis"}},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(&fakeClock{}), WithOrder(test.order))
+		for _, name := range []string{"redis", "db", "queue", "cache"} {
+			check.RegisterDependency(name, LevelSoft, func() bool { return true })
+		}
+
+		states := check.DependencyStates()
+		if len(states) != len(test.expected) {
+			t.Fatalf("expected %d dependencies got %d", len(test.expected), len(states))
+		}
+		for i, name := range test.expected {
+			if states[i].Name != name {
+				t.Errorf("expected %s got %s at %d for order %d", name, states[i].Name, i, test.order)
+			}
+		}
+
+		// the document is the same every time
+		var first, second bytes.Buffer
+		check.WriteStatus(&first)
+		check.Update()
+		check.WriteStatus(&second)
+		if first.String() != second.String() {
+			t.Errorf("expected %s got %s", first.String(), second.String())
+		}
+	}
+}
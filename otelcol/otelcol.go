@@ -0,0 +1,80 @@
+// Package otelcol serves the health of a ServiceCheck in the format of the
+// OpenTelemetry Collector health_check extension, so tooling which already
+// understands collectors can consume services unchanged:
+//
+//	200 {"status":"Server available","upSince":"2020-11-11T04:12:31.684Z","uptime":"49.0132518s"}
+//	503 {"status":"Server not available","upSince":"0001-01-01T00:00:00Z","uptime":""}
+package otelcol
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// The defaults of the health_check extension
+const (
+	DefaultAddr = ":13133"
+	DefaultPath = "/"
+)
+
+// response is the document written by the health_check extension
+type response struct {
+	Status  string    `json:"status"`
+	UpSince time.Time `json:"upSince"`
+	Uptime  string    `json:"uptime"`
+}
+
+// Handler serves the health of a ServiceCheck in the health_check extension
+// format. Use NewHandler to instantiate one
+type Handler struct {
+	check *health.ServiceCheck
+
+	upSince time.Time
+	mu      sync.Mutex
+}
+
+// NewHandler returns a Handler for `check`. The service is considered up
+// since the first request to observe it healthy, or since now if it is
+// healthy already.
+func NewHandler(check *health.ServiceCheck) *Handler {
+	h := &Handler{check: check}
+	if check.IsHealthy() {
+		h.upSince = time.Now()
+	}
+
+	return h
+}
+
+// ServeHTTP writes the health_check document with the relevant response code
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	resp, statusCode := h.response(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// response returns the document as of `now`
+func (h *Handler) response(now time.Time) (response, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.check.IsHealthy() {
+		h.upSince = time.Time{}
+		return response{Status: "Server not available"}, http.StatusServiceUnavailable
+	}
+
+	if h.upSince.IsZero() {
+		h.upSince = now
+	}
+
+	return response{
+		Status:  "Server available",
+		UpSince: h.upSince.UTC(),
+		Uptime:  now.Sub(h.upSince).String(),
+	}, http.StatusOK
+}
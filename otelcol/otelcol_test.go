@@ -0,0 +1,51 @@
+package otelcol
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestHandler(t *testing.T) {
+	check := &health.ServiceCheck{Name: "collector", Healthy: true}
+	h := NewHandler(check)
+	upSince := h.upSince
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", DefaultPath, nil))
+	if w.Code != 200 {
+		t.Errorf("expected 200 got %v", w.Code)
+	}
+
+	var resp map[string]string
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["status"] != "Server available" || resp["uptime"] == "" {
+		t.Errorf("unexpected response %v", resp)
+	}
+	if resp["upSince"] != upSince.UTC().Format(time.RFC3339Nano) {
+		t.Errorf("expected %v got %v", upSince.UTC().Format(time.RFC3339Nano), resp["upSince"])
+	}
+
+	check.Healthy = false
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", DefaultPath, nil))
+	if w.Code != 503 {
+		t.Errorf("expected 503 got %v", w.Code)
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	expected := map[string]string{"status": "Server not available", "upSince": "0001-01-01T00:00:00Z", "uptime": ""}
+	for k, v := range expected {
+		if resp[k] != v {
+			t.Errorf("expected %s %q got %q", k, v, resp[k])
+		}
+	}
+
+	// up since is reset once the service recovers
+	check.Healthy = true
+	if resp, _ := h.response(upSince.Add(time.Hour)); !resp.UpSince.Equal(upSince.Add(time.Hour)) {
+		t.Errorf("expected up since %v got %v", upSince.Add(time.Hour), resp.UpSince)
+	}
+}
@@ -0,0 +1,73 @@
+// Package otelhealth traces the checks of a ServiceCheck's dependencies in
+// OpenTelemetry spans, named "health.check" with the attributes:
+//
+//	health.service      api
+//	health.dependency   redis
+//	health.level        hard
+//	health.healthy      false
+//	health.duration_ms  12.5
+//
+// Failed checks record their error and set the span's status. Checks
+// registered with RegisterDependencyContext run with the span's context, so
+// the HTTP or database calls they make are traced within it.
+package otelhealth
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fresh8/health"
+)
+
+// TracerName is the name of the tracer spans are started with
+const TracerName = "github.com/fresh8/health"
+
+// SpanName is the name of the span of each check
+const SpanName = "health.check"
+
+// Tracer is a health.Tracer starting OpenTelemetry spans. Use NewTracer to
+// instantiate one
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a Tracer starting spans with a tracer from `provider`
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(TracerName)}
+}
+
+// WithTracerProvider traces the service's checks with spans from `provider`
+func WithTracerProvider(provider trace.TracerProvider) health.Option {
+	return health.WithTracer(NewTracer(provider))
+}
+
+// StartCheck implements health.Tracer
+func (t *Tracer) StartCheck(ctx context.Context, service, dependency string, level health.Level) (context.Context, func(healthy bool, err error, took time.Duration)) {
+	ctx, span := t.tracer.Start(ctx, SpanName,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("health.service", service),
+			attribute.String("health.dependency", dependency),
+			attribute.String("health.level", level.String()),
+		),
+	)
+
+	return ctx, func(healthy bool, err error, took time.Duration) {
+		span.SetAttributes(
+			attribute.Bool("health.healthy", healthy),
+			attribute.Float64("health.duration_ms", float64(took)/float64(time.Millisecond)),
+		)
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case !healthy:
+			span.SetStatus(codes.Error, health.ErrUnhealthy.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,83 @@
+package otelhealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/fresh8/health"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	check, _ := health.InitialiseServiceCheck("api", time.Minute, WithTracerProvider(provider))
+	check.RegisterDependency("cache", health.LevelSoft, func() bool { return true })
+
+	var parent trace.SpanContext
+	check.RegisterDependencyContext("db", health.LevelHard, func(ctx context.Context) (bool, error) {
+		parent = trace.SpanContextFromContext(ctx)
+		return false, errors.New("connection refused")
+	})
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans got %d", len(spans))
+	}
+
+	tests := []struct {
+		dependency string
+		level      string
+		healthy    bool
+		status     codes.Code
+		events     int
+	}{
+		{"cache", "soft", true, codes.Unset, 0},
+		{"db", "hard", false, codes.Error, 1},
+	}
+	for i, test := range tests {
+		span := spans[i]
+		if span.Name() != SpanName {
+			t.Errorf("expected %s got %s", SpanName, span.Name())
+		}
+
+		attrs := map[attribute.Key]attribute.Value{}
+		for _, attr := range span.Attributes() {
+			attrs[attr.Key] = attr.Value
+		}
+		if service := attrs["health.service"].AsString(); service != "api" {
+			t.Errorf("expected api got %s", service)
+		}
+		if dependency := attrs["health.dependency"].AsString(); dependency != test.dependency {
+			t.Errorf("expected %s got %s", test.dependency, dependency)
+		}
+		if level := attrs["health.level"].AsString(); level != test.level {
+			t.Errorf("expected %s got %s", test.level, level)
+		}
+		if healthy := attrs["health.healthy"].AsBool(); healthy != test.healthy {
+			t.Errorf("expected %v got %v", test.healthy, healthy)
+		}
+		if _, ok := attrs["health.duration_ms"]; !ok {
+			t.Errorf("expected a duration")
+		}
+		if status := span.Status().Code; status != test.status {
+			t.Errorf("expected %v got %v", test.status, status)
+		}
+		if events := len(span.Events()); events != test.events {
+			t.Errorf("expected %d events got %d", test.events, events)
+		}
+	}
+
+	// ensure the check runs within its span
+	if parent.SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("expected the check's context to carry its span")
+	}
+}
@@ -0,0 +1,80 @@
+package health
+
+// DefaultCycleQueue is the number of cycles StartCheck queues whilst one is
+// running unless set by WithCycleQueue
+const DefaultCycleQueue = 1
+
+// OverrunPolicy decides what StartCheck does with a cycle falling due when the
+// queue of cycles is full, see WithCycleQueue
+type OverrunPolicy int
+
+const (
+	// OverrunSkip skips the cycle falling due, the default
+	OverrunSkip OverrunPolicy = iota
+	// OverrunDropOldest drops the longest queued cycle to make room for the
+	// one falling due
+	OverrunDropOldest
+)
+
+// WithCycleQueue bounds the cycles StartCheck queues whilst one is running to
+// `size`, DefaultCycleQueue if zero, applying `policy` once it is full. Cycles
+// which don't run are counted by SkippedCycles, so a check which can't keep up
+// with its duration degrades predictably.
+func WithCycleQueue(size int, policy OverrunPolicy) Option {
+	return func(s *ServiceCheck) {
+		s.queueSize, s.overrun = size, policy
+	}
+}
+
+// SkippedCycles returns the number of cycles StartCheck has skipped or
+// dropped because the last was still running
+func (s *ServiceCheck) SkippedCycles() uint64 {
+	return s.skipped.Load()
+}
+
+func (s *ServiceCheck) cycleQueue() int {
+	if s.queueSize <= 0 {
+		return DefaultCycleQueue
+	}
+
+	return s.queueSize
+}
+
+// tick queues a cycle every duration until `stop` is closed
+func (s *ServiceCheck) tick(stop <-chan struct{}, cycles chan struct{}) {
+	t := newTimer(s.getClock(), s.duration)
+	defer t.stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.c:
+		}
+
+		t.reset(s.duration)
+		s.queueCycle(cycles)
+	}
+}
+
+// queueCycle queues a cycle, applying the OverrunPolicy if the queue is full
+func (s *ServiceCheck) queueCycle(cycles chan struct{}) {
+	select {
+	case cycles <- struct{}{}:
+		return
+	default:
+	}
+
+	if s.overrun == OverrunDropOldest {
+		select {
+		case <-cycles:
+		default:
+		}
+
+		select {
+		case cycles <- struct{}{}:
+		default:
+		}
+	}
+	s.skipped.Add(1)
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithCycleQueue(t *testing.T) {
+	tests := []struct {
+		size     int
+		policy   OverrunPolicy
+		expected uint64
+	}{
+		{0, OverrunSkip, 2},
+		{2, OverrunSkip, 1},
+		{2, OverrunDropOldest, 1},
+	}
+
+	for _, test := range tests {
+		clock := &fakeClock{}
+		check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock), WithCycleQueue(test.size, test.policy))
+
+		gate, entered := make(chan struct{}, 10), make(chan struct{}, 10)
+		gate <- struct{}{}
+		gate <- struct{}{}
+		check.RegisterDependency("db", LevelHard, func() bool {
+			entered <- struct{}{}
+			<-gate
+			return true
+		})
+		<-entered
+
+		check.StartCheck()
+		<-entered
+
+		// the second cycle runs and blocks, the rest fall due whilst it runs
+		clock.advance(time.Second, 1)
+		<-entered
+		for i := 0; i < 3; i++ {
+			clock.advance(time.Second, 1)
+		}
+
+		for i := 0; check.SkippedCycles() != test.expected; i++ {
+			if i == 100 {
+				t.Fatalf("expected %d skipped got %d for size %d policy %d", test.expected, check.SkippedCycles(), test.size, test.policy)
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		for i := 0; i < 10-2; i++ {
+			gate <- struct{}{}
+		}
+		check.StopCheck()
+	}
+}
@@ -0,0 +1,196 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SavedState is the state of a service saved by its Store, see WithStore
+type SavedState struct {
+	Maintenance  bool              `json:"maintenance,omitempty"`
+	Dependencies []SavedDependency `json:"dependencies"`
+}
+
+// SavedDependency is the state of a dependency saved by its service's Store
+type SavedDependency struct {
+	Name        string    `json:"name"`
+	Healthy     bool      `json:"healthy"`
+	Override    *bool     `json:"override,omitempty"`
+	Actual      bool      `json:"actual,omitempty"`
+	Streak      int       `json:"streak,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+	LastChecked time.Time `json:"lastChecked"`
+	LastSuccess time.Time `json:"lastSuccess,omitzero"`
+	// History is the dependency's results kept by WithHistory, oldest first
+	History []CheckResult `json:"history,omitempty"`
+}
+
+// Store saves the state of a service and loads it back, see WithStore
+type Store interface {
+	// Load returns the last state saved, or nil if there is none
+	Load() (*SavedState, error)
+	Save(state SavedState) error
+}
+
+// FileStore is a Store keeping the state as JSON in the file at Path, which
+// is replaced whole on each save so a crash never leaves it half written
+type FileStore struct {
+	Path string
+}
+
+// Load implements Store
+func (f FileStore) Load() (*SavedState, error) {
+	b, err := os.ReadFile(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &SavedState{}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Save implements Store
+func (f FileStore) Save(state SavedState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// WithStore saves the state of the service to `store` after each of the
+// check's cycles, see StartCheck and Update: whether it is under maintenance
+// and the health, threshold streak, override and history of each dependency.
+// The state saved last is loaded by InitialiseServiceCheck and each
+// dependency's restored when it is registered, so a restart doesn't reset
+// long failure thresholds. Failing to save is logged with WithLogger.
+func WithStore(store Store) Option {
+	return func(s *ServiceCheck) {
+		s.store = store
+	}
+}
+
+// loadState restores the service's state from its Store, if any
+func (s *ServiceCheck) loadState() error {
+	if s.store == nil {
+		return nil
+	}
+
+	state, err := s.store.Load()
+	if err != nil || state == nil {
+		return err
+	}
+
+	s.Maintenance = state.Maintenance
+	s.restored = make(map[string]SavedDependency, len(state.Dependencies))
+	for _, dependency := range state.Dependencies {
+		s.restored[dependency.Name] = dependency
+	}
+	return nil
+}
+
+// takeRestored returns the saved state of the named dependency, if any, which
+// is only restored once
+func (s *ServiceCheck) takeRestored(name string) (SavedDependency, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved, ok := s.restored[name]
+	delete(s.restored, name)
+	return saved, ok
+}
+
+// restore sets the dependency's state to that saved, before it is first
+// checked
+func (d *Dependency) restore(saved SavedDependency) {
+	d.Healthy, d.Override, d.actual = saved.Healthy, saved.Override, saved.Actual
+	d.streak = saved.Streak
+	d.LastError = saved.LastError
+	d.LastChecked, d.LastSuccess = saved.LastChecked, saved.LastSuccess
+	d.checked, d.started = true, saved.LastChecked
+}
+
+// restoreHistory adds the saved results of `dep` to the history, if kept. s.mu
+// must be held
+func (s *ServiceCheck) restoreHistory(dep *Dependency, results []CheckResult) {
+	if s.history == nil || len(results) == 0 {
+		return
+	}
+
+	for _, result := range results {
+		s.history.add(dep, result)
+	}
+	if s.history.config.Summary {
+		summary := summarise(s.history.results(dep))
+		dep.summary = &summary
+	}
+}
+
+// saveState saves the service's state to its Store, if any
+func (s *ServiceCheck) saveState() {
+	if s.store == nil {
+		return
+	}
+
+	s.mu.RLock()
+	state := SavedState{
+		Maintenance:  s.Maintenance,
+		Dependencies: make([]SavedDependency, 0, len(s.Dependencies)),
+	}
+	for _, dependency := range s.Dependencies {
+		// results recorded by recordResult are guarded by the shard lock
+		shard := s.shard(dependency)
+		shard.Lock()
+		if !dependency.checked {
+			shard.Unlock()
+			continue
+		}
+
+		saved := SavedDependency{
+			Name:        dependency.Name,
+			Healthy:     dependency.Healthy,
+			Override:    dependency.Override,
+			Streak:      dependency.streak,
+			LastError:   dependency.LastError,
+			LastChecked: dependency.LastChecked,
+			LastSuccess: dependency.LastSuccess,
+		}
+		if dependency.Override != nil {
+			saved.Actual = dependency.actual
+		}
+		shard.Unlock()
+		if s.history != nil {
+			saved.History = s.history.results(dependency)
+		}
+		state.Dependencies = append(state.Dependencies, saved)
+	}
+	s.mu.RUnlock()
+
+	if err := s.store.Save(state); err != nil && s.logger != nil {
+		s.logger.Warn("health state not saved", "service", s.Name, "error", err)
+	}
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "health.json")}
+
+	// ensure nothing is loaded before anything is saved
+	state, err := store.Load()
+	if err != nil || state != nil {
+		t.Fatalf("expected no state got %+v, %v", state, err)
+	}
+
+	healthy := false
+	saved := SavedState{
+		Maintenance: true,
+		Dependencies: []SavedDependency{
+			{Name: "db", Healthy: true, Streak: 2, LastChecked: time.Unix(100, 0).UTC()},
+			{Name: "cache", Override: &healthy, Actual: true, LastError: "timeout", LastChecked: time.Unix(200, 0).UTC()},
+		},
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+
+	state, err = store.Load()
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !reflect.DeepEqual(*state, saved) {
+		t.Errorf("expected %+v got %+v", saved, *state)
+	}
+
+	// ensure no temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(store.Path))
+	if len(entries) != 1 {
+		t.Errorf("expected 1 file got %d", len(entries))
+	}
+}
+
+func TestWithStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "health.json")}
+
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithStore(store), WithHistory(HistoryConfig{}))
+	healthy := false
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy }, WithThreshold(3, 3))
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+	check.ForceDependencyState("cache", false)
+	check.SetMaintenanceMode(true)
+	healthy = true
+	check.Update()
+
+	// ensure the restarted service carries on from the saved state
+	restarted, err := InitialiseServiceCheck("api", time.Minute, WithStore(store), WithHistory(HistoryConfig{}))
+	if err != nil {
+		t.Fatalf("expected no error got %v", err)
+	}
+	if !restarted.InMaintenance() {
+		t.Errorf("expected the service to be under maintenance")
+	}
+
+	db, _ := restarted.RegisterDependency("db", LevelHard, func() bool { return true }, WithThreshold(3, 3))
+	if db.Healthy {
+		t.Errorf("expected db to be unhealthy until 3 successes")
+	}
+	restarted.Update()
+	if !db.Healthy {
+		t.Errorf("expected db to be healthy after 3 successes")
+	}
+	if stats := restarted.HistoryStats(); stats.Results != 2 {
+		t.Errorf("expected 2 results got %d", stats.Results)
+	}
+
+	cache, _ := restarted.RegisterDependency("cache", LevelSoft, func() bool { return true })
+	if cache.Healthy || cache.Override == nil {
+		t.Errorf("expected the cache to be forced unhealthy got %+v", cache)
+	}
+	restarted.ClearOverride("cache")
+	if !cache.Healthy {
+		t.Errorf("expected the cache to be healthy once cleared")
+	}
+
+	// ensure a dependency is only restored once
+	restarted.UnregisterDependency("db")
+	db, _ = restarted.RegisterDependency("db", LevelHard, func() bool { return true }, WithThreshold(3, 3))
+	if !db.Healthy {
+		t.Errorf("expected db to be healthy")
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Load() (*SavedState, error) { return nil, errors.New("unavailable") }
+func (failingStore) Save(SavedState) error      { return nil }
+
+func TestWithStoreLoadError(t *testing.T) {
+	check, err := InitialiseServiceCheck("api", time.Minute, WithStore(failingStore{}))
+	if err == nil || check != nil {
+		t.Errorf("expected an error got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package health
+
+import "net/http"
+
+// Probe is the set of Kubernetes style probes a dependency affects, see
+// WithProbes
+type Probe uint8
+
+const (
+	// ProbeReadiness makes a failing hard dependency fail ReadinessHandler,
+	// so traffic is routed elsewhere. It is the default.
+	ProbeReadiness Probe = 1 << iota
+	// ProbeLiveness makes a failing hard dependency fail LivenessHandler, so
+	// the process is restarted. It suits process level problems, such as a
+	// deadlock, which a restart fixes.
+	ProbeLiveness
+)
+
+// WithProbes sets the probes the dependency affects, ProbeReadiness if zero,
+// for example ProbeReadiness|ProbeLiveness for both
+func WithProbes(probes Probe) DependencyOption {
+	return func(d *Dependency) {
+		d.probes = probes
+	}
+}
+
+// affects returns whether the dependency failing fails `probe`
+func (d *Dependency) affects(probe Probe) bool {
+	if d.probes == 0 {
+		return probe == ProbeReadiness
+	}
+
+	return d.probes&probe != 0
+}
+
+// isReady returns whether no hard dependency affecting readiness is failing
+// and the service is neither starting, draining nor under maintenance, s.mu
+// must be held
+func (s *ServiceCheck) isReady() bool {
+	if s.Draining || s.Starting || s.Maintenance {
+		return false
+	}
+	// a service unhealthy for want of failing dependencies, such as one set
+	// as unhealthy directly, isn't ready either
+	if !s.Healthy && s.dependenciesHealthy() {
+		return false
+	}
+
+	return s.probeHealthy(ProbeReadiness)
+}
+
+// isLive returns whether no hard dependency affecting liveness is failing,
+// s.mu must be held
+func (s *ServiceCheck) isLive() bool {
+	return s.probeHealthy(ProbeLiveness)
+}
+
+// probeHealthy returns whether no hard dependency affecting `probe` is
+// failing, s.mu must be held
+func (s *ServiceCheck) probeHealthy(probe Probe) bool {
+	for _, dependency := range s.Dependencies {
+		if !dependency.Healthy && dependency.Level == LevelHard && dependency.affects(probe) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsReady returns whether the service should receive traffic: no hard
+// dependency affecting readiness is failing, see WithProbes, and it is
+// neither starting up nor draining
+func (s *ServiceCheck) IsReady() bool {
+	return s.precomputed().ready
+}
+
+// IsLive returns whether no hard dependency affecting liveness is failing,
+// see WithProbes
+func (s *ServiceCheck) IsLive() bool {
+	return s.precomputed().live
+}
+
+// ReadinessHandler outputs the status with a 200 whilst IsReady and a 503
+// otherwise, for Kubernetes readiness probes. The dependencies are checked
+// first if the check's HandlerMode requires it.
+func (s *ServiceCheck) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	s.refresh()
+	status := s.load().status
+	s.probeResponse(w, status, status.isReady())
+}
+
+// LivenessHandler outputs the status with a 200 whilst IsLive and a 503
+// otherwise, for Kubernetes liveness probes. Starting, draining and failing
+// dependencies which only affect readiness don't fail it. The dependencies are
+// checked first if the check's HandlerMode requires it.
+func (s *ServiceCheck) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	s.refresh()
+	status := s.load().status
+	s.probeResponse(w, status, status.isLive())
+}
+
+// probeResponse writes the snapshot `status` with a 200 if `ok`, and a 503
+// otherwise
+func (s *ServiceCheck) probeResponse(w http.ResponseWriter, status *ServiceCheck, ok bool) {
+	setContentType(w)
+	if ok {
+		w.WriteHeader(200)
+	} else {
+		w.WriteHeader(503)
+	}
+
+	status.writeStatus(w)
+}
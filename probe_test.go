@@ -0,0 +1,72 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbes(t *testing.T) {
+	tests := []struct {
+		probes Probe
+		failed string
+		ready  bool
+		live   bool
+	}{
+		{0, "", true, true},
+		{0, "db", false, true},
+		{ProbeReadiness, "db", false, true},
+		{ProbeLiveness, "db", true, false},
+		{ProbeReadiness | ProbeLiveness, "db", false, false},
+		// soft dependencies affect neither
+		{ProbeReadiness | ProbeLiveness, "cache", true, true},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute)
+		db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true }, WithProbes(test.probes))
+		cache, _ := check.RegisterDependency("cache", LevelSoft, func() bool { return true }, WithProbes(test.probes))
+		switch test.failed {
+		case "db":
+			db.SetHealthy(false)
+		case "cache":
+			cache.SetHealthy(false)
+		}
+
+		if got := check.IsReady(); got != test.ready {
+			t.Errorf("expected ready %v got %v for probes %d failing %q", test.ready, got, test.probes, test.failed)
+		}
+		if got := check.IsLive(); got != test.live {
+			t.Errorf("expected live %v got %v for probes %d failing %q", test.live, got, test.probes, test.failed)
+		}
+
+		for _, handler := range []struct {
+			serve    http.HandlerFunc
+			expected bool
+		}{
+			{check.ReadinessHandler, test.ready},
+			{check.LivenessHandler, test.live},
+		} {
+			w := httptest.NewRecorder()
+			handler.serve(w, httptest.NewRequest("GET", "/", nil))
+			if (w.Code == 200) != handler.expected {
+				t.Errorf("expected %v got %d for probes %d failing %q", handler.expected, w.Code, test.probes, test.failed)
+			}
+		}
+	}
+}
+
+func TestProbesDraining(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.Drain()
+
+	// ensure draining stops traffic without restarting the process
+	if check.IsReady() {
+		t.Errorf("expected not ready whilst draining")
+	}
+	if !check.IsLive() {
+		t.Errorf("expected live whilst draining")
+	}
+}
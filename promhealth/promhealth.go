@@ -0,0 +1,78 @@
+// Package promhealth exports the health of a ServiceCheck as Prometheus
+// metrics:
+//
+//	health_service_up{service="api"} 1
+//	health_dependency_up{service="api",dependency="redis",level="hard"} 1
+//	health_check_duration_seconds{service="api",dependency="redis",level="hard"} 0.0012
+package promhealth
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/fresh8/health"
+)
+
+var (
+	serviceUpDesc = prometheus.NewDesc(
+		"health_service_up",
+		"Whether the service is healthy, 1 if so and 0 if not.",
+		[]string{"service"}, nil,
+	)
+	dependencyUpDesc = prometheus.NewDesc(
+		"health_dependency_up",
+		"Whether the dependency is healthy as of its last check, 1 if so and 0 if not.",
+		[]string{"service", "dependency", "level"}, nil,
+	)
+	checkDurationDesc = prometheus.NewDesc(
+		"health_check_duration_seconds",
+		"How long the last check of the dependency took.",
+		[]string{"service", "dependency", "level"}, nil,
+	)
+)
+
+// Collector is a prometheus.Collector reporting the health of a ServiceCheck
+// as of its last check. Use NewCollector to instantiate one
+type Collector struct {
+	check *health.ServiceCheck
+}
+
+// NewCollector returns a Collector for `check`
+func NewCollector(check *health.ServiceCheck) *Collector {
+	return &Collector{check: check}
+}
+
+// Register creates a Collector for `check` and registers it on `reg`
+func Register(reg prometheus.Registerer, check *health.ServiceCheck) (*Collector, error) {
+	c := NewCollector(check)
+	if err := reg.Register(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Describe sends the descriptors of the metrics the Collector reports
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- serviceUpDesc
+	ch <- dependencyUpDesc
+	ch <- checkDurationDesc
+}
+
+// Collect sends the metrics for the service and each of its dependencies
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	name := c.check.Name
+	ch <- prometheus.MustNewConstMetric(serviceUpDesc, prometheus.GaugeValue, up(c.check.IsHealthy()), name)
+
+	for _, dependency := range c.check.DependencyStates() {
+		level := dependency.Level.String()
+		ch <- prometheus.MustNewConstMetric(dependencyUpDesc, prometheus.GaugeValue, up(dependency.Healthy), name, dependency.Name, level)
+		ch <- prometheus.MustNewConstMetric(checkDurationDesc, prometheus.GaugeValue, dependency.CheckDuration().Seconds(), name, dependency.Name, level)
+	}
+}
+
+func up(healthy bool) float64 {
+	if healthy {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,41 @@
+package promhealth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/fresh8/health"
+)
+
+func TestCollector(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("redis", health.LevelHard, func() bool { return false })
+	check.RegisterDependency("cache", health.LevelSoft, func() bool { return true })
+	check.Update()
+
+	reg := prometheus.NewPedanticRegistry()
+	if _, err := Register(reg, check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := `
+# HELP health_dependency_up Whether the dependency is healthy as of its last check, 1 if so and 0 if not.
+# TYPE health_dependency_up gauge
+health_dependency_up{dependency="cache",level="soft",service="api"} 1
+health_dependency_up{dependency="redis",level="hard",service="api"} 0
+# HELP health_service_up Whether the service is healthy, 1 if so and 0 if not.
+# TYPE health_service_up gauge
+health_service_up{service="api"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "health_dependency_up", "health_service_up"); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+
+	if n := testutil.CollectAndCount(NewCollector(check), "health_check_duration_seconds"); n != 2 {
+		t.Errorf("expected 2 durations got %d", n)
+	}
+}
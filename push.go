@@ -0,0 +1,142 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultPushRetries is the number of retries a PushReporter makes when
+	// MaxRetries is not set
+	DefaultPushRetries = 3
+	// DefaultPushBackoff is the initial delay between retries when Backoff is
+	// not set
+	DefaultPushBackoff = 100 * time.Millisecond
+	// DefaultPushMaxBackoff caps the delay between retries when MaxBackoff is
+	// not set
+	DefaultPushMaxBackoff = 5 * time.Second
+)
+
+// PushReporter periodically POSTs the status of a ServiceCheck to a central
+// aggregator, for environments where the aggregator can't reach every
+// instance to scrape it
+type PushReporter struct {
+	// URL of the aggregator
+	URL string
+	// Interval between pushes when started with Start
+	Interval time.Duration
+	// Header is added to every request, for example to authenticate with the
+	// aggregator
+	Header http.Header
+
+	// MaxRetries is the number of times a failed push is retried, negative
+	// disables retries
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubling on each further
+	// retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// Client is used to make requests, a NewHTTPClient is used if nil
+	Client *http.Client
+}
+
+// Start pushes the status of `s` every Interval until ctx is cancelled. Errors
+// are dropped, the next push will report the current status regardless.
+func (p *PushReporter) Start(ctx context.Context, s *ServiceCheck) {
+	go func() {
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+
+		for {
+			p.Report(ctx, s)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Report pushes the current status of `s` once, retrying with exponential
+// backoff on network errors, 429s and 5xx responses
+func (p *PushReporter) Report(ctx context.Context, s *ServiceCheck) error {
+	var body bytes.Buffer
+	if err := s.WriteStatus(&body); err != nil {
+		return err
+	}
+
+	retries := p.MaxRetries
+	switch {
+	case retries == 0:
+		retries = DefaultPushRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = DefaultPushBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultPushMaxBackoff
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = p.push(ctx, body.Bytes())
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// push makes a single request, reporting whether a failure is worth retrying
+func (p *PushReporter) push(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", p.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, values := range p.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := p.Client
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("aggregator responded with status code %d", resp.StatusCode)
+}
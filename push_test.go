@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPushReporter(t *testing.T) {
+	tests := []struct {
+		failures         int32
+		failureCode      int
+		expectedAttempts int32
+		expectedErr      bool
+	}{
+		// Passing - first time
+		{0, 0, 1, false},
+		// Passing - after retrying server errors
+		{2, http.StatusBadGateway, 3, false},
+		// Failing - out of retries
+		{10, http.StatusTooManyRequests, 4, true},
+		// Failing - client errors aren't retried
+		{10, http.StatusUnauthorized, 1, true},
+	}
+
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	check.RegisterDependency("redis", LevelHard, func() bool { return true })
+
+	for i, test := range tests {
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempt := atomic.AddInt32(&attempts, 1)
+			if r.Header.Get("Authorization") != "Bearer token" {
+				t.Errorf("expected authorization header to be sent")
+			}
+			if attempt <= test.failures {
+				w.WriteHeader(test.failureCode)
+				return
+			}
+
+			var status ServiceCheck
+			if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+				t.Errorf("unexpected error decoding push: %v", err)
+			}
+			if status.Name != "test" || len(status.Dependencies) != 1 {
+				t.Errorf("unexpected status pushed %+v", &status)
+			}
+		}))
+
+		reporter := &PushReporter{
+			URL:     server.URL,
+			Header:  http.Header{"Authorization": []string{"Bearer token"}},
+			Backoff: time.Millisecond,
+		}
+
+		err := reporter.Report(context.Background(), check)
+		if (err != nil) != test.expectedErr {
+			t.Errorf("expected error %v got %v on test case #%d", test.expectedErr, err, i)
+		}
+		if attempts != test.expectedAttempts {
+			t.Errorf("expected %d attempts got %d on test case #%d", test.expectedAttempts, attempts, i)
+		}
+
+		server.Close()
+	}
+}
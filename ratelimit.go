@@ -0,0 +1,68 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter bounds how often dependencies are checked, so a process with
+// many dependencies and short durations can't overwhelm shared
+// infrastructure. It can be shared by several ServiceChecks to bound the whole
+// process, see WithRateLimiter.
+type RateLimiter struct {
+	perSecond float64
+	burst     float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing `perSecond` checks a second on
+// average and up to `burst` at once, at least one
+func NewRateLimiter(perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+	}
+}
+
+// WithRateLimiter makes every check of a dependency wait its turn with
+// `limiter`, including the check on registration
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(s *ServiceCheck) {
+		s.limiter = limiter
+	}
+}
+
+// wait blocks until a check may run. Waiting checks are let through in the
+// order they arrived.
+func (l *RateLimiter) wait(clock Clock) {
+	if l == nil || l.perSecond <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := clock.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	// reserve a token, waiting for it to be refilled if there are none
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.perSecond * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait > 0 {
+		<-clock.After(wait)
+	}
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	clock := &fakeClock{}
+	limiter := NewRateLimiter(1, 2)
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock), WithRateLimiter(limiter))
+
+	checked := make(chan string, 3)
+	register := func(name string) {
+		check.RegisterDependency(name, LevelHard, func() bool {
+			checked <- name
+			return true
+		})
+	}
+
+	// the burst is let straight through
+	register("db")
+	register("cache")
+	if len(checked) != 2 {
+		t.Fatalf("expected 2 checks got %d", len(checked))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		register("queue")
+		close(done)
+	}()
+
+	clock.advance(500*time.Millisecond, 1)
+	select {
+	case <-done:
+		t.Fatal("expected the third check to wait for the rate limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.advance(500*time.Millisecond, 0)
+	<-done
+	if len(checked) != 3 {
+		t.Errorf("expected 3 checks got %d", len(checked))
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	clock := &fakeClock{}
+	limiter := NewRateLimiter(10, 1)
+	first, _ := InitialiseServiceCheck("first", time.Minute, WithClock(clock), WithRateLimiter(limiter))
+	second, _ := InitialiseServiceCheck("second", time.Minute, WithClock(clock), WithRateLimiter(limiter))
+
+	first.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	done := make(chan struct{})
+	go func() {
+		second.RegisterDependency("db", LevelHard, func() bool { return true })
+		close(done)
+	}()
+
+	clock.advance(100*time.Millisecond, 1)
+	<-done
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	var limiter *RateLimiter
+	limiter.wait(realClock{})
+	NewRateLimiter(0, 1).wait(realClock{})
+}
@@ -0,0 +1,33 @@
+package health
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// recovered returns `check` reporting the dependency unhealthy, rather than
+// crashing the checks, if it panics. The panic is kept for the dependency's
+// LastError and logged, see WithLogger.
+func (d *Dependency) recovered(check func() bool) func() bool {
+	return func() (healthy bool) {
+		d.panics.set(nil)
+		start := d.clock().Now()
+		defer func() {
+			if r := recover(); r != nil {
+				healthy = false
+				d.panics.set(panicError(r))
+				if logger := d.logger(); logger != nil {
+					took := d.clock().Now().Sub(start)
+					logger.Error("health check panicked", append(d.logAttrs(took), slog.Any("panic", r))...)
+				}
+			}
+		}()
+
+		return check()
+	}
+}
+
+// panicError returns the error a check panicking with `r` failed with
+func panicError(r interface{}) error {
+	return fmt.Errorf("%w: %v", ErrCheckPanicked, r)
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecoveredPanic(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	panics := true
+	plain, _ := check.RegisterDependency("plain", LevelHard, func() bool {
+		if panics {
+			panic("boom")
+		}
+		return true
+	})
+	timed, _ := check.RegisterDependency("timed", LevelSoft, func() bool { panic("boom") }, WithTimeout(time.Second))
+	ctx, _ := check.RegisterDependencyContext("ctx", LevelSoft, func(context.Context) (bool, error) { panic("boom") })
+
+	for _, dep := range []*Dependency{plain, timed, ctx} {
+		if dep.IsHealthy() {
+			t.Errorf("expected %s to be unhealthy", dep.Name)
+		}
+	}
+
+	expected := panicError("boom").Error()
+	for _, dep := range check.Document().Dependencies {
+		if dep.LastError != expected {
+			t.Errorf("expected %s got %q for %s", expected, dep.LastError, dep.Name)
+		}
+	}
+	if !errors.Is(panicError("boom"), ErrCheckPanicked) {
+		t.Errorf("expected %v", ErrCheckPanicked)
+	}
+
+	// ensure the panic is cleared once the check stops panicking
+	panics = false
+	check.Update()
+	if !plain.IsHealthy() || check.Document().Dependencies[0].LastError != "" {
+		t.Errorf("expected plain to recover got %+v", check.Document().Dependencies[0])
+	}
+}
+
+func TestRecoveredPanicStartCheck(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Millisecond)
+	runs := make(chan struct{}, 10)
+	check.RegisterDependency("db", LevelHard, func() bool {
+		select {
+		case runs <- struct{}{}:
+		default:
+		}
+		panic("boom")
+	})
+	check.StartCheck()
+	defer check.StopCheck()
+
+	// ensure the checks carry on after a panic
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("expected the checks to carry on")
+		}
+	}
+}
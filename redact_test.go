@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"postgres://app:hunter2@db:5432/orders", "postgres://app:[REDACTED]@db:5432/orders"},
+		{"dial redis://:s3cr3t@cache:6379: refused", "dial redis://:[REDACTED]@cache:6379: refused"},
+		{"app:hunter2@tcp(db:3306)/orders", "app:[REDACTED]@tcp(db:3306)/orders"},
+		{"host=db user=app password=hunter2 dbname=orders", "host=db user=app password=[REDACTED] dbname=orders"},
+		{"https://partner/health?api_key=abc123&region=eu", "https://partner/health?api_key=[REDACTED]&region=eu"},
+		{"http://users/health", "http://users/health"},
+		{"db", "db"},
+	}
+
+	for _, test := range tests {
+		if got := RedactSecrets(test.input); got != test.expected {
+			t.Errorf("expected %s got %s", test.expected, got)
+		}
+	}
+}
+
+func TestWithRedactor(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithRedactor(RedactSecrets))
+	check.RegisterDependency("db", LevelHard, func() bool { return true }, WithURL("http://admin:hunter2@db/health"))
+
+	b, err := json.Marshal(check)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if strings.Contains(string(b), "hunter2") || !strings.Contains(string(b), `"url":"http://admin:[REDACTED]@db/health"`) {
+		t.Errorf("expected the password to be redacted got %s", b)
+	}
+}
+
+func TestWithRedactorNotify(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithRedactor(RedactSecrets))
+	healthy := true
+	check.RegisterDependencyContext("db", LevelHard, func(ctx context.Context) (bool, error) {
+		if healthy {
+			return true, nil
+		}
+		return false, errors.New("dial postgres://app:hunter2@db/orders: refused")
+	}, WithURL("http://admin:hunter2@db/health"))
+
+	docs := make(chan StatusDocument, 1)
+	cancel := check.OnStatusChange(func(ctx context.Context, doc StatusDocument) {
+		docs <- doc
+	})
+	defer cancel()
+
+	healthy = false
+	check.Update()
+
+	// ensure notifiers are sent the document as HTTPHandler serves it
+	select {
+	case doc := <-docs:
+		b, _ := json.Marshal(doc)
+		if strings.Contains(string(b), "hunter2") {
+			t.Errorf("expected the passwords to be redacted got %s", b)
+		}
+		if expected := "dial postgres://app:[REDACTED]@db/orders: refused"; doc.Dependencies[0].LastError != expected {
+			t.Errorf("expected %s got %s", expected, doc.Dependencies[0].LastError)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected the callback to be called")
+	}
+}
@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// defaultTimeout bounds dials and commands whose context has no deadline
+const defaultTimeout = 5 * time.Second
+
+// conn is a minimal RESP connection, just enough for the commands used by the
+// package
+type conn struct {
+	netConn net.Conn
+	r       *bufio.Reader
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func dial(ctx context.Context, addr, password string, db int) (*conn, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	var d net.Dialer
+	netConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{netConn: netConn, r: bufio.NewReader(netConn)}
+	if password != "" {
+		if _, err := c.do(ctx, "AUTH", password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if db != 0 {
+		if _, err := c.do(ctx, "SELECT", strconv.Itoa(db)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// do sends a command and reads its reply
+func (c *conn) do(ctx context.Context, args ...string) (interface{}, error) {
+	ctx, cancel := withDefaultTimeout(ctx)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	c.netConn.SetDeadline(deadline)
+
+	if err := c.write(args...); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+func (c *conn) write(args ...string) error {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+
+	_, err := c.netConn.Write(buf)
+	return err
+}
+
+// read reads a single reply. Bulk strings are returned as string, or nil if
+// absent, integers as int64 and arrays as []interface{}
+func (c *conn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", kind)
+	}
+}
+
+func (c *conn) Close() error {
+	return c.netConn.Close()
+}
+
+func withDefaultTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, defaultTimeout)
+}
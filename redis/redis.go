@@ -0,0 +1,270 @@
+// Package redis shares replica health through Redis, for worker fleets behind
+// queues where no load balancer ever probes the health endpoint. Each replica
+// publishes its status to a key with a TTL, and optionally a pub/sub channel,
+// and any process can read back the health of the whole fleet.
+//
+// It speaks the Redis protocol directly, so needs no Redis client library.
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+const (
+	// DefaultAddr is the address of a local Redis server
+	DefaultAddr = "127.0.0.1:6379"
+	// DefaultPrefix is the key prefix used when Store.Prefix is empty
+	DefaultPrefix = "health:"
+	// DefaultTTL is the key TTL used when Store.TTL is not set
+	DefaultTTL = 30 * time.Second
+)
+
+// Store publishes and reads replica health in Redis
+type Store struct {
+	// Addr of the Redis server, DefaultAddr if empty
+	Addr     string
+	Password string
+	DB       int
+
+	// Prefix every replica's key is written under, DefaultPrefix if empty
+	Prefix string
+	// TTL of every replica's key, so replicas which stop publishing drop out
+	// of the fleet. DefaultTTL if not set
+	TTL time.Duration
+	// Channel, if set, additionally has every status published to it as a
+	// Message
+	Channel string
+
+	mu   sync.Mutex
+	conn *conn
+}
+
+// Message is published to Store.Channel on every publish
+type Message struct {
+	Instance string               `json:"instance"`
+	Status   *health.ServiceCheck `json:"status"`
+}
+
+// Publish writes the status of `check` under the key for `instance`, and to
+// the channel if one is configured
+func (s *Store) Publish(ctx context.Context, instance string, check *health.ServiceCheck) error {
+	var status bytes.Buffer
+	if err := check.WriteStatus(&status); err != nil {
+		return err
+	}
+	value := strings.TrimSpace(status.String())
+
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	if _, err := s.do(ctx, "SET", s.prefix()+instance, value, "PX", strconv.FormatInt(int64(ttl/time.Millisecond), 10)); err != nil {
+		return err
+	}
+
+	if s.Channel == "" {
+		return nil
+	}
+
+	message, err := json.Marshal(Message{Instance: instance, Status: check})
+	if err != nil {
+		return err
+	}
+	_, err = s.do(ctx, "PUBLISH", s.Channel, string(message))
+	return err
+}
+
+// Fleet reads back the status of every replica currently published under the
+// prefix, keyed by instance
+func (s *Store) Fleet(ctx context.Context) (map[string]*health.ServiceCheck, error) {
+	prefix := s.prefix()
+
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := s.do(ctx, "SCAN", cursor, "MATCH", escapeGlob(prefix)+"*", "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, redisError("unexpected SCAN reply")
+		}
+		cursor, _ = page[0].(string)
+		batch, _ := page[1].([]interface{})
+		for _, key := range batch {
+			if key, ok := key.(string); ok {
+				keys = append(keys, key)
+			}
+		}
+
+		if cursor == "0" {
+			break
+		}
+	}
+
+	fleet := make(map[string]*health.ServiceCheck, len(keys))
+	if len(keys) == 0 {
+		return fleet, nil
+	}
+
+	reply, err := s.do(ctx, append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := reply.([]interface{})
+	for i, value := range values {
+		// keys may expire between SCAN and MGET
+		value, ok := value.(string)
+		if !ok || i >= len(keys) {
+			continue
+		}
+
+		check := &health.ServiceCheck{}
+		if err := json.Unmarshal([]byte(value), check); err != nil {
+			return nil, err
+		}
+		fleet[strings.TrimPrefix(keys[i], prefix)] = check
+	}
+
+	return fleet, nil
+}
+
+// Start publishes the status of `check` every `interval` until ctx is
+// cancelled. `interval` should be comfortably shorter than the TTL.
+func (s *Store) Start(ctx context.Context, instance string, check *health.ServiceCheck, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.Publish(ctx, instance, check)
+
+			select {
+			case <-ctx.Done():
+				s.Close()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Subscribe invokes fn with every Message published to the channel until ctx
+// is cancelled or the connection fails, returning the reason it stopped. It
+// uses a dedicated connection.
+func (s *Store) Subscribe(ctx context.Context, fn func(Message)) error {
+	c, err := dial(ctx, s.addr(), s.Password, s.DB)
+	if err != nil {
+		return err
+	}
+
+	// closing the connection unblocks the read loop on cancellation
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		c.Close()
+	}()
+
+	if err := c.write("SUBSCRIBE", s.Channel); err != nil {
+		return err
+	}
+
+	for {
+		reply, err := c.read()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		push, ok := reply.([]interface{})
+		if !ok || len(push) != 3 || push[0] != "message" {
+			continue
+		}
+		payload, _ := push[2].(string)
+
+		var message Message
+		if err := json.Unmarshal([]byte(payload), &message); err != nil {
+			continue
+		}
+		fn(message)
+	}
+}
+
+// Close closes the store's connection, a new one is made on next use
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// do runs a command on the store's connection, dialling if needed. The
+// connection is dropped on any failure other than an error reply.
+func (s *Store) do(ctx context.Context, args ...string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		c, err := dial(ctx, s.addr(), s.Password, s.DB)
+		if err != nil {
+			return nil, err
+		}
+		s.conn = c
+	}
+
+	reply, err := s.conn.do(ctx, args...)
+	if _, ok := err.(redisError); err != nil && !ok {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return reply, err
+}
+
+func (s *Store) addr() string {
+	if s.Addr == "" {
+		return DefaultAddr
+	}
+	return s.Addr
+}
+
+func (s *Store) prefix() string {
+	if s.Prefix == "" {
+		return DefaultPrefix
+	}
+	return s.Prefix
+}
+
+// escapeGlob escapes the glob characters understood by SCAN MATCH
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,210 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// fakeServer is an in-memory stand in for a Redis server supporting the
+// commands used by Store
+type fakeServer struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	values      map[string]string
+	ttls        map[string]string
+	subscribers map[string][]net.Conn
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeServer{
+		listener:    listener,
+		values:      map[string]string{},
+		ttls:        map[string]string{},
+		subscribers: map[string][]net.Conn{},
+	}
+	go s.serve()
+	return s
+}
+
+func (s *fakeServer) serve() {
+	for {
+		netConn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(&conn{netConn: netConn, r: bufio.NewReader(netConn)})
+	}
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func (s *fakeServer) handle(c *conn) {
+	defer c.Close()
+	for {
+		reply, err := c.read()
+		if err != nil {
+			return
+		}
+
+		var args []string
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, arg.(string))
+		}
+
+		s.mu.Lock()
+		var out string
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[1] == "secret" {
+				out = "+OK\r\n"
+			} else {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case "SET":
+			s.values[args[1]] = args[2]
+			s.ttls[args[1]] = args[4]
+			out = "+OK\r\n"
+		case "PUBLISH":
+			for _, subscriber := range s.subscribers[args[1]] {
+				fmt.Fprintf(subscriber, "*3\r\n%s%s%s", bulk("message"), bulk(args[1]), bulk(args[2]))
+			}
+			out = fmt.Sprintf(":%d\r\n", len(s.subscribers[args[1]]))
+		case "SUBSCRIBE":
+			s.subscribers[args[1]] = append(s.subscribers[args[1]], c.netConn)
+			out = fmt.Sprintf("*3\r\n%s%s:1\r\n", bulk("subscribe"), bulk(args[1]))
+		case "SCAN":
+			var keys []string
+			for key := range s.values {
+				if ok, _ := path.Match(args[3], key); ok {
+					keys = append(keys, bulk(key))
+				}
+			}
+			out = fmt.Sprintf("*2\r\n%s*%d\r\n%s", bulk("0"), len(keys), strings.Join(keys, ""))
+		case "MGET":
+			out = fmt.Sprintf("*%d\r\n", len(args)-1)
+			for _, key := range args[1:] {
+				if value, ok := s.values[key]; ok {
+					out += bulk(value)
+				} else {
+					out += "$-1\r\n"
+				}
+			}
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		s.mu.Unlock()
+
+		c.netConn.Write([]byte(out))
+	}
+}
+
+func (s *fakeServer) subscriberCount(channel string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subscribers[channel])
+}
+
+func TestStore(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.listener.Close()
+
+	store := &Store{
+		Addr:     server.listener.Addr().String(),
+		Password: "secret",
+		Prefix:   "health:workers:",
+		TTL:      10 * time.Second,
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	if err := store.Publish(ctx, "worker-0", &health.ServiceCheck{Name: "workers", Healthy: true}); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if err := store.Publish(ctx, "worker-1", &health.ServiceCheck{Name: "workers", Healthy: false}); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	server.mu.Lock()
+	ttl := server.ttls["health:workers:worker-0"]
+	server.mu.Unlock()
+	if ttl != "10000" {
+		t.Errorf("expected a 10000ms TTL got %v", ttl)
+	}
+
+	fleet, err := store.Fleet(ctx)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if len(fleet) != 2 {
+		t.Fatalf("expected 2 replicas got %d", len(fleet))
+	}
+	if !fleet["worker-0"].Healthy || fleet["worker-1"].Healthy {
+		t.Errorf("unexpected fleet health %v %v", fleet["worker-0"].Healthy, fleet["worker-1"].Healthy)
+	}
+}
+
+func TestStoreAuthFailure(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.listener.Close()
+
+	store := &Store{Addr: server.listener.Addr().String(), Password: "wrong"}
+	err := store.Publish(context.Background(), "worker-0", &health.ServiceCheck{Name: "workers"})
+	if _, ok := err.(redisError); !ok {
+		t.Errorf("expected an error reply got %v", err)
+	}
+}
+
+func TestStoreSubscribe(t *testing.T) {
+	server := newFakeServer(t)
+	defer server.listener.Close()
+
+	store := &Store{Addr: server.listener.Addr().String(), Channel: "health"}
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages := make(chan Message, 1)
+	done := make(chan error)
+	go func() {
+		done <- store.Subscribe(ctx, func(m Message) { messages <- m })
+	}()
+
+	for i := 0; server.subscriberCount("health") == 0; i++ {
+		if i > 100 {
+			t.Fatal("timed out waiting for subscription")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	store.Publish(context.Background(), "worker-0", &health.ServiceCheck{Name: "workers", Healthy: true})
+
+	select {
+	case m := <-messages:
+		if m.Instance != "worker-0" || !m.Status.Healthy {
+			t.Errorf("unexpected message %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+}
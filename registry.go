@@ -0,0 +1,135 @@
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Registry holds several ServiceChecks running in the same process and reports
+// on them together. Use NewRegistry to instantiate one
+type Registry struct {
+	checks []*ServiceCheck
+	mu     sync.RWMutex
+}
+
+// registryStatus is the combined status written by a Registry
+type registryStatus struct {
+	Healthy  bool            `json:"healthy"`
+	Services []*ServiceCheck `json:"services"`
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a ServiceCheck to the registry. ServiceCheck names must be
+// unique within a registry.
+func (r *Registry) Register(check *ServiceCheck) error {
+	if check == nil || check.Name == "" {
+		return ErrNoServiceNameSupplied
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.checks {
+		if existing.Name == check.Name {
+			return &ServiceError{Service: check.Name, Err: ErrServiceAlreadyRegistered}
+		}
+	}
+
+	r.checks = append(r.checks, check)
+	return nil
+}
+
+// ServiceCheck finds and returns the named ServiceCheck
+func (r *Registry) ServiceCheck(name string) (*ServiceCheck, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, check := range r.checks {
+		if check.Name == name {
+			return check, nil
+		}
+	}
+
+	return nil, &ServiceError{Service: name, Err: ErrNoServiceCheck}
+}
+
+// IsHealthy returns a bool whether every ServiceCheck in the registry is
+// healthy
+func (r *Registry) IsHealthy() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, check := range r.checks {
+		if !check.IsHealthy() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WriteStatus writes the combined status of every ServiceCheck to any
+// io.Writer
+func (r *Registry) WriteStatus(w io.Writer) error {
+	r.mu.RLock()
+	status := registryStatus{
+		Healthy:  true,
+		Services: make([]*ServiceCheck, len(r.checks)),
+	}
+	copy(status.Services, r.checks)
+	r.mu.RUnlock()
+
+	for _, check := range status.Services {
+		if !check.IsHealthy() {
+			status.Healthy = false
+		}
+	}
+
+	return json.NewEncoder(w).Encode(status)
+}
+
+// HTTPHandler outputs the combined status of every ServiceCheck, each nested
+// under services, with the relevant response code to a ResponseWriter. The
+// response code is 200 whilst every ServiceCheck is healthy, so a hard
+// dependency failing in any of them, and 503 otherwise.
+func (r *Registry) HTTPHandler(w http.ResponseWriter, req *http.Request) {
+	setContentType(w)
+	if r.IsHealthy() {
+		w.WriteHeader(200)
+	} else {
+		w.WriteHeader(503)
+	}
+
+	r.WriteStatus(w)
+}
+
+// Handler returns a http.Handler serving the combined status at `prefix` and
+// the status of an individual ServiceCheck at `prefix/{service}`. When using a
+// http.ServeMux register it for both paths, for example:
+//
+//	h := registry.Handler("/health")
+//	mux.Handle("/health", h)
+//	mux.Handle("/health/", h)
+func (r *Registry) Handler(prefix string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := strings.Trim(strings.TrimPrefix(req.URL.Path, prefix), "/")
+		if name == "" {
+			r.HTTPHandler(w, req)
+			return
+		}
+
+		check, err := r.ServiceCheck(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		check.HTTPHandler(w, req)
+	})
+}
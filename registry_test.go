@@ -0,0 +1,142 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+
+	users, _ := InitialiseServiceCheck("users", time.Second)
+	tests := []struct {
+		check       *ServiceCheck
+		expectedErr error
+	}{
+		{users, nil},
+		{users, ErrServiceAlreadyRegistered},
+		{&ServiceCheck{}, ErrNoServiceNameSupplied},
+		{nil, ErrNoServiceNameSupplied},
+	}
+
+	for i, test := range tests {
+		if err := registry.Register(test.check); !errors.Is(err, test.expectedErr) {
+			t.Errorf("expected %v got %v on test case #%d", test.expectedErr, err, i)
+		}
+	}
+
+	check, err := registry.ServiceCheck("users")
+	if err != nil || check != users {
+		t.Errorf("expected %v got %v (%v)", users, check, err)
+	}
+
+	if _, err := registry.ServiceCheck("orders"); !errors.Is(err, ErrNoServiceCheck) {
+		t.Errorf("expected %v got %v", ErrNoServiceCheck, err)
+	}
+}
+
+func TestRegistryHandler(t *testing.T) {
+	users, _ := InitialiseServiceCheck("users", time.Second)
+	users.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	orders, _ := InitialiseServiceCheck("orders", time.Second)
+	orders.RegisterDependency("db", LevelHard, func() bool { return false })
+	orders.updateStatus()
+
+	registry := NewRegistry()
+	registry.Register(users)
+	registry.Register(orders)
+
+	h := registry.Handler("/health")
+	mux := http.NewServeMux()
+	mux.Handle("/health", h)
+	mux.Handle("/health/", h)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{"/health", 503},
+		{"/health/", 503},
+		{"/health/users", 200},
+		{"/health/orders", 503},
+		{"/health/missing", 404},
+	}
+
+	for _, test := range tests {
+		res, err := http.Get(ts.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != test.expectedCode {
+			t.Errorf("expected %d got %d for %s", test.expectedCode, res.StatusCode, test.path)
+		}
+	}
+
+	res, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var status registryStatus
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Healthy {
+		t.Error("expected combined status to be unhealthy")
+	}
+	if len(status.Services) != 2 {
+		t.Errorf("expected 2 services got %d", len(status.Services))
+	}
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected application/json got %s", contentType)
+	}
+}
+
+func TestRegistryHTTPHandler(t *testing.T) {
+	users, _ := InitialiseServiceCheck("users", time.Second)
+	users.RegisterDependency("db", LevelHard, func() bool { return true })
+	orders, _ := InitialiseServiceCheck("orders", time.Second)
+	orders.RegisterDependency("cache", LevelSoft, func() bool { return false })
+	orders.updateStatus()
+
+	registry := NewRegistry()
+	registry.Register(users)
+	registry.Register(orders)
+
+	// a soft failure in a service leaves the registry healthy
+	w := httptest.NewRecorder()
+	registry.HTTPHandler(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 200 {
+		t.Errorf("expected 200 got %d", w.Code)
+	}
+
+	var status struct {
+		Healthy  bool             `json:"healthy"`
+		Services []StatusDocument `json:"services"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Healthy || len(status.Services) != 2 || status.Services[1].Dependencies[0].Name != "cache" {
+		t.Errorf("expected both services nested got %s", w.Body)
+	}
+
+	orders.RegisterDependency("db", LevelHard, func() bool { return false })
+	orders.updateStatus()
+	w = httptest.NewRecorder()
+	registry.HTTPHandler(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 503 {
+		t.Errorf("expected 503 got %d", w.Code)
+	}
+}
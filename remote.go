@@ -0,0 +1,299 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRemoteDetailDepth is the number of levels of remote detail kept
+	// when WithRemoteDetail is given a depth of zero
+	DefaultRemoteDetailDepth = 3
+
+	// remoteRetryDelay is the pause between attempts of a remote check
+	remoteRetryDelay = 100 * time.Millisecond
+)
+
+// RemoteOption configures a dependency registered with RegisterRemoteService.
+// It is a DependencyOption, so the two can be passed together, and does
+// nothing to other dependencies.
+type RemoteOption = DependencyOption
+
+// remoteOption returns a RemoteOption configuring the remoteCheck of a
+// remote dependency with `set`
+func remoteOption(set func(r *remoteCheck)) RemoteOption {
+	return func(d *Dependency) {
+		if d.remote != nil {
+			set(d.remote)
+		}
+	}
+}
+
+// WithRemoteTimeout bounds each attempt to fetch the remote status, on top of
+// the timeout of the HTTP client
+func WithRemoteTimeout(timeout time.Duration) RemoteOption {
+	return remoteOption(func(r *remoteCheck) {
+		r.timeout = timeout
+	})
+}
+
+// WithRemoteRetries retries a failed fetch of the remote status up to
+// `retries` times before reporting the dependency as unhealthy. A remote
+// service reporting itself as unhealthy is not retried.
+func WithRemoteRetries(retries int) RemoteOption {
+	return remoteOption(func(r *remoteCheck) {
+		r.retries = retries
+	})
+}
+
+// WithRemoteClient uses `client` rather than the check's client, see
+// WithHTTPClient, to fetch the remote status
+func WithRemoteClient(client *http.Client) RemoteOption {
+	return remoteOption(func(r *remoteCheck) {
+		r.client = client
+	})
+}
+
+// WithRemoteDetail embeds the remote service's status document in the
+// dependency's Remote field. Remote detail is nested at most `maxDepth` levels
+// deep, DefaultRemoteDetailDepth if zero, so services which depend on each
+// other don't produce ever growing documents.
+func WithRemoteDetail(maxDepth int) RemoteOption {
+	return remoteOption(func(r *remoteCheck) {
+		if maxDepth <= 0 {
+			maxDepth = DefaultRemoteDetailDepth
+		}
+		r.detailDepth = maxDepth
+	})
+}
+
+// WithRemoteStaleness serves the last successfully fetched status for up to
+// `window` after it was fetched when fetching fails, rather than immediately
+// reporting the dependency as unhealthy. Whilst the status is stale it is
+// revalidated in the background, including any retries, and the dependency is
+// flagged as stale. This keeps transient failures between the services from
+// flapping the dependency.
+func WithRemoteStaleness(window time.Duration) RemoteOption {
+	return remoteOption(func(r *remoteCheck) {
+		r.staleness = window
+	})
+}
+
+// RegisterRemoteService registers another service using this package as a
+// dependency, checking it by fetching its health endpoint at `url`. The URL is
+// recorded on the dependency so GetTree can follow it. `opts` may mix
+// RemoteOptions with any other DependencyOption, such as WithThreshold.
+func (s *ServiceCheck) RegisterRemoteService(name, url string, level Level, opts ...DependencyOption) (*Dependency, error) {
+	r := &remoteCheck{
+		url:     url,
+		client:  s.getHTTPClient(),
+		clock:   s.getClock(),
+		context: s.context,
+	}
+
+	remote := func(d *Dependency) {
+		d.remote = r
+	}
+	return s.RegisterDependency(name, level, r.check, append([]DependencyOption{WithURL(url), remote}, opts...)...)
+}
+
+// RegisterRemoteDependency registers another service using this package as a
+// dependency, as RegisterRemoteService, embedding its dependency tree in the
+// status document so transitive failures are visible from one endpoint. The
+// tree is nested at most DefaultRemoteDetailDepth levels deep unless `opts`
+// include WithRemoteDetail.
+func (s *ServiceCheck) RegisterRemoteDependency(name, url string, level Level, opts ...DependencyOption) (*Dependency, error) {
+	return s.RegisterRemoteService(name, url, level, append([]DependencyOption{WithRemoteDetail(0)}, opts...)...)
+}
+
+// remoteCheck checks a remote service, remembering its last status document
+type remoteCheck struct {
+	url         string
+	client      *http.Client
+	timeout     time.Duration
+	retries     int
+	detailDepth int
+	staleness   time.Duration
+	clock       Clock
+	// context returns the context fetches run with
+	context func() context.Context
+
+	mu           sync.Mutex
+	last         *ServiceCheck
+	lastSuccess  time.Time
+	err          error
+	stale        bool
+	revalidating bool
+}
+
+// clone returns a remoteCheck with the same configuration which hasn't
+// fetched the remote status yet
+func (r *remoteCheck) clone() *remoteCheck {
+	return &remoteCheck{
+		url:         r.url,
+		client:      r.client,
+		timeout:     r.timeout,
+		retries:     r.retries,
+		detailDepth: r.detailDepth,
+		staleness:   r.staleness,
+		clock:       r.clock,
+		context:     r.context,
+	}
+}
+
+func (r *remoteCheck) check() bool {
+	status, err := r.fetch()
+	if err != nil && r.serveStale() {
+		return r.lastHealthy()
+	}
+
+	for attempt := 1; err != nil && attempt <= r.retries; attempt++ {
+		<-r.clock.After(remoteRetryDelay)
+		status, err = r.fetch()
+	}
+
+	r.record(status, err)
+	return err == nil && status.Healthy
+}
+
+// record stores the outcome of fetching the remote status
+func (r *remoteCheck) record(status *ServiceCheck, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stale = false
+	r.err = err
+	if err != nil {
+		r.last = nil
+		return
+	}
+
+	r.last = status
+	r.lastSuccess = r.clock.Now()
+}
+
+// serveStale reports whether the last status is still within the staleness
+// window, marking it as stale and revalidating it in the background if so
+func (r *remoteCheck) serveStale() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.staleness <= 0 || r.last == nil || r.clock.Now().Sub(r.lastSuccess) >= r.staleness {
+		return false
+	}
+
+	r.stale = true
+	if !r.revalidating {
+		r.revalidating = true
+		go r.revalidate()
+	}
+	return true
+}
+
+// revalidate retries fetching the remote status until it succeeds, the
+// retries run out, or the last status falls out of the staleness window
+func (r *remoteCheck) revalidate() {
+	defer func() {
+		r.mu.Lock()
+		r.revalidating = false
+		r.mu.Unlock()
+	}()
+
+	attempts := r.retries
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		<-r.clock.After(remoteRetryDelay)
+
+		status, err := r.fetch()
+		if err == nil {
+			r.record(status, nil)
+			return
+		}
+
+		r.mu.Lock()
+		expired := r.clock.Now().Sub(r.lastSuccess) >= r.staleness
+		r.mu.Unlock()
+		if expired {
+			return
+		}
+	}
+}
+
+func (r *remoteCheck) lastHealthy() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last != nil && r.last.Healthy
+}
+
+// lastError returns the error the last fetch failed with, if any
+func (r *remoteCheck) lastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *remoteCheck) isStale() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stale
+}
+
+func (r *remoteCheck) fetch() (*ServiceCheck, error) {
+	ctx := context.Background()
+	if r.context != nil {
+		ctx = r.context()
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	return fetchServiceCheck(ctx, r.client, r.url)
+}
+
+// detail returns the last status document pruned to the detail depth, or nil
+// if detail isn't enabled or the last fetch failed
+func (r *remoteCheck) detail() *ServiceCheck {
+	if r.detailDepth == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == nil {
+		return nil
+	}
+
+	return pruneRemote(r.last, r.detailDepth)
+}
+
+// pruneRemote returns a copy of status with remote detail nested deeper than
+// depth levels removed
+func pruneRemote(status *ServiceCheck, depth int) *ServiceCheck {
+	pruned := &ServiceCheck{
+		Name:         status.Name,
+		Healthy:      status.Healthy,
+		Dependencies: make([]*Dependency, len(status.Dependencies)),
+	}
+
+	for i, dependency := range status.Dependencies {
+		copied := &Dependency{
+			Name:    dependency.Name,
+			Healthy: dependency.Healthy,
+			Level:   dependency.Level,
+			URL:     dependency.URL,
+		}
+		if dependency.Remote != nil && depth > 1 {
+			copied.Remote = pruneRemote(dependency.Remote, depth-1)
+		}
+		pruned.Dependencies[i] = copied
+	}
+
+	return pruned
+}
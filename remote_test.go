@@ -0,0 +1,256 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegisterRemoteService(t *testing.T) {
+	var failures int32
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		check, _ := InitialiseServiceCheck("users", time.Second)
+		check.RegisterDependency("db", LevelHard, func() bool { return true })
+		check.HTTPHandler(w, r)
+	}))
+	defer remote.Close()
+
+	tests := []struct {
+		failures       int32
+		opts           []RemoteOption
+		expectedHealth bool
+		expectedDetail bool
+	}{
+		// Passing
+		{0, nil, true, false},
+		// Passing - with detail
+		{0, []RemoteOption{WithRemoteDetail(0)}, true, true},
+		// Passing - after retrying
+		{2, []RemoteOption{WithRemoteRetries(2)}, true, false},
+		// Failing - out of retries
+		{2, []RemoteOption{WithRemoteRetries(1), WithRemoteDetail(0)}, false, false},
+	}
+
+	for i, test := range tests {
+		atomic.StoreInt32(&failures, test.failures)
+
+		check, _ := InitialiseServiceCheck("api", time.Second)
+		_, err := check.RegisterRemoteService("users", remote.URL, LevelHard, test.opts...)
+		if err != nil {
+			t.Fatalf("expected nil got %v on test case #%d", err, i)
+		}
+
+		dep, _ := check.Dependency("users")
+		if dep.Healthy != test.expectedHealth {
+			t.Errorf("expected %v got %v on test case #%d", test.expectedHealth, dep.Healthy, i)
+		}
+		// ensure failed fetches record why
+		if (dep.LastError == "") != test.expectedHealth {
+			t.Errorf("unexpected last error %q on test case #%d", dep.LastError, i)
+		}
+		if dep.URL != remote.URL {
+			t.Errorf("expected URL %v got %v on test case #%d", remote.URL, dep.URL, i)
+		}
+		if (dep.Remote != nil) != test.expectedDetail {
+			t.Errorf("expected detail %v got %v on test case #%d", test.expectedDetail, dep.Remote, i)
+		}
+		if dep.Remote != nil && (dep.Remote.Name != "users" || len(dep.Remote.Dependencies) != 1) {
+			t.Errorf("unexpected detail %+v on test case #%d", dep.Remote, i)
+		}
+	}
+}
+
+func TestRegisterRemoteDependency(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("users", time.Second)
+		check.RegisterDependency("db", LevelHard, func() bool { return false })
+		check.Update()
+		check.HTTPHandler(w, r)
+	}))
+	defer remote.Close()
+
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	if _, err := check.RegisterRemoteDependency("users", remote.URL, LevelHard); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	check.Update()
+
+	// ensure the transitive failure is visible from our own document
+	doc := check.Document()
+	users := doc.Dependencies[0]
+	if users.Healthy || users.Remote == nil || len(users.Remote.Dependencies) != 1 {
+		t.Fatalf("expected the remote tree to be embedded got %+v", users)
+	}
+	if db := users.Remote.Dependencies[0]; db.Name != "db" || db.Healthy {
+		t.Errorf("expected an unhealthy db got %+v", db)
+	}
+}
+
+func TestRegisterRemoteServiceOptions(t *testing.T) {
+	var failing int32
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		check, _ := InitialiseServiceCheck("users", time.Second)
+		check.HTTPHandler(w, r)
+	}))
+	defer remote.Close()
+
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	dep, err := check.RegisterRemoteService("users", remote.URL, LevelHard, WithThreshold(2, 1), WithRemoteDetail(0))
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if dep.Remote == nil {
+		t.Error("expected the remote option to apply alongside the threshold")
+	}
+
+	// ensure dependency options apply to remote dependencies
+	atomic.StoreInt32(&failing, 1)
+	for _, expected := range []bool{true, false} {
+		check.Update()
+		if healthy := dep.IsHealthy(); healthy != expected {
+			t.Errorf("expected %v got %v", expected, healthy)
+		}
+	}
+
+	// ensure the returned dependency can be paused
+	dep.Pause()
+	atomic.StoreInt32(&failing, 0)
+	check.Update()
+	if dep.IsHealthy() {
+		t.Errorf("expected %v got %v", false, true)
+	}
+}
+
+func TestRegisterRemoteServiceTimeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	start := time.Now()
+	check.RegisterRemoteService("slow", slow.URL, LevelHard, WithRemoteTimeout(20*time.Millisecond))
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected the remote timeout to be respected, took %v", time.Since(start))
+	}
+	if dep, _ := check.Dependency("slow"); dep.Healthy {
+		t.Error("expected timed out remote to be unhealthy")
+	}
+}
+
+func TestPruneRemote(t *testing.T) {
+	// a → b → a → b ...
+	deep := &ServiceCheck{Name: "a", Healthy: true}
+	current := deep
+	for i := 0; i < 10; i++ {
+		next := &ServiceCheck{Name: "b", Healthy: true}
+		current.Dependencies = []*Dependency{{Name: "next", Healthy: true, Remote: next}}
+		current = next
+	}
+
+	pruned := pruneRemote(deep, 2)
+
+	b, _ := json.Marshal(pruned)
+	var depth int
+	for c := pruned; c != nil && len(c.Dependencies) > 0; c = c.Dependencies[0].Remote {
+		depth++
+	}
+	if depth != 2 {
+		t.Errorf("expected detail to be pruned to 2 levels got %d: %s", depth, b)
+	}
+}
+
+func TestRemoteStaleness(t *testing.T) {
+	var failing int32
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(&ServiceCheck{Name: "users", Healthy: true})
+	}))
+	defer remote.Close()
+
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	check.RegisterRemoteService("users", remote.URL, LevelHard, WithRemoteStaleness(300*time.Millisecond))
+
+	atomic.StoreInt32(&failing, 1)
+	check.updateStatus()
+
+	states := check.DependencyStates()
+	if !states[0].Healthy || !states[0].Stale {
+		t.Errorf("expected last known healthy status to be served as stale got healthy %v stale %v", states[0].Healthy, states[0].Stale)
+	}
+
+	// recovery is picked up by the background revalidation
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(2 * remoteRetryDelay)
+	check.updateStatus()
+
+	states = check.DependencyStates()
+	if !states[0].Healthy || states[0].Stale {
+		t.Errorf("expected fresh healthy status got healthy %v stale %v", states[0].Healthy, states[0].Stale)
+	}
+
+	// once the window passes the failure is reported
+	atomic.StoreInt32(&failing, 1)
+	time.Sleep(350 * time.Millisecond)
+	check.updateStatus()
+
+	states = check.DependencyStates()
+	if states[0].Healthy || states[0].Stale {
+		t.Errorf("expected unhealthy status once stale window passed got healthy %v stale %v", states[0].Healthy, states[0].Stale)
+	}
+}
+
+// roundTripper counts requests before passing them on
+type roundTripper struct {
+	requests int32
+}
+
+func (rt *roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&rt.requests, 1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("users", time.Second)
+		check.HTTPHandler(w, r)
+	}))
+	defer remote.Close()
+
+	rt := &roundTripper{}
+	check, _ := InitialiseServiceCheck("test", time.Second, WithHTTPClient(&http.Client{Transport: rt}))
+	if _, err := check.RegisterRemoteService("users", remote.URL, LevelHard); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	if atomic.LoadInt32(&rt.requests) != 1 {
+		t.Errorf("expected 1 request through the check's client got %d", rt.requests)
+	}
+
+	// the remote option takes precedence
+	other := &roundTripper{}
+	check.RegisterRemoteService("orders", remote.URL, LevelHard, WithRemoteClient(&http.Client{Transport: other}))
+	if atomic.LoadInt32(&rt.requests) != 1 || atomic.LoadInt32(&other.requests) != 1 {
+		t.Errorf("expected 1 and 1 requests got %d and %d", rt.requests, other.requests)
+	}
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReportTimeout bounds each call of a Reporter registered with
+// RegisterReporter
+const DefaultReportTimeout = 10 * time.Second
+
+// Reporter pushes the state of a ServiceCheck to an external system, such as
+// PushReporter posting it to a webhook or the statsd package's Reporter
+type Reporter interface {
+	Report(ctx context.Context, s *ServiceCheck) error
+}
+
+// ensure PushReporter can be registered with RegisterReporter
+var _ Reporter = (*PushReporter)(nil)
+
+// reporter is a registered Reporter, with whether a report is running
+type reporter struct {
+	Reporter
+	running atomic.Bool
+}
+
+// RegisterReporter calls r.Report with a Snapshot of the service after each
+// cycle of the check started by StartCheck, with a context cancelled after
+// DefaultReportTimeout or once the check is stopped. Reports run in the
+// background, so a slow reporter doesn't hold up the checks, and a cycle
+// ending whilst the last report is still running is skipped for that
+// reporter. Errors are logged, see WithLogger. The returned func unregisters
+// r.
+func (s *ServiceCheck) RegisterReporter(r Reporter) func() {
+	rep := &reporter{Reporter: r}
+
+	s.mu.Lock()
+	s.reporters = append(s.reporters, rep)
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, registered := range s.reporters {
+			if registered == rep {
+				s.reporters = append(s.reporters[:i:i], s.reporters[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// report starts a report by every reporter not still running its last, s.mu
+// must be held
+func (s *ServiceCheck) report() {
+	if len(s.reporters) == 0 {
+		return
+	}
+
+	parent := s.lockedContext()
+	published := s.view.Load().status
+	for _, rep := range s.reporters {
+		if !rep.running.CompareAndSwap(false, true) {
+			continue
+		}
+
+		// each reporter has its own copy, so it can change it freely
+		status := published.deepCopy()
+
+		go func(rep *reporter) {
+			defer rep.running.Store(false)
+			ctx, cancel := context.WithTimeout(parent, DefaultReportTimeout)
+			defer cancel()
+
+			if err := rep.Report(ctx, status); err != nil && s.logger != nil {
+				s.logger.Warn("health report failed", "service", s.Name, "error", err.Error())
+			}
+		}(rep)
+	}
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// reporterFunc is a Reporter calling the func
+type reporterFunc func(ctx context.Context, s *ServiceCheck) error
+
+func (f reporterFunc) Report(ctx context.Context, s *ServiceCheck) error {
+	return f(ctx, s)
+}
+
+func TestRegisterReporter(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	reports := make(chan *ServiceCheck, 10)
+	unregister := check.RegisterReporter(reporterFunc(func(ctx context.Context, s *ServiceCheck) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected the report to have a deadline")
+		}
+		reports <- s
+		return errors.New("unreachable")
+	}))
+
+	// on demand updates aren't cycles of the check
+	check.Update()
+	check.scheduledUpdate()
+	select {
+	case s := <-reports:
+		// ensure a snapshot is reported rather than the live check
+		if s == check || s.Name != "api" || len(s.Dependencies) != 1 || !s.Dependencies[0].Healthy {
+			t.Errorf("expected a snapshot of the check to be reported got %+v", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a report after the cycle")
+	}
+	select {
+	case <-reports:
+		t.Errorf("expected a single report")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unregister()
+	check.scheduledUpdate()
+	select {
+	case <-reports:
+		t.Errorf("expected no report once unregistered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRegisterReporterSlow(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	check.RegisterReporter(reporterFunc(func(ctx context.Context, s *ServiceCheck) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}))
+
+	// ensure cycles ending whilst a report runs neither block nor pile up
+	for i := 0; i < 3; i++ {
+		check.scheduledUpdate()
+	}
+	close(release)
+	<-started
+	if len(started) != 0 {
+		t.Errorf("expected 1 report got %d", len(started)+1)
+	}
+}
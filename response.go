@@ -0,0 +1,93 @@
+package health
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxResponseSize is the default of MaxResponseSize, 1MiB
+const DefaultMaxResponseSize = 1 << 20
+
+// MaxResponseSize is the largest status document read from another service,
+// by Get, GetAll, Watch, remote dependencies and GetTree. Larger documents
+// fail with ErrResponseTooLarge rather than being read into memory.
+var MaxResponseSize int64 = DefaultMaxResponseSize
+
+// jsonContentType is the Content-Type of status documents, shared so setting
+// it doesn't allocate
+var jsonContentType = []string{"application/json"}
+
+// setContentType sets the Content-Type of a status document on `w`
+func setContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = jsonContentType
+}
+
+// decodeResponse decodes the status document in the body of `resp` into `v`,
+// reading at most MaxResponseSize bytes. Errors are a ResponseError.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	if !isStatusContentType(resp.Header.Get("Content-Type")) {
+		return responseError(resp, ErrUnexpectedContentType)
+	}
+
+	body := &limitedReader{r: resp.Body, n: MaxResponseSize}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return responseError(resp, err)
+	}
+
+	return nil
+}
+
+// isStatusContentType returns whether a response of Content-Type
+// `contentType` can be a status document. Older versions of this package
+// didn't set one, so documents were served as sniffed text/plain.
+func isStatusContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || mediaType == "text/plain" || strings.HasSuffix(mediaType, "+json")
+}
+
+// responseError returns a ResponseError for `resp`
+func responseError(resp *http.Response, err error) error {
+	e := &ResponseError{StatusCode: resp.StatusCode, ContentType: resp.Header.Get("Content-Type"), Err: err}
+	if resp.Request != nil {
+		e.URL = resp.Request.URL.String()
+	}
+
+	return e
+}
+
+// limitedReader reads from r, failing with ErrResponseTooLarge once more
+// than n bytes are read
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	// read one byte past the limit, to tell a document of exactly n bytes
+	// from a larger one
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, ErrResponseTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetResponses(t *testing.T) {
+	defer func(size int64) { MaxResponseSize = size }(MaxResponseSize)
+	MaxResponseSize = 64
+
+	doc := `{"name":"api","healthy":true,"dependencies":[]}`
+	tests := []struct {
+		contentType string
+		body        string
+		healthy     bool
+		err         error
+	}{
+		{"application/json", doc, true, nil},
+		{"application/json; charset=utf-8", doc, true, nil},
+		{"application/health+json", doc, true, nil},
+		// older versions of this package served sniffed text/plain
+		{"text/plain; charset=utf-8", doc, true, nil},
+		{"", doc, true, nil},
+		{"application/json", doc + strings.Repeat(" ", 64-len(doc)), true, nil},
+		{"text/html", doc, false, ErrUnexpectedContentType},
+		{"application/json", `{"name":"` + strings.Repeat("a", 64) + `","healthy":true}`, false, ErrResponseTooLarge},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if test.contentType != "" {
+				w.Header().Set("Content-Type", test.contentType)
+			} else {
+				w.Header()["Content-Type"] = nil
+			}
+			fmt.Fprint(w, test.body)
+		}))
+
+		healthy, err := Get(server.URL)
+		if healthy != test.healthy {
+			t.Errorf("expected %v got %v for %q", test.healthy, healthy, test.contentType)
+		}
+		if test.err == nil && err != nil {
+			t.Errorf("expected nil got %v for %q", err, test.contentType)
+		}
+		if test.err != nil {
+			var responseErr *ResponseError
+			if !errors.Is(err, test.err) || !errors.As(err, &responseErr) {
+				t.Errorf("expected %v got %v for %q", test.err, err, test.contentType)
+			} else if responseErr.StatusCode != 200 || !strings.HasPrefix(responseErr.URL, server.URL) {
+				t.Errorf("expected the response of %s got %+v", server.URL, responseErr)
+			}
+		}
+
+		server.Close()
+	}
+}
+
+func TestHTTPHandlerContentType(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	for _, handler := range []http.HandlerFunc{check.HTTPHandler, check.FastHTTPHandler} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", "/health", nil))
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("expected application/json got %s", got)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package rpchealth serves the status of a ServiceCheck as the
+// fresh8.health.v1.HealthService described in health.proto, over the Connect
+// and Twirp protocols, for platforms standardised on those RPC stacks rather
+// than plain HTTP JSON.
+//
+// Only the JSON codecs are supported. GetStatus is served over Connect at
+// /fresh8.health.v1.HealthService/GetStatus and over Twirp at
+// /twirp/fresh8.health.v1.HealthService/GetStatus. WatchStatus is a Connect
+// server stream.
+package rpchealth
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/fresh8/health"
+)
+
+// ServiceName is the fully qualified name of the service in health.proto
+const ServiceName = "fresh8.health.v1.HealthService"
+
+// maxRequestSize bounds how much of a request is read
+const maxRequestSize = 1 << 16
+
+// Status is the JSON form of the Status message
+type Status struct {
+	Name         string       `json:"name"`
+	Healthy      bool         `json:"healthy"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Dependency is the JSON form of the Dependency message
+type Dependency struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Level   string `json:"level"`
+}
+
+// NewStatus returns the Status message for `check`
+func NewStatus(check *health.ServiceCheck) Status {
+	dependencies := check.DependencyStates()
+	status := Status{
+		Name:         check.Name,
+		Healthy:      check.IsHealthy(),
+		Dependencies: make([]Dependency, len(dependencies)),
+	}
+
+	for i, dependency := range dependencies {
+		status.Dependencies[i] = Dependency{
+			Name:    dependency.Name,
+			Healthy: dependency.Healthy,
+			Level:   "LEVEL_SOFT",
+		}
+		if dependency.Level == health.LevelHard {
+			status.Dependencies[i].Level = "LEVEL_HARD"
+		}
+	}
+
+	return status
+}
+
+// Handler returns a http.Handler serving HealthService for `check`. Mount it
+// at the root of a mux, or at both "/fresh8.health.v1.HealthService/" and
+// "/twirp/fresh8.health.v1.HealthService/".
+func Handler(check *health.ServiceCheck) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+ServiceName+"/GetStatus", func(w http.ResponseWriter, r *http.Request) {
+		getStatus(w, r, check, connectError)
+	})
+	mux.HandleFunc("/twirp/"+ServiceName+"/GetStatus", func(w http.ResponseWriter, r *http.Request) {
+		getStatus(w, r, check, twirpError)
+	})
+	mux.HandleFunc("/"+ServiceName+"/WatchStatus", func(w http.ResponseWriter, r *http.Request) {
+		watchStatus(w, r, check)
+	})
+
+	return mux
+}
+
+// getStatus serves a unary GetStatus call, writing errors with `writeError`
+func getStatus(w http.ResponseWriter, r *http.Request, check *health.ServiceCheck, writeError func(http.ResponseWriter, int, string)) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "unsupported method "+r.Method)
+		return
+	}
+	if !isContentType(r, "application/json") {
+		writeError(w, http.StatusUnsupportedMediaType, "only the JSON codec is supported")
+		return
+	}
+	if err := readRequest(io.LimitReader(r.Body, maxRequestSize)); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(NewStatus(check))
+}
+
+// watchStatus serves a WatchStatus Connect server stream
+func watchStatus(w http.ResponseWriter, r *http.Request, check *health.ServiceCheck) {
+	if r.Method != http.MethodPost {
+		connectError(w, http.StatusMethodNotAllowed, "unsupported method "+r.Method)
+		return
+	}
+	if !isContentType(r, "application/connect+json") {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// the request is a single enveloped, and empty, message
+	var header [5]byte
+	if _, err := io.ReadFull(r.Body, header[:]); err != nil {
+		connectError(w, http.StatusBadRequest, "missing request message")
+		return
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxRequestSize {
+		connectError(w, http.StatusBadRequest, "request message too large")
+		return
+	}
+	if err := readRequest(io.LimitReader(r.Body, int64(size))); err != nil {
+		connectError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/connect+json")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	var last []byte
+	for {
+		// fetch the channel before reading the status so no change is missed
+		changed := check.Changed()
+
+		msg, _ := json.Marshal(NewStatus(check))
+		if !bytes.Equal(msg, last) {
+			if err := writeEnvelope(w, 0, msg); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			last = msg
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+// readRequest validates a request message, which has no fields. An empty body
+// is accepted as an empty message.
+func readRequest(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil || len(bytes.TrimSpace(b)) == 0 {
+		return err
+	}
+
+	var msg struct{}
+	return json.Unmarshal(b, &msg)
+}
+
+func isContentType(r *http.Request, expected string) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == expected
+}
+
+// writeEnvelope writes a Connect stream envelope
+func writeEnvelope(w io.Writer, flags byte, msg []byte) error {
+	var header [5]byte
+	header[0] = flags
+	binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+
+	_, err := w.Write(msg)
+	return err
+}
+
+// connectError writes an error in the Connect unary format
+func connectError(w http.ResponseWriter, statusCode int, msg string) {
+	writeJSONError(w, statusCode, struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{errorCode(statusCode, "unimplemented", "invalid_argument"), msg})
+}
+
+// twirpError writes an error in the Twirp format
+func twirpError(w http.ResponseWriter, statusCode int, msg string) {
+	writeJSONError(w, statusCode, struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+	}{errorCode(statusCode, "bad_route", "malformed"), msg})
+}
+
+// errorCode maps a status code onto the error code of either protocol
+func errorCode(statusCode int, route, malformed string) string {
+	if statusCode == http.StatusBadRequest {
+		return malformed
+	}
+	return route
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
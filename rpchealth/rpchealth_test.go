@@ -0,0 +1,99 @@
+package rpchealth
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fresh8/health"
+	"github.com/fresh8/health/healthtest"
+)
+
+func TestGetStatus(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true))
+	server := httptest.NewServer(Handler(check))
+	defer server.Close()
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"/" + ServiceName + "/GetStatus", "application/json", "{}", 200},
+		{"/twirp/" + ServiceName + "/GetStatus", "application/json", "", 200},
+		{"/" + ServiceName + "/GetStatus", "application/proto", "", 415},
+		{"/twirp/" + ServiceName + "/GetStatus", "application/json", "[", 400},
+	}
+
+	for _, test := range tests {
+		resp, err := http.Post(server.URL+test.path, test.contentType, strings.NewReader(test.body))
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != test.expected {
+			t.Errorf("expected %v got %v for %s", test.expected, resp.StatusCode, test.path)
+		}
+		if resp.StatusCode != 200 {
+			continue
+		}
+
+		var status Status
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if status.Name != "api" || len(status.Dependencies) != 1 || status.Dependencies[0].Level != "LEVEL_HARD" {
+			t.Errorf("unexpected status %+v", status)
+		}
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	check := healthtest.NewServiceCheck("api", healthtest.Hard("db", true))
+	server := httptest.NewServer(Handler(check))
+	defer server.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		writeEnvelope(pw, 0, []byte("{}"))
+		pw.Close()
+	}()
+
+	resp, err := http.Post(server.URL+"/"+ServiceName+"/WatchStatus", "application/connect+json", pr)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	defer resp.Body.Close()
+
+	body := bufio.NewReader(resp.Body)
+	read := func() Status {
+		var header [5]byte
+		if _, err := io.ReadFull(body, header[:]); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		io.ReadFull(body, msg)
+
+		var status Status
+		if err := json.Unmarshal(msg, &status); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		return status
+	}
+
+	if status := read(); len(status.Dependencies) != 1 {
+		t.Errorf("expected 1 dependency got %d", len(status.Dependencies))
+	}
+
+	check.RegisterDependency("cache", health.LevelSoft, func() bool { return false })
+	if status := read(); len(status.Dependencies) != 2 {
+		t.Errorf("expected 2 dependencies got %d", len(status.Dependencies))
+	}
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// RuntimeOption configures the checks registered by RegisterRuntimeChecks
+type RuntimeOption func(*runtimeChecks)
+
+// runtimeChecks are the limits of the process checked by
+// RegisterRuntimeChecks, zero being unchecked
+type runtimeChecks struct {
+	maxGoroutines int
+	maxHeapBytes  uint64
+	maxGCPause    time.Duration
+	level         Level
+}
+
+// WithMaxGoroutines registers the runtime-goroutines dependency, unhealthy
+// whilst the process has more than `max` goroutines
+func WithMaxGoroutines(max int) RuntimeOption {
+	return func(r *runtimeChecks) {
+		r.maxGoroutines = max
+	}
+}
+
+// WithMaxHeapBytes registers the runtime-heap dependency, unhealthy whilst
+// more than `max` bytes are allocated on the heap
+func WithMaxHeapBytes(max uint64) RuntimeOption {
+	return func(r *runtimeChecks) {
+		r.maxHeapBytes = max
+	}
+}
+
+// WithMaxGCPause registers the runtime-gc-pause dependency, unhealthy whilst
+// the last garbage collection paused the process for longer than `max`
+func WithMaxGCPause(max time.Duration) RuntimeOption {
+	return func(r *runtimeChecks) {
+		r.maxGCPause = max
+	}
+}
+
+// WithRuntimeLevel registers the runtime dependencies at `level`, LevelSoft
+// by default, so an overloaded process degrades rather than fails
+func WithRuntimeLevel(level Level) RuntimeOption {
+	return func(r *runtimeChecks) {
+		r.level = level
+	}
+}
+
+// RegisterRuntimeChecks registers dependencies checking the process itself,
+// one for each limit set by `opts`, such as WithMaxGoroutines, so operators
+// get process level health without writing checks of their own. The reason a
+// runtime dependency is unhealthy is recorded as its LastError. Without any
+// limits it returns ErrNoCheck.
+//
+//	check.RegisterRuntimeChecks(health.WithMaxGoroutines(5000), health.WithMaxHeapBytes(2<<30))
+func (s *ServiceCheck) RegisterRuntimeChecks(opts ...RuntimeOption) error {
+	r := runtimeChecks{level: LevelSoft}
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	if r.maxGoroutines <= 0 && r.maxHeapBytes == 0 && r.maxGCPause <= 0 {
+		return ErrNoCheck
+	}
+
+	for _, c := range []struct {
+		limited bool
+		name    string
+		check   func(ctx context.Context) (bool, error)
+	}{
+		{r.maxGoroutines > 0, "runtime-goroutines", r.goroutines},
+		{r.maxHeapBytes > 0, "runtime-heap", r.heap},
+		{r.maxGCPause > 0, "runtime-gc-pause", r.gcPause},
+	} {
+		if !c.limited {
+			continue
+		}
+		if _, err := s.RegisterDependencyContext(c.name, r.level, c.check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r runtimeChecks) goroutines(context.Context) (bool, error) {
+	if n := runtime.NumGoroutine(); n > r.maxGoroutines {
+		return false, fmt.Errorf("%d goroutines exceed the maximum of %d", n, r.maxGoroutines)
+	}
+	return true, nil
+}
+
+func (r runtimeChecks) heap(context.Context) (bool, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > r.maxHeapBytes {
+		return false, fmt.Errorf("%d heap bytes exceed the maximum of %d", stats.HeapAlloc, r.maxHeapBytes)
+	}
+	return true, nil
+}
+
+func (r runtimeChecks) gcPause(context.Context) (bool, error) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.NumGC == 0 {
+		return true, nil
+	}
+
+	// PauseNs is a circular buffer of the most recent pauses
+	pause := time.Duration(stats.PauseNs[(stats.NumGC+255)%256])
+	if pause > r.maxGCPause {
+		return false, fmt.Errorf("GC pause of %v exceeds the maximum of %v", pause, r.maxGCPause)
+	}
+	return true, nil
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRegisterRuntimeChecks(t *testing.T) {
+	runtime.GC()
+
+	tests := []struct {
+		opts     []RuntimeOption
+		name     string
+		expected bool
+	}{
+		{[]RuntimeOption{WithMaxGoroutines(1 << 20)}, "runtime-goroutines", true},
+		{[]RuntimeOption{WithMaxGoroutines(1)}, "runtime-goroutines", false},
+		{[]RuntimeOption{WithMaxHeapBytes(1 << 50)}, "runtime-heap", true},
+		{[]RuntimeOption{WithMaxHeapBytes(1)}, "runtime-heap", false},
+		{[]RuntimeOption{WithMaxGCPause(time.Hour)}, "runtime-gc-pause", true},
+		{[]RuntimeOption{WithMaxGCPause(time.Nanosecond)}, "runtime-gc-pause", false},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute)
+		if err := check.RegisterRuntimeChecks(test.opts...); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+
+		dep, err := check.Dependency(test.name)
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if dep.IsHealthy() != test.expected || dep.Level != LevelSoft {
+			t.Errorf("expected a soft dependency healthy %v got %v for %s", test.expected, dep.IsHealthy(), test.name)
+		}
+		if state := check.DependencyStates()[0]; !test.expected && state.LastError == "" {
+			t.Errorf("expected the reason to be recorded for %s", test.name)
+		}
+	}
+}
+
+func TestRegisterRuntimeChecksOptions(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	if err := check.RegisterRuntimeChecks(); !errors.Is(err, ErrNoCheck) {
+		t.Errorf("expected %v got %v", ErrNoCheck, err)
+	}
+
+	err := check.RegisterRuntimeChecks(WithMaxGoroutines(1), WithMaxHeapBytes(1), WithRuntimeLevel(LevelHard))
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	check.Update()
+	if len(check.DependencyStates()) != 2 || check.IsHealthy() {
+		t.Errorf("expected 2 failing hard dependencies got %d", len(check.DependencyStates()))
+	}
+}
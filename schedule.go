@@ -0,0 +1,69 @@
+package health
+
+import "time"
+
+// SchedulePolicy decides when each dependency is checked, see
+// WithSchedulePolicy. To check hard dependencies more often than soft ones,
+// shorten the check's duration and check the soft ones every few cycles.
+type SchedulePolicy struct {
+	// HardFirst checks the hard dependencies before the soft ones in each
+	// cycle, whatever the check's Order
+	HardFirst bool
+	// SoftEvery checks the soft dependencies only every SoftEvery cycles,
+	// starting with the first, rather than every cycle
+	SoftEvery int
+}
+
+// WithSchedulePolicy sets when each dependency is checked, by default every
+// dependency is checked every cycle in the check's Order
+func WithSchedulePolicy(policy SchedulePolicy) Option {
+	return func(s *ServiceCheck) {
+		s.schedule = policy
+	}
+}
+
+// due returns the dependencies to check in the next cycle, in the order to
+// check them, s.mu must be held
+func (s *ServiceCheck) due() []*Dependency {
+	soft := s.schedule.SoftEvery <= 1 || s.cycles%s.schedule.SoftEvery == 0
+	s.cycles++
+
+	if !s.schedule.HardFirst && soft {
+		return s.Dependencies
+	}
+
+	due := make([]*Dependency, 0, len(s.Dependencies))
+	for _, dependency := range s.Dependencies {
+		if dependency.Level == LevelHard || soft && !s.schedule.HardFirst {
+			due = append(due, dependency)
+		}
+	}
+	if !soft || !s.schedule.HardFirst {
+		return due
+	}
+
+	for _, dependency := range s.Dependencies {
+		if dependency.Level != LevelHard {
+			due = append(due, dependency)
+		}
+	}
+	return due
+}
+
+// interval returns how long StartSpread waits between checks of `dep`
+func (s *ServiceCheck) interval(dep *Dependency) time.Duration {
+	s.mu.RLock()
+	adaptive := dep.adaptive
+	s.mu.RUnlock()
+	if adaptive != nil {
+		return adaptive.current()
+	}
+	if dep.ttl > s.duration || dep.interval > s.duration {
+		return max(dep.ttl, dep.interval)
+	}
+	if dep.Level != LevelHard && s.schedule.SoftEvery > 1 {
+		return s.duration * time.Duration(s.schedule.SoftEvery)
+	}
+
+	return s.duration
+}
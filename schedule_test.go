@@ -0,0 +1,82 @@
+package health
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSchedulePolicy(t *testing.T) {
+	tests := []struct {
+		policy   SchedulePolicy
+		expected [][]string
+	}{
+		{
+			SchedulePolicy{},
+			[][]string{{"cache", "db", "queue"}, {"cache", "db", "queue"}, {"cache", "db", "queue"}},
+		},
+		{
+			SchedulePolicy{HardFirst: true},
+			[][]string{{"db", "cache", "queue"}, {"db", "cache", "queue"}, {"db", "cache", "queue"}},
+		},
+		{
+			SchedulePolicy{SoftEvery: 2},
+			[][]string{{"cache", "db", "queue"}, {"db"}, {"cache", "db", "queue"}},
+		},
+		{
+			SchedulePolicy{HardFirst: true, SoftEvery: 3},
+			[][]string{{"db", "cache", "queue"}, {"db"}, {"db"}},
+		},
+	}
+
+	for _, test := range tests {
+		var checked []string
+		record := func(name string) func() bool {
+			return func() bool {
+				checked = append(checked, name)
+				return true
+			}
+		}
+
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithSchedulePolicy(test.policy))
+		check.RegisterDependency("cache", LevelSoft, record("cache"))
+		check.RegisterDependency("db", LevelHard, record("db"))
+		check.RegisterDependency("queue", LevelSoft, record("queue"))
+
+		for i, expected := range test.expected {
+			checked = nil
+			check.Update()
+			if !reflect.DeepEqual(checked, expected) {
+				t.Errorf("expected %v got %v in cycle %d for %+v", expected, checked, i, test.policy)
+			}
+		}
+	}
+}
+
+func TestSchedulePolicySpread(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		runs = make(map[string]int)
+	)
+	record := func(name string) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			runs[name]++
+			return true
+		}
+	}
+
+	check, _ := InitialiseServiceCheck("api", 50*time.Millisecond, WithSchedulePolicy(SchedulePolicy{SoftEvery: 4}))
+	check.RegisterDependency("db", LevelHard, record("db"))
+	check.RegisterDependency("cache", LevelSoft, record("cache"))
+	check.StartSpread(2)
+	time.Sleep(500 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs["db"] < 2*runs["cache"] {
+		t.Errorf("expected db to be checked more often than cache got %d and %d", runs["db"], runs["cache"])
+	}
+}
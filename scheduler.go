@@ -0,0 +1,203 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scheduler checks several ServiceChecks from a single loop and a shared pool
+// of workers, in place of calling StartCheck on each, for binaries hosting many
+// services. Each check keeps its own duration and results, and is started as
+// by StartCheck, so StopCheck stops it and WithWatchdog watches it. Use
+// NewScheduler to instantiate one
+type Scheduler struct {
+	workers int
+	clock   Clock
+
+	checks []*scheduled
+	wake   chan struct{}
+	mu     sync.Mutex
+	// ctx is the context of Start, nil until it is called
+	ctx context.Context
+}
+
+// scheduled is a ServiceCheck on a Scheduler
+type scheduled struct {
+	check   *ServiceCheck
+	next    time.Time
+	running bool
+	// stop is closed by StopCheck, and done releases the check's loops, once
+	// the scheduler has started the check
+	stop <-chan struct{}
+	done func()
+}
+
+// NewScheduler returns a Scheduler running at most `workers` checks at once
+func NewScheduler(workers int) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Scheduler{
+		workers: workers,
+		clock:   realClock{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Add schedules `check` to be checked every duration it was initialised with,
+// starting straight away. It is safe to call whilst the scheduler is running.
+func (sc *Scheduler) Add(check *ServiceCheck) {
+	job := &scheduled{check: check, next: sc.clock.Now()}
+
+	sc.mu.Lock()
+	sc.checks = append(sc.checks, job)
+	if sc.ctx != nil {
+		sc.attach(sc.ctx, job)
+	}
+	sc.mu.Unlock()
+
+	sc.poke()
+}
+
+// Start runs the checks in the background until ctx is cancelled, when each
+// is stopped as by StopCheck. A check which is still running when it is next
+// due is skipped until it finishes. A check stopped with StopCheck is dropped
+// from the scheduler.
+func (sc *Scheduler) Start(ctx context.Context) {
+	sc.mu.Lock()
+	sc.ctx = ctx
+	for _, job := range sc.checks {
+		sc.attach(ctx, job)
+	}
+	sc.mu.Unlock()
+
+	jobs := make(chan *scheduled)
+	var workers sync.WaitGroup
+	for i := 0; i < sc.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				job.check.scheduledUpdate()
+
+				sc.mu.Lock()
+				job.running = false
+				job.next = sc.clock.Now().Add(job.check.duration)
+				sc.mu.Unlock()
+
+				sc.poke()
+			}
+		}()
+	}
+
+	go func() {
+		defer sc.stop(&workers)
+		defer close(jobs)
+		for {
+			due, wait := sc.due()
+			for _, job := range due {
+				select {
+				case jobs <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			var timer <-chan time.Time
+			if wait >= 0 {
+				timer = sc.clock.After(wait)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-sc.wake:
+			case <-timer:
+			}
+		}
+	}()
+}
+
+// attach starts `job`'s check as StartCheck does, waking the scheduler to
+// drop it once it is stopped. sc.mu must be held.
+func (sc *Scheduler) attach(ctx context.Context, job *scheduled) {
+	job.stop, job.done = job.check.start()
+
+	stop := job.stop
+	go func() {
+		select {
+		case <-stop:
+			sc.poke()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// stop stops every check once the workers have finished the checks in flight
+func (sc *Scheduler) stop(workers *sync.WaitGroup) {
+	workers.Wait()
+
+	sc.mu.Lock()
+	checks := sc.checks
+	sc.checks, sc.ctx = nil, nil
+	sc.mu.Unlock()
+
+	for _, job := range checks {
+		job.done()
+		job.check.StopCheck()
+	}
+}
+
+// poke wakes the scheduler's loop, if it isn't already due to wake
+func (sc *Scheduler) poke() {
+	select {
+	case sc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// due marks the checks which are due as running and returns them, along with
+// how long until the next check is due, or -1 if none is waiting. Checks
+// which have been stopped are dropped.
+func (sc *Scheduler) due() ([]*scheduled, time.Duration) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	var (
+		now  = sc.clock.Now()
+		due  []*scheduled
+		wait = time.Duration(-1)
+	)
+	kept := sc.checks[:0]
+	for _, job := range sc.checks {
+		if job.running {
+			kept = append(kept, job)
+			continue
+		}
+
+		// the check was stopped by StopCheck
+		select {
+		case <-job.stop:
+			job.done()
+			continue
+		default:
+		}
+		kept = append(kept, job)
+
+		if until := job.next.Sub(now); until > 0 {
+			if wait < 0 || until < wait {
+				wait = until
+			}
+			continue
+		}
+
+		job.running = true
+		due = append(due, job)
+	}
+	clear(sc.checks[len(kept):])
+	sc.checks = kept
+
+	return due, wait
+}
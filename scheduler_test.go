@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var fast, slow atomic.Int32
+	fastCheck, _ := InitialiseServiceCheck("fast", 10*time.Millisecond)
+	fastCheck.RegisterDependency("db", LevelHard, func() bool { fast.Add(1); return true })
+	slowCheck, _ := InitialiseServiceCheck("slow", time.Hour)
+	slowCheck.RegisterDependency("db", LevelHard, func() bool { slow.Add(1); return false })
+
+	sc := NewScheduler(2)
+	sc.Add(fastCheck)
+	sc.Start(ctx)
+	// checks can be added whilst running
+	sc.Add(slowCheck)
+
+	deadline := time.Now().Add(time.Second)
+	for (fast.Load() < 5 || slowCheck.IsHealthy()) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if fast.Load() < 5 {
+		t.Errorf("expected the fast check to run repeatedly got %d", fast.Load())
+	}
+	if slowCheck.IsHealthy() || !fastCheck.IsHealthy() {
+		t.Error("expected each check to keep its own results")
+	}
+	// once at registration and once scheduled
+	if slow.Load() != 2 {
+		t.Errorf("expected 2 runs of the slow check got %d", slow.Load())
+	}
+}
+
+func TestSchedulerSkipsRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var running, overlaps atomic.Int32
+	check, _ := InitialiseServiceCheck("api", time.Millisecond)
+	check.RegisterDependency("db", LevelHard, func() bool {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer running.Add(-1)
+		time.Sleep(5 * time.Millisecond)
+		return true
+	})
+
+	sc := NewScheduler(4)
+	sc.Add(check)
+	sc.Start(ctx)
+	time.Sleep(20 * time.Millisecond)
+
+	if overlaps.Load() != 0 {
+		t.Errorf("expected no overlapping runs got %d", overlaps.Load())
+	}
+}
+
+func TestSchedulerStop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	var healthy atomic.Bool
+	check, _ := InitialiseServiceCheck("api", time.Millisecond)
+	check.RegisterDependency("db", LevelHard, func() bool { runs.Add(1); return healthy.Load() })
+
+	sc := NewScheduler(1)
+	sc.Add(check)
+	sc.Start(ctx)
+	time.Sleep(10 * time.Millisecond)
+
+	// ensure StopCheck stops a scheduled check
+	check.StopCheck()
+	stopped := runs.Load()
+	time.Sleep(10 * time.Millisecond)
+	if got := runs.Load(); got != stopped {
+		t.Errorf("expected %v got %v", stopped, got)
+	}
+
+	// ensure the check can be waited upon once the scheduler stops, whilst
+	// unhealthy
+	other, _ := InitialiseServiceCheck("other", time.Millisecond)
+	other.RegisterDependency("db", LevelHard, func() bool { return healthy.Load() })
+	sc.Add(other)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	healthy.Store(true)
+	if !other.WaitForDependencies(time.Second) {
+		t.Errorf("expected %v got %v", true, false)
+	}
+}
+
+func TestSchedulerWatchdog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	check, _ := InitialiseServiceCheck("api", 5*time.Millisecond, WithWatchdog(2, LevelHard))
+	release := make(chan struct{})
+	defer close(release)
+	var block atomic.Bool
+	check.RegisterDependency("db", LevelHard, func() bool {
+		if block.Load() {
+			<-release
+		}
+		return true
+	})
+
+	sc := NewScheduler(1)
+	sc.Add(check)
+	sc.Start(ctx)
+
+	// ensure the watchdog watches scheduled checks
+	block.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for check.IsHealthy() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if check.IsHealthy() {
+		t.Errorf("expected %v got %v", false, true)
+	}
+}
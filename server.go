@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// DefaultHealthPath is where the built in servers serve the status
+const DefaultHealthPath = "/health"
+
+// ShutdownTimeout bounds how long the built in servers wait for in-flight
+// requests when their context is cancelled
+var ShutdownTimeout = 5 * time.Second
+
+// Serve serves the status at DefaultHealthPath on a dedicated http.Server at
+// `addr` until ctx is cancelled, when in-flight requests are given up to
+// ShutdownTimeout to complete. HTTP/1.1 and h2c are both accepted.
+func (s *ServiceCheck) Serve(ctx context.Context, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, lis, nil)
+}
+
+// ServeTLS is like Serve but serves over TLS configured by `config`, which
+// must provide a certificate. HTTP/2 is negotiated via ALPN. To require and
+// verify client certificates set ClientAuth to tls.RequireAndVerifyClientCert
+// and ClientCAs to the pool of trusted CAs.
+func (s *ServiceCheck) ServeTLS(ctx context.Context, addr string, config *tls.Config) error {
+	if config == nil || (len(config.Certificates) == 0 && config.GetCertificate == nil && config.GetConfigForClient == nil) {
+		return ErrNoCertificate
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, lis, config)
+}
+
+// ServeUnix serves the status at DefaultHealthPath on the unix socket at
+// `path` until ctx is cancelled, so node local agents can probe the service
+// without another TCP port. A stale socket left at `path` is replaced, and the
+// socket is removed on return. Access is governed by the permissions of the
+// directory containing the socket.
+func (s *ServiceCheck) ServeUnix(ctx context.Context, path string) error {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, lis, nil)
+}
+
+// routes returns the handler of the built in servers
+func (s *ServiceCheck) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(DefaultHealthPath, s.HTTPHandler)
+	return mux
+}
+
+// serve serves routes on `lis`, over TLS if `config` is set, until ctx is
+// cancelled, then shuts down gracefully
+func (s *ServiceCheck) serve(ctx context.Context, lis net.Listener, config *tls.Config) error {
+	// accept HTTP/2 without TLS, h2c with prior knowledge, as well as over
+	// TLS so HTTP/2 only probes work on every listener
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	server := &http.Server{
+		Handler:           s.routes(),
+		Protocols:         protocols,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if config != nil {
+			server.TLSConfig = config.Clone()
+			errs <- server.ServeTLS(lis, "", "")
+			return
+		}
+		errs <- server.Serve(lis)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,235 @@
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := lis.Addr().String()
+	lis.Close()
+
+	check := &ServiceCheck{Name: "test"}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- check.Serve(ctx, addr) }()
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + DefaultHealthPath); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 503 {
+		t.Errorf("expected 503 got %v", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if _, err := http.Get("http://" + addr + DefaultHealthPath); err == nil {
+		t.Error("expected the server to be shut down")
+	}
+}
+
+func TestServeH2C(t *testing.T) {
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := lis.Addr().String()
+	lis.Close()
+
+	check := &ServiceCheck{Name: "test", Healthy: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go check.Serve(ctx, addr)
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://" + addr + DefaultHealthPath); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.ProtoMajor != 2 || resp.StatusCode != 200 {
+		t.Errorf("expected HTTP/2 200 got %s %v", resp.Proto, resp.StatusCode)
+	}
+}
+
+func TestServeUnix(t *testing.T) {
+	dir, err := os.MkdirTemp("", "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "health.sock")
+
+	// a stale socket from a previous run is replaced
+	stale, _ := net.Listen("unix", path)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	check := &ServiceCheck{Name: "test", Healthy: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- check.ServeUnix(ctx, path) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+	}}
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://unix" + DefaultHealthPath); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Errorf("expected 200 got %v", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the socket to be removed got %v", err)
+	}
+}
+
+// issue returns a certificate for `name` signed by `ca`, or self signed if
+// `ca` is nil
+func issue(t *testing.T, name string, ca *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	parent, signer := template, interface{}(key)
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		parent, signer = ca.Leaf, ca.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ := x509.ParseCertificate(der)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestServeTLS(t *testing.T) {
+	ca := issue(t, "ca", nil)
+	serverCert := issue(t, "server", &ca)
+	clientCert := issue(t, "client", &ca)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := lis.Addr().String()
+	lis.Close()
+
+	check := &ServiceCheck{Name: "test", Healthy: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go check.ServeTLS(ctx, addr, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+
+	get := func(certs ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			ForceAttemptHTTP2: true,
+			TLSClientConfig: &tls.Config{
+				RootCAs:      pool,
+				Certificates: certs,
+			},
+		}}
+
+		var (
+			resp *http.Response
+			err  error
+		)
+		for i := 0; i < 50; i++ {
+			if resp, err = client.Get("https://" + addr + DefaultHealthPath); err == nil {
+				resp.Body.Close()
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		return resp, err
+	}
+
+	resp, err := get(clientCert)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Errorf("expected 200 got %v", resp.StatusCode)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("expected HTTP/2 got %s", resp.Proto)
+	}
+
+	if _, err := get(); err == nil {
+		t.Error("expected a client without a certificate to be rejected")
+	}
+
+	if err := check.ServeTLS(ctx, addr, &tls.Config{}); err != ErrNoCertificate {
+		t.Errorf("expected %v got %v", ErrNoCertificate, err)
+	}
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// shards is the number of locks the results of dependencies are recorded
+// under, see recordResult
+const shards = 32
+
+// shard returns the lock guarding the results of `dep`, picked by an FNV-1a
+// hash of its name
+func (s *ServiceCheck) shard(dep *Dependency) *sync.Mutex {
+	hash := uint32(2166136261)
+	for i := 0; i < len(dep.Name); i++ {
+		hash ^= uint32(dep.Name[i])
+		hash *= 16777619
+	}
+
+	return &s.shards[hash%shards]
+}
+
+// recordResult records a result of checking `dep` which doesn't change its
+// health, holding s.mu only for reading along with the dependency's shard, so
+// the results of thousands of dependencies are recorded without contending
+// with each other or with readers. Rather than a snapshot being published per
+// result, the next reader takes one, see load. It returns false if the result
+// changes the health of the dependency, and so that of the service, which is
+// left to be recorded under the write lock. Results from before StopCheck or
+// of unregistered dependencies are discarded.
+func (s *ServiceCheck) recordResult(dep *Dependency, epoch uint64, healthy bool, start time.Time, took time.Duration) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.epoch != epoch || !s.registered(dep) {
+		return true
+	}
+
+	shard := s.shard(dep)
+	shard.Lock()
+	defer shard.Unlock()
+	// a passing check which is too slow is recorded as failing, see
+	// WithMaxLatency
+	if dep.Healthy != (healthy && !dep.tooSlow(took)) {
+		return false
+	}
+
+	dep.record(healthy, start, took)
+	s.remember(dep)
+	s.setLastChecked(s.getClock().Now())
+	s.version.Add(1)
+	return true
+}
+
+// setLastChecked records when the dependencies were last checked
+func (s *ServiceCheck) setLastChecked(checked time.Time) {
+	s.lastChecked.Store(&checked)
+}
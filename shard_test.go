@@ -0,0 +1,76 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordResult(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true })
+	published := check.view.Load()
+
+	clock.advance(time.Second, 0)
+	if !check.recordResult(db, 0, true, clock.Now(), time.Millisecond) {
+		t.Error("expected an unchanged result to be recorded")
+	}
+	if check.view.Load() != published {
+		t.Error("expected no snapshot to be published per result")
+	}
+
+	// the next reader takes a snapshot
+	if checked := check.LastChecked(); !checked.Equal(clock.Now()) {
+		t.Errorf("expected %v got %v", clock.Now(), checked)
+	}
+	if check.view.Load() == published {
+		t.Error("expected the reader to publish a snapshot")
+	}
+
+	if check.recordResult(db, 0, false, clock.Now(), time.Millisecond) {
+		t.Error("expected a change of health to be left to the write lock")
+	}
+	if !db.IsHealthy() {
+		t.Error("expected db to be left healthy")
+	}
+
+	// a passing check which is too slow changes the health too
+	db.maxLatency = 10 * time.Millisecond
+	if check.recordResult(db, 0, true, clock.Now(), time.Second) {
+		t.Error("expected a slow result to be left to the write lock")
+	}
+	db.maxLatency = 0
+
+	// results from before StopCheck are discarded
+	check.StopCheck()
+	if !check.recordResult(db, 0, true, clock.Now(), time.Millisecond) {
+		t.Error("expected a stale result to be discarded")
+	}
+}
+
+func TestShardedResults(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Millisecond)
+	for i := 0; i < 200; i++ {
+		check.RegisterDependency(fmt.Sprintf("shard-%d", i), LevelHard, func() bool { return true })
+	}
+	check.StartSpread(8)
+	defer check.StopCheck()
+
+	// readers run alongside the workers recording results
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if !check.IsHealthy() || len(check.DependencyStates()) != 200 {
+					t.Error("expected every dependency to be healthy")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
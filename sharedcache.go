@@ -0,0 +1,127 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// WithSharedCache shares the dependency's result with dependencies of this or
+// any other ServiceCheck using the same `key`, for `ttl` after it was
+// checked, so several services in one process checking the same resource
+// only check it once per `ttl`. An empty key is the dependency's name and
+// URL. Concurrent checks missing the cache are collapsed as by WithDedupKey.
+// A result is dropped once every dependency sharing it is unregistered or its
+// check stopped.
+func WithSharedCache(key string, ttl time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		d.check = d.shared(key, ttl, d.check)
+	}
+}
+
+// cachedResult is a result shared by WithSharedCache
+type cachedResult struct {
+	healthy bool
+	checked time.Time
+}
+
+// sharedEntry is the result shared under a WithSharedCache key, along with
+// the number of registered dependencies using it, so it is dropped with the
+// last of them
+type sharedEntry struct {
+	result cachedResult
+	cached bool
+	refs   int
+}
+
+// sharedResults are the results shared per WithSharedCache key
+var sharedResults = struct {
+	sync.Mutex
+	results map[string]*sharedEntry
+}{results: make(map[string]*sharedEntry)}
+
+// sharedRef is a dependency's reference to the entry of its WithSharedCache
+// key, taken when it is first checked. sharedResults must be held.
+type sharedRef struct {
+	key  string
+	held bool
+}
+
+// acquire returns the entry for `key`, referencing it if not already
+func (r *sharedRef) acquire(key string) *sharedEntry {
+	if r.held && r.key != key {
+		r.release()
+	}
+
+	entry, ok := sharedResults.results[key]
+	if !ok {
+		entry = &sharedEntry{}
+		sharedResults.results[key] = entry
+	}
+	if !r.held {
+		entry.refs++
+		r.key, r.held = key, true
+	}
+	return entry
+}
+
+// release drops the reference, deleting the entry once it is unreferenced
+func (r *sharedRef) release() {
+	if !r.held {
+		return
+	}
+
+	r.held = false
+	entry, ok := sharedResults.results[r.key]
+	if !ok {
+		return
+	}
+	if entry.refs--; entry.refs <= 0 {
+		delete(sharedResults.results, r.key)
+	}
+}
+
+// releaseShared releases the dependency's WithSharedCache entry, if any, once
+// it is unregistered or stopped
+func (d *Dependency) releaseShared() {
+	if d.sharedRef == nil {
+		return
+	}
+
+	sharedResults.Lock()
+	d.sharedRef.release()
+	sharedResults.Unlock()
+}
+
+// shared returns `check` sharing its result for `ttl` with the checks of
+// other dependencies keyed by `key`
+func (d *Dependency) shared(key string, ttl time.Duration, check func() bool) func() bool {
+	ref := &sharedRef{}
+	d.sharedRef = ref
+	return func() bool {
+		key := key
+		if key == "" {
+			// the URL may be set by an option applied after this one
+			key = d.Name + " " + d.URL
+		}
+
+		clock := d.clock()
+		sharedResults.Lock()
+		entry := ref.acquire(key)
+		result, ok := entry.result, entry.cached
+		sharedResults.Unlock()
+		if ok && clock.Now().Sub(result.checked) < ttl {
+			return result.healthy
+		}
+
+		result = cachedResult{checked: clock.Now()}
+		result.healthy = dedup("shared "+key, check)()
+
+		sharedResults.Lock()
+		// the dependency may have been released whilst it was being checked
+		if ref.held {
+			entry.result, entry.cached = result, true
+		}
+		sharedResults.Unlock()
+		return result.healthy
+	}
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+// resetSharedResults clears the results shared by earlier tests
+func resetSharedResults() {
+	sharedResults.Lock()
+	clear(sharedResults.results)
+	sharedResults.Unlock()
+}
+
+func TestWithSharedCache(t *testing.T) {
+	resetSharedResults()
+	clock := &fakeClock{now: time.Now()}
+	first, _ := InitialiseServiceCheck("first", time.Second, WithClock(clock))
+	second, _ := InitialiseServiceCheck("second", time.Second, WithClock(clock))
+
+	var runs int
+	healthy := true
+	probe := func() bool {
+		runs++
+		return healthy
+	}
+
+	firstDB, _ := first.RegisterDependency("db", LevelHard, probe, WithSharedCache("tcp://db:5432", time.Minute))
+	secondDB, _ := second.RegisterDependency("db", LevelHard, probe, WithSharedCache("tcp://db:5432", time.Minute))
+	if runs != 1 {
+		t.Errorf("expected 1 run got %d", runs)
+	}
+
+	// ensure the result is reused within the TTL
+	healthy = false
+	first.Update()
+	second.Update()
+	if runs != 1 || !firstDB.IsHealthy() || !secondDB.IsHealthy() {
+		t.Errorf("expected the cached healthy result got %d runs", runs)
+	}
+
+	clock.advance(time.Minute, 0)
+	first.Update()
+	second.Update()
+	if runs != 2 || firstDB.IsHealthy() || secondDB.IsHealthy() {
+		t.Errorf("expected a single check after the TTL got %d runs", runs)
+	}
+}
+
+func TestWithSharedCacheDefaultKey(t *testing.T) {
+	resetSharedResults()
+	check, _ := InitialiseServiceCheck("api", time.Second)
+
+	var runs int
+	probe := func() bool {
+		runs++
+		return true
+	}
+
+	check.RegisterDependency("shared-default-key", LevelHard, probe, WithSharedCache("", time.Minute))
+	check.RegisterDependency("shared-default-key-other", LevelHard, probe, WithSharedCache("", time.Minute))
+	if runs != 2 {
+		t.Errorf("expected dependencies with different names not to share got %d runs", runs)
+	}
+}
+
+func TestWithSharedCacheRelease(t *testing.T) {
+	resetSharedResults()
+	first, _ := InitialiseServiceCheck("first", time.Second)
+	second, _ := InitialiseServiceCheck("second", time.Second)
+
+	probe := func() bool { return true }
+	first.RegisterDependency("db", LevelHard, probe, WithSharedCache("tcp://db:5432", time.Minute))
+	second.RegisterDependency("db", LevelHard, probe, WithSharedCache("tcp://db:5432", time.Minute))
+	second.RegisterDependency("cache", LevelHard, probe, WithSharedCache("", time.Minute))
+
+	tests := []struct {
+		release  func()
+		expected int
+	}{
+		// the entry is kept whilst any dependency shares it
+		{func() { first.UnregisterDependency("db") }, 2},
+		{func() { second.UnregisterDependency("db") }, 1},
+		{func() { second.StopCheck() }, 0},
+		// a stopped check takes its results again when it next checks
+		{func() { second.Update() }, 1},
+	}
+
+	for i, test := range tests {
+		test.release()
+
+		sharedResults.Lock()
+		entries := len(sharedResults.results)
+		sharedResults.Unlock()
+		if entries != test.expected {
+			t.Errorf("expected %v got %v on test case #%d", test.expected, entries, i)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package sidecar runs health checks described in a config file on behalf of
+// another application and serves the standard health endpoint for it, so
+// services not written in Go can report in the same format. The checks are
+// described as per package healthconfig.
+//
+// A config file looks like:
+//
+//	{
+//		"name": "legacy-billing",
+//		"listen": ":8081",
+//		"interval": "10s",
+//		"checks": [
+//			{"name": "app", "type": "http", "target": "http://localhost:8080/ping", "level": "hard"},
+//			{"name": "db", "type": "tcp", "target": "db:5432", "level": "hard", "timeout": "1s"},
+//			{"name": "queue", "type": "exec", "target": "/usr/local/bin/check-queue", "level": "soft"}
+//		]
+//	}
+package sidecar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fresh8/health"
+	"github.com/fresh8/health/healthconfig"
+)
+
+// Defaults applied to unset config values
+const (
+	DefaultListen = ":8080"
+	DefaultPath   = "/health"
+)
+
+// Duration is a time.Duration which is written as a string such as "10s" in
+// config files
+type Duration = healthconfig.Duration
+
+// CheckConfig describes a single dependency check, see healthconfig.Check for
+// the supported types
+type CheckConfig = healthconfig.Check
+
+// Config describes the checks to run and where to serve their status
+type Config struct {
+	// Name of the service reported in the status
+	Name string `json:"name"`
+	// Listen is the address to serve on, DefaultListen if empty. Overridden by
+	// HEALTH_LISTEN.
+	Listen string `json:"listen"`
+	// Path to serve the status on, DefaultPath if empty
+	Path string `json:"path"`
+	// Interval between checks, healthconfig.DefaultInterval if not set
+	Interval Duration `json:"interval"`
+	// Checks to run
+	Checks []CheckConfig `json:"checks"`
+}
+
+// LoadConfig reads a config file, decoding it as YAML if its extension is
+// .yaml or .yml, see healthconfig.YAMLUnmarshal, and as JSON otherwise
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := healthconfig.Unmarshal(b, healthconfig.IsYAML(path), &config); err != nil {
+		return nil, fmt.Errorf("sidecar: %s: %v", path, err)
+	}
+
+	return &config, nil
+}
+
+// ServiceCheck builds a ServiceCheck with a dependency for every check in the
+// config, after applying any environment overrides, see
+// healthconfig.ApplyEnv. The check isn't started.
+func (c *Config) ServiceCheck() (*health.ServiceCheck, error) {
+	config := &healthconfig.Config{
+		Name:     c.Name,
+		Interval: c.Interval,
+		Checks:   append([]CheckConfig(nil), c.Checks...),
+	}
+	if err := config.ApplyEnv(); err != nil {
+		return nil, err
+	}
+
+	return config.ServiceCheck()
+}
+
+// Run builds and starts the ServiceCheck and serves its status until ctx is
+// cancelled, when the server is shut down gracefully
+func Run(ctx context.Context, c *Config) error {
+	check, err := c.ServiceCheck()
+	if err != nil {
+		return err
+	}
+	check.StartCheck()
+
+	listen, path := c.Listen, c.Path
+	if listen == "" {
+		listen = DefaultListen
+	}
+	listen = healthconfig.Listen(listen)
+	if path == "" {
+		path = DefaultPath
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, check.HTTPHandler)
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.ListenAndServe() }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
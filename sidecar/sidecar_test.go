@@ -0,0 +1,113 @@
+package sidecar
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{
+		"name": "legacy",
+		"interval": "5s",
+		"checks": [{"name": "db", "type": "tcp", "target": "db:5432", "level": "hard", "timeout": "1s"}]
+	}`), 0600)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if config.Name != "legacy" || time.Duration(config.Interval) != 5*time.Second {
+		t.Errorf("expected legacy 5s got %s %v", config.Name, time.Duration(config.Interval))
+	}
+	if len(config.Checks) != 1 || time.Duration(config.Checks[0].Timeout) != time.Second {
+		t.Errorf("expected one check with a 1s timeout got %+v", config.Checks)
+	}
+
+	os.WriteFile(path, []byte(`{"interval": "often"}`), 0600)
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestServiceCheck(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer app.Close()
+
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer lis.Close()
+
+	config := &Config{
+		Name: "legacy",
+		Checks: []CheckConfig{
+			{Name: "app", Type: "http", Target: app.URL, Level: "hard"},
+			{Name: "db", Type: "tcp", Target: lis.Addr().String(), Level: "hard"},
+			{Name: "down", Type: "tcp", Target: "127.0.0.1:1"},
+			{Name: "script", Type: "exec", Target: "true"},
+		},
+	}
+
+	check, err := config.ServiceCheck()
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	expected := map[string]bool{"app": true, "db": true, "down": false, "script": true}
+	for _, dependency := range check.DependencyStates() {
+		if dependency.Healthy != expected[dependency.Name] {
+			t.Errorf("expected %v got %v for %s", expected[dependency.Name], dependency.Healthy, dependency.Name)
+		}
+	}
+}
+
+func TestServiceCheckInvalid(t *testing.T) {
+	tests := []CheckConfig{
+		{Name: "a", Type: "smtp", Target: "mail:25"},
+		{Name: "b", Type: "tcp", Target: "db:5432", Level: "critical"},
+		{Name: "c", Type: "exec", Target: " "},
+	}
+
+	for _, test := range tests {
+		config := &Config{Name: "legacy", Checks: []CheckConfig{test}}
+		if _, err := config.ServiceCheck(); err == nil {
+			t.Errorf("expected an error for %+v", test)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	lis, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := lis.Addr().String()
+	lis.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Run(ctx, &Config{Name: "legacy", Listen: addr}) }()
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + DefaultPath); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Errorf("expected 200 got %v", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected nil got %v", err)
+	}
+}
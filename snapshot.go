@@ -0,0 +1,131 @@
+package health
+
+import "sync"
+
+// snapshot is an immutable copy of the state of a ServiceCheck, published
+// after every change so readers never contend with the checks
+type snapshot struct {
+	// status is a copy of the service and its dependencies, for reading and
+	// encoding
+	status *ServiceCheck
+	// handles are the registered dependencies, for Dependency
+	handles []*Dependency
+
+	// encoded is the status document, encoded once when first served by
+	// FastHTTPHandler
+	encoded     []byte
+	encodedOnce sync.Once
+
+	// version is the version of the results the snapshot was taken at
+	version uint64
+}
+
+// publish swaps in a snapshot of the current state, along with its
+// precomputed health, s.mu must be held
+func (s *ServiceCheck) publish() {
+	version := s.version.Add(1)
+	view := s.snapshot()
+	view.version = version
+	s.view.Store(view)
+	s.state.Store(newState(view.status))
+}
+
+// snapshot copies the current state, s.mu must be held, if only for reading
+func (s *ServiceCheck) snapshot() *snapshot {
+	status := &ServiceCheck{
+		Name:         s.Name,
+		Healthy:      s.Healthy,
+		Dependencies: make([]*Dependency, len(s.Dependencies)),
+		Draining:     s.Draining,
+		ShuttingDown: s.ShuttingDown,
+		Starting:     s.Starting,
+		Maintenance:  s.Maintenance,
+
+		namePolicy:    s.namePolicy,
+		rename:        s.rename,
+		marshalHook:   s.marshalHook,
+		metadata:      s.metadata,
+		redact:        s.redact,
+		checkTimes:    s.checkTimes,
+		degradedCode:  s.degradedCode,
+		aggregator:    s.aggregator,
+		aggregated:    s.aggregated,
+		unhealthyCode: s.unhealthyCode,
+	}
+	status.lastChecked.Store(s.lastChecked.Load())
+	for i, dependency := range s.Dependencies {
+		// results recorded by recordResult are guarded by the shard lock
+		shard := s.shard(dependency)
+		shard.Lock()
+		dep := *dependency
+		shard.Unlock()
+
+		dep.owner = nil
+		status.Dependencies[i] = &dep
+	}
+
+	handles := make([]*Dependency, len(s.Dependencies))
+	copy(handles, s.Dependencies)
+
+	return &snapshot{status: status, handles: handles}
+}
+
+// Snapshot returns a deep copy of the state of the service and its
+// dependencies as of the last check, which may be read, encoded or changed
+// freely without racing with the checks. It encodes as the service does.
+func (s *ServiceCheck) Snapshot() *ServiceCheck {
+	// the published snapshot is shared by every reader, so it is copied
+	return s.load().status.deepCopy()
+}
+
+// deepCopy returns a copy of the service sharing nothing it may change
+func (s *ServiceCheck) deepCopy() *ServiceCheck {
+	s.mu.RLock()
+	status := s.snapshot().status
+	s.mu.RUnlock()
+
+	for _, dependency := range status.Dependencies {
+		if dependency.Override != nil {
+			override := *dependency.Override
+			dependency.Override = &override
+		}
+		if dependency.Remote != nil {
+			dependency.Remote = dependency.Remote.deepCopy()
+		}
+		dependency.Members = append([]GroupMember(nil), dependency.Members...)
+	}
+
+	return status
+}
+
+// load returns the last published snapshot, first taking a new one if results
+// have been recorded since, see recordResult. A ServiceCheck which hasn't
+// changed since it was decoded or built as a literal has none, so a snapshot
+// of its fields is taken instead.
+func (s *ServiceCheck) load() *snapshot {
+	view := s.view.Load()
+	if view != nil && view.version == s.version.Load() {
+		return view
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if view == nil {
+		return s.snapshot()
+	}
+
+	// the version is read first so results recorded whilst taking the
+	// snapshot leave it out of date
+	version := s.version.Load()
+	fresh := s.snapshot()
+	fresh.version = version
+	for {
+		current := s.view.Load()
+		if current.version >= version {
+			return current
+		}
+		if s.view.CompareAndSwap(current, fresh) {
+			return fresh
+		}
+	}
+}
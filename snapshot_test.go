@@ -0,0 +1,103 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSnapshotReadsDontWait(t *testing.T) {
+	var (
+		block   bool
+		checked = make(chan struct{})
+		release = make(chan struct{})
+	)
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool {
+		if block {
+			close(checked)
+			<-release
+		}
+		return true
+	})
+
+	block = true
+	go check.Update()
+	<-checked
+	defer close(release)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		check.IsHealthy()
+		check.Dependency("db")
+		check.HTTPHandler(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("expected reads not to wait for the running check")
+	}
+}
+
+func TestSnapshotPublished(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	db.Pause()
+	if states := check.DependencyStates(); !states[0].Paused {
+		t.Error("expected pausing to be published")
+	}
+
+	db.SetHealthy(false)
+	if check.IsHealthy() {
+		t.Error("expected the change of health to be published")
+	}
+
+	check.UnregisterDependency("db")
+	if _, err := check.Dependency("db"); err == nil {
+		t.Error("expected unregistering to be published")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+	check.ForceDependencyState("cache", false)
+
+	snapshot := check.Snapshot()
+	expected, _ := json.Marshal(check)
+	got, _ := json.Marshal(snapshot)
+	if !bytes.Equal(expected, got) {
+		t.Errorf("expected %s got %s", expected, got)
+	}
+
+	// ensure changing the snapshot leaves the check and later snapshots alone
+	snapshot.Healthy = false
+	snapshot.Dependencies[0].Healthy = false
+	*snapshot.Dependencies[1].Override = true
+	if !check.IsHealthy() {
+		t.Errorf("expected the check to be healthy")
+	}
+	later := check.Snapshot()
+	if !later.Healthy || !later.Dependencies[0].Healthy || *later.Dependencies[1].Override {
+		t.Errorf("expected the snapshot to be unchanged got %+v", later)
+	}
+
+	// ensure snapshots don't race with the checks
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			check.Update()
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		json.Marshal(check.Snapshot())
+	}
+	<-done
+}
@@ -0,0 +1,194 @@
+package health
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// StartSpread starts checking the dependencies like StartCheck, but from a
+// pool of `workers`, with each dependency on its own schedule spread evenly
+// across the duration. Services with hundreds of dependencies then check a
+// few at a time rather than all of them at every tick, and a slow check only
+// holds up its own worker. Dependencies registered later are picked up.
+func (s *ServiceCheck) StartSpread(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	stop, done := s.start()
+	sp := &spreader{
+		s:     s,
+		clock: s.getClock(),
+		known: make(map[*Dependency]bool),
+		jobs:  make(chan *dueDependency),
+		wake:  make(chan struct{}, 1),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sp.work()
+		}()
+	}
+	go func() {
+		defer done()
+		sp.dispatch(stop)
+		close(sp.jobs)
+		wg.Wait()
+	}()
+}
+
+// spreader runs the checks of StartSpread
+type spreader struct {
+	s     *ServiceCheck
+	clock Clock
+
+	queue dueQueue
+	known map[*Dependency]bool
+	jobs  chan *dueDependency
+	wake  chan struct{}
+	mu    sync.Mutex
+}
+
+// dispatch hands the dependencies to the workers as they fall due, until
+// `stop` is closed
+func (sp *spreader) dispatch(stop <-chan struct{}) {
+	for {
+		// fetch the channel before syncing so no registration is missed
+		changed := sp.s.Changed()
+		due, wait := sp.due()
+		for _, d := range due {
+			select {
+			case sp.jobs <- d:
+			case <-stop:
+				return
+			}
+		}
+
+		var timer <-chan time.Time
+		if wait >= 0 {
+			timer = sp.clock.After(wait)
+		}
+
+		select {
+		case <-timer:
+		case <-changed:
+		case <-sp.wake:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// due picks up newly registered dependencies and returns those which are
+// due, along with how long until the next is due, or -1 if none is waiting
+func (sp *spreader) due() ([]*dueDependency, time.Duration) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	now := sp.clock.Now()
+	registered := make(map[*Dependency]bool)
+	var added []*Dependency
+	for _, dep := range sp.s.load().handles {
+		registered[dep] = true
+		if !sp.known[dep] {
+			sp.known[dep] = true
+			added = append(added, dep)
+		}
+	}
+
+	// spread the new dependencies evenly across the next duration
+	for i, dep := range added {
+		offset := sp.s.duration * time.Duration(i) / time.Duration(len(added))
+		heap.Push(&sp.queue, &dueDependency{dep: dep, at: now.Add(offset)})
+	}
+
+	var due []*dueDependency
+	for sp.queue.Len() > 0 && !sp.queue[0].at.After(now) {
+		d := heap.Pop(&sp.queue).(*dueDependency)
+		if !registered[d.dep] {
+			delete(sp.known, d.dep)
+			continue
+		}
+		due = append(due, d)
+	}
+
+	if sp.queue.Len() == 0 {
+		return due, -1
+	}
+	return due, sp.queue[0].at.Sub(now)
+}
+
+// work checks the dependencies handed to it, scheduling each again once
+// checked
+func (sp *spreader) work() {
+	for d := range sp.jobs {
+		sp.s.checkDependency(d.dep)
+
+		sp.mu.Lock()
+		d.at = sp.clock.Now().Add(sp.s.interval(d.dep))
+		heap.Push(&sp.queue, d)
+		sp.mu.Unlock()
+
+		select {
+		case sp.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// checkDependency checks a single dependency without holding s.mu, then
+// records the result and the health of the service
+func (s *ServiceCheck) checkDependency(dep *Dependency) {
+	s.mu.RLock()
+	shard := s.shard(dep)
+	shard.Lock()
+	epoch, check := s.epoch, dep.check
+	skip := dep.Paused || dep.fresh()
+	shard.Unlock()
+	s.mu.RUnlock()
+	if skip {
+		return
+	}
+
+	healthy, start, took := dep.run(check)
+	if s.recordResult(dep, epoch, healthy, start, took) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the dependency may have been unregistered whilst it was being checked
+	if s.epoch != epoch || !s.registered(dep) {
+		return
+	}
+
+	previous := dep.Healthy
+	dep.record(healthy, start, took)
+	s.remember(dep)
+	s.setLastChecked(s.getClock().Now())
+	s.recordHealth(dep.Healthy != previous)
+}
+
+// dueDependency is a dependency scheduled by StartSpread
+type dueDependency struct {
+	dep *Dependency
+	at  time.Time
+}
+
+// dueQueue is a heap of dependencies, soonest due first
+type dueQueue []*dueDependency
+
+func (q dueQueue) Len() int            { return len(q) }
+func (q dueQueue) Less(i, j int) bool  { return q[i].at.Before(q[j].at) }
+func (q dueQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *dueQueue) Push(x interface{}) { *q = append(*q, x.(*dueDependency)) }
+func (q *dueQueue) Pop() interface{} {
+	old := *q
+	d := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return d
+}
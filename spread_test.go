@@ -0,0 +1,65 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartSpread(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		start = time.Now()
+		runs  = make(map[string][]time.Duration)
+	)
+	check, _ := InitialiseServiceCheck("api", 200*time.Millisecond)
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("shard%d", i)
+		check.RegisterDependency(name, LevelHard, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			runs[name] = append(runs[name], time.Since(start))
+			return true
+		})
+	}
+
+	check.StartSpread(2)
+	time.Sleep(500 * time.Millisecond)
+	// dependencies registered later are picked up
+	check.RegisterDependency("late", LevelSoft, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		runs["late"] = append(runs["late"], time.Since(start))
+		return false
+	})
+	time.Sleep(300 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// the first scheduled run of each dependency, after the registration run
+	var first, last time.Duration
+	for i := 0; i < 4; i++ {
+		shard := runs[fmt.Sprintf("shard%d", i)]
+		if len(shard) < 3 {
+			t.Fatalf("expected shard%d to be checked repeatedly got %d runs", i, len(shard))
+		}
+		if i == 0 || shard[1] < first {
+			first = shard[1]
+		}
+		if shard[1] > last {
+			last = shard[1]
+		}
+	}
+	if last-first < 100*time.Millisecond {
+		t.Errorf("expected the checks to be spread across the duration got %v to %v", first, last)
+	}
+
+	if len(runs["late"]) < 3 {
+		t.Errorf("expected the late dependency to be checked got %d runs", len(runs["late"]))
+	}
+	if states := check.DependencyStates(); len(states) != 5 || states[4].Healthy {
+		t.Errorf("expected the late dependency's result to be recorded got %+v", states)
+	}
+}
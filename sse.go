@@ -0,0 +1,82 @@
+package health
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultSSEHeartbeat is how often SSEHandler sends a heartbeat event unless
+// set by WithSSEHeartbeat
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// WithSSEHeartbeat sets how often SSEHandler sends a heartbeat event whilst
+// the status is unchanged, keeping idle connections open through proxies
+func WithSSEHeartbeat(interval time.Duration) Option {
+	return func(s *ServiceCheck) {
+		s.sseHeartbeat = interval
+	}
+}
+
+// SSEHandler streams the status to a ResponseWriter as server-sent events, so
+// dashboards are told of changes rather than polling HTTPHandler. A status
+// event carrying the status document is sent on connecting and whenever the
+// health of the service or one of its dependencies changes, see Changed, and
+// a heartbeat event carrying the time is sent whilst nothing changes, see
+// WithSSEHeartbeat. The stream ends when the request's context is done. The
+// dependencies are checked before connecting if the check's HandlerMode
+// requires it.
+func (s *ServiceCheck) SSEHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	interval := s.sseHeartbeat
+	if interval <= 0 {
+		interval = DefaultSSEHeartbeat
+	}
+	clock := s.getClock()
+	heartbeat := newTimer(clock, interval)
+	defer heartbeat.stop()
+
+	s.refresh()
+	changed := s.Changed()
+	err := s.writeStatusEvent(w)
+	for err == nil {
+		flusher.Flush()
+
+		select {
+		case <-changed:
+			// watch for the next change before the status is written, so
+			// none is missed
+			changed = s.Changed()
+			err = s.writeStatusEvent(w)
+		case <-heartbeat.c:
+			heartbeat.reset(interval)
+			_, err = io.WriteString(w, "event: heartbeat\ndata: "+clock.Now().UTC().Format(time.RFC3339)+"\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeStatusEvent writes the published status as a status event, with the
+// version of the results it was taken at as its id
+func (s *ServiceCheck) writeStatusEvent(w io.Writer) error {
+	view := s.load()
+	b, err := view.status.marshal(view.status.document())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "id: "+strconv.FormatUint(view.version, 10)+"\nevent: status\ndata: "+string(b)+"\n\n")
+	return err
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEHandler(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithClock(clock), WithSSEHeartbeat(time.Second))
+	healthy := true
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+	check.Update()
+
+	server := httptest.NewServer(http.HandlerFunc(check.SSEHandler))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected text/event-stream got %s", got)
+	}
+
+	events := bufio.NewReader(resp.Body)
+	next := func() (string, string) {
+		var event, data string
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("expected an event got %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	// ensure the current status is sent on connecting
+	if event, data := next(); event != "status" || !strings.Contains(data, `"healthy":true`) {
+		t.Errorf("expected a healthy status event got %s %s", event, data)
+	}
+
+	healthy = false
+	check.Update()
+	if event, data := next(); event != "status" || !strings.Contains(data, `"status":"unhealthy"`) {
+		t.Errorf("expected an unhealthy status event got %s %s", event, data)
+	}
+
+	clock.advance(time.Second, 1)
+	if event, data := next(); event != "heartbeat" || data != "2024-01-02T03:04:06Z" {
+		t.Errorf("expected a heartbeat event got %s %s", event, data)
+	}
+}
+
+func TestSSEHandlerCancelled(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		check.SSEHandler(w, req)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the stream to end with the request's context")
+	}
+}
@@ -0,0 +1,34 @@
+package health
+
+import "time"
+
+// Startup marks the service as starting, so HTTPHandler responds 503 whilst
+// the dependencies are waited upon for up to `deadline`, after which the check
+// is started as per StartCheck. It returns straight away so the port can be
+// opened immediately, suiting platforms such as Cloud Run which route traffic
+// as soon as it is.
+func (s *ServiceCheck) Startup(deadline time.Duration) {
+	s.setStarting(true)
+
+	go func() {
+		s.WaitForDependencies(deadline)
+		s.setStarting(false)
+		s.StartCheck()
+	}()
+}
+
+// IsStarting returns a bool whether Startup is waiting for the dependencies
+func (s *ServiceCheck) IsStarting() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Starting
+}
+
+func (s *ServiceCheck) setStarting(starting bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Starting != starting {
+		s.Starting = starting
+		s.notifyChanged()
+	}
+}
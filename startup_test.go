@@ -0,0 +1,51 @@
+package health
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartup(t *testing.T) {
+	var ready atomic.Bool
+
+	check, _ := InitialiseServiceCheck("test", 10*time.Millisecond)
+	check.RegisterDependency("db", LevelHard, func() bool { return ready.Load() })
+	check.Startup(5 * time.Second)
+
+	w := httptest.NewRecorder()
+	check.HTTPHandler(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 503 || !strings.Contains(w.Body.String(), `"starting":true`) {
+		t.Errorf("expected 503 whilst starting got %v %s", w.Code, w.Body.String())
+	}
+
+	ready.Store(true)
+	for i := 0; i < 100 && check.IsStarting(); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if check.IsStarting() {
+		t.Fatal("expected startup to complete once the dependencies are healthy")
+	}
+
+	w = httptest.NewRecorder()
+	check.HTTPHandler(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 200 {
+		t.Errorf("expected 200 got %v", w.Code)
+	}
+}
+
+func TestStartupDeadline(t *testing.T) {
+	check, _ := InitialiseServiceCheck("test", 10*time.Millisecond)
+	check.RegisterDependency("db", LevelHard, func() bool { return false })
+	check.Startup(50 * time.Millisecond)
+
+	time.Sleep(200 * time.Millisecond)
+	if check.IsStarting() {
+		t.Error("expected startup to give up after the deadline")
+	}
+	if check.IsServing() {
+		t.Error("expected not to serve with an unhealthy hard dependency")
+	}
+}
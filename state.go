@@ -0,0 +1,52 @@
+package health
+
+// state is the health of a ServiceCheck precomputed on publication, so the
+// request path reads it with a single atomic load rather than taking or
+// scanning a snapshot
+type state struct {
+	healthy bool
+	status  Status
+	serving bool
+	ready   bool
+	live    bool
+	// failing is the number of unhealthy dependencies by Level
+	failing [LevelInformational + 1]int
+}
+
+// newState computes the state of the snapshot `status`
+func newState(status *ServiceCheck) *state {
+	st := &state{
+		healthy: status.Healthy,
+		status:  status.serviceStatus(),
+		serving: status.isServing(),
+		ready:   status.isReady(),
+		live:    status.isLive(),
+	}
+	for _, dependency := range status.Dependencies {
+		if !dependency.Healthy && dependency.Level <= LevelInformational {
+			st.failing[dependency.Level]++
+		}
+	}
+
+	return st
+}
+
+// precomputed returns the state stored by publish, or that of a snapshot for
+// a ServiceCheck which has never published one, such as one decoded from JSON
+func (s *ServiceCheck) precomputed() *state {
+	if st := s.state.Load(); st != nil {
+		return st
+	}
+
+	return newState(s.load().status)
+}
+
+// FailingDependencies returns the number of unhealthy dependencies of `level`
+// as of the last check
+func (s *ServiceCheck) FailingDependencies(level Level) int {
+	if level > LevelInformational {
+		return 0
+	}
+
+	return s.precomputed().failing[level]
+}
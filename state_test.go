@@ -0,0 +1,79 @@
+package health
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFailingDependencies(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	healthy := true
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+	check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+	check.RegisterDependency("queue", LevelSoft, func() bool { return false })
+	check.Update()
+
+	tests := []struct {
+		healthy bool
+		hard    int
+		soft    int
+	}{
+		{true, 0, 2},
+		{false, 1, 2},
+	}
+
+	for _, test := range tests {
+		healthy = test.healthy
+		check.Update()
+
+		if got := check.IsHealthy(); got != test.healthy {
+			t.Errorf("expected %v got %v", test.healthy, got)
+		}
+		if got := check.IsServing(); got != test.healthy {
+			t.Errorf("expected %v got %v", test.healthy, got)
+		}
+		if got := check.FailingDependencies(LevelHard); got != test.hard {
+			t.Errorf("expected %d got %d", test.hard, got)
+		}
+		if got := check.FailingDependencies(LevelSoft); got != test.soft {
+			t.Errorf("expected %d got %d", test.soft, got)
+		}
+	}
+
+	// ensure draining is seen straight away
+	check.Drain()
+	if check.IsServing() {
+		t.Errorf("expected false got true")
+	}
+}
+
+func TestFailingDependenciesDecoded(t *testing.T) {
+	var check ServiceCheck
+	doc := `{"name":"api","healthy":false,"dependencies":[{"name":"db","healthy":false,"level":"hard"}]}`
+	if err := json.Unmarshal([]byte(doc), &check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	if check.IsHealthy() {
+		t.Errorf("expected false got true")
+	}
+	if got := check.FailingDependencies(LevelHard); got != 1 {
+		t.Errorf("expected 1 got %d", got)
+	}
+}
+
+func BenchmarkIsHealthy(b *testing.B) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	for _, name := range []string{"db", "cache", "queue"} {
+		check.RegisterDependency(name, LevelHard, func() bool { return true })
+	}
+	check.Update()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			check.IsHealthy()
+		}
+	})
+}
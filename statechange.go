@@ -0,0 +1,41 @@
+package health
+
+import "context"
+
+// OnStateChange calls fn whenever the service or one of its dependencies
+// changes between healthy and unhealthy, with the old and new health. The
+// service itself is reported under the empty name, after its dependencies.
+// Dependencies registered later are treated as previously healthy, so one
+// failing its first check is reported too. Calls are made as by
+// OnStatusChange, whose queue they share the limits of, and the returned func
+// unregisters fn.
+func (s *ServiceCheck) OnStateChange(fn func(dep string, old, new bool)) func() {
+	last := s.Document()
+	return s.OnStatusChange(func(ctx context.Context, doc StatusDocument) {
+		transitions(last, doc, fn)
+		last = doc
+	})
+}
+
+// transitions calls fn for every dependency, then the service, whose health
+// differs between `old` and `new`
+func transitions(old, new StatusDocument, fn func(dep string, old, new bool)) {
+	previous := make(map[string]bool, len(old.Dependencies))
+	for _, dependency := range old.Dependencies {
+		previous[dependency.Name] = dependency.Healthy
+	}
+
+	for _, dependency := range new.Dependencies {
+		healthy, ok := previous[dependency.Name]
+		if !ok {
+			healthy = true
+		}
+		if healthy != dependency.Healthy {
+			fn(dependency.Name, healthy, dependency.Healthy)
+		}
+	}
+
+	if old.Healthy != new.Healthy {
+		fn("", old.Healthy, new.Healthy)
+	}
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+type transition struct {
+	dep      string
+	old, new bool
+}
+
+func TestOnStateChange(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true })
+	cache, _ := check.RegisterDependency("cache", LevelSoft, func() bool { return true })
+
+	changes := make(chan transition, 10)
+	cancel := check.OnStateChange(func(dep string, old, new bool) {
+		changes <- transition{dep, old, new}
+	})
+	defer cancel()
+
+	tests := []struct {
+		change   func()
+		expected []transition
+	}{
+		{
+			func() { cache.SetHealthy(false) },
+			[]transition{{"cache", true, false}},
+		},
+		{
+			func() { db.SetHealthy(false) },
+			[]transition{{"db", true, false}, {"", true, false}},
+		},
+		{
+			func() {
+				check.RegisterDependency("queue", LevelSoft, func() bool { return false })
+			},
+			[]transition{{"queue", true, false}},
+		},
+		{
+			func() { db.SetHealthy(true) },
+			[]transition{{"db", false, true}, {"", false, true}},
+		},
+	}
+
+	for i, test := range tests {
+		test.change()
+		for _, expected := range test.expected {
+			select {
+			case got := <-changes:
+				if got != expected {
+					t.Errorf("expected %+v got %+v for change %d", expected, got, i+1)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("expected %+v for change %d", expected, i+1)
+			}
+		}
+	}
+
+	select {
+	case got := <-changes:
+		t.Errorf("expected no more transitions got %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
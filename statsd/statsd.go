@@ -0,0 +1,106 @@
+// Package statsd reports the health of a ServiceCheck to a statsd server as
+// gauges, keyed per dependency:
+//
+//	<prefix>.service.up                   1 when the service is healthy, 0 otherwise
+//	<prefix>.dependency.<name>.up         1 when the dependency is healthy, 0 otherwise
+//	<prefix>.dependency.<name>.duration   how long its last check took, in milliseconds
+//
+// Register a Reporter to report after every cycle of the check:
+//
+//	check.RegisterReporter(&statsd.Reporter{Addr: "statsd:8125"})
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+const (
+	// DefaultPrefix is used for every metric when Reporter.Prefix is empty
+	DefaultPrefix = "health"
+	// DefaultTimeout bounds a report when Reporter.Timeout is not set and the
+	// context has no deadline
+	DefaultTimeout = 5 * time.Second
+)
+
+// Reporter is a health.Reporter sending gauges to a statsd server over UDP
+type Reporter struct {
+	// Addr of the statsd server, for example statsd:8125
+	Addr string
+	// Prefix of every metric, DefaultPrefix if empty
+	Prefix string
+	// Timeout bounds each report, DefaultTimeout if not set
+	Timeout time.Duration
+}
+
+// ensure Reporter can be registered with RegisterReporter
+var _ health.Reporter = (*Reporter)(nil)
+
+// Report sends the current health of `check` and its dependencies in a single
+// packet
+func (r *Reporter) Report(ctx context.Context, check *health.ServiceCheck) error {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	var buf bytes.Buffer
+	gauge(&buf, prefix+".service.up", up(check.IsHealthy()))
+	for _, dependency := range check.DependencyStates() {
+		name := prefix + ".dependency." + sanitise(dependency.Name)
+		gauge(&buf, name+".up", up(dependency.Healthy))
+		gauge(&buf, name+".duration", strconv.FormatFloat(float64(dependency.CheckDuration())/float64(time.Millisecond), 'f', -1, 64))
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", r.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	_, err = conn.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// gauge writes a gauge line
+func gauge(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteByte(':')
+	buf.WriteString(value)
+	buf.WriteString("|g\n")
+}
+
+func up(healthy bool) string {
+	if healthy {
+		return "1"
+	}
+	return "0"
+}
+
+// sanitise replaces the characters statsd treats specially in a metric name
+func sanitise(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', ':', '|', '@', ' ', '\n':
+			return '_'
+		}
+		return r
+	}, name)
+}
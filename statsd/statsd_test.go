@@ -0,0 +1,60 @@
+package statsd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestReporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	defer conn.Close()
+
+	check, _ := health.InitialiseServiceCheck("api", time.Minute)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+	check.RegisterDependency("redis.cache", health.LevelSoft, func() bool { return false })
+	check.Update()
+
+	r := &Reporter{Addr: conn.LocalAddr().String(), Prefix: "api"}
+	if err := r.Report(context.Background(), check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	lines := strings.Split(string(buf[:n]), "\n")
+	expected := []string{
+		"api.service.up:1|g",
+		"api.dependency.db.up:1|g",
+		"api.dependency.redis_cache.up:0|g",
+	}
+	for _, e := range expected {
+		if !contains(lines, e) {
+			t.Errorf("expected %q in %q", e, lines)
+		}
+	}
+	if len(lines) != 5 {
+		t.Errorf("expected 5 gauges got %d", len(lines))
+	}
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
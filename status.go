@@ -0,0 +1,84 @@
+package health
+
+// WithDegradedStatusCode makes HTTPHandler, FastHTTPHandler and WriteResponse
+// respond with `code`, rather than 200, whilst the service is degraded, so load
+// balancers which understand it, such as with 207, can prefer fully healthy
+// instances
+func WithDegradedStatusCode(code int) Option {
+	return func(s *ServiceCheck) {
+		s.degradedCode = code
+	}
+}
+
+// WithUnhealthyStatusCode makes HTTPHandler, FastHTTPHandler and WriteResponse
+// respond with `code`, rather than 503, whilst the service isn't serving, for
+// load balancers expecting another code, such as 429
+func WithUnhealthyStatusCode(code int) Option {
+	return func(s *ServiceCheck) {
+		s.unhealthyCode = code
+	}
+}
+
+// Status returns whether the service is healthy, degraded, with only soft
+// dependencies failing, or unhealthy, with a hard dependency failing, unless
+// its Aggregator decides otherwise. It is included in the status document as
+// status.
+func (s *ServiceCheck) Status() Status {
+	return s.precomputed().status
+}
+
+// serviceStatus returns the Status of a snapshot, or of a ServiceCheck whilst
+// s.mu is held
+func (s *ServiceCheck) serviceStatus() Status {
+	if !s.Healthy {
+		return StatusUnhealthy
+	}
+	if s.aggregator != nil {
+		if s.aggregated == StatusDegraded {
+			return StatusDegraded
+		}
+		return StatusHealthy
+	}
+	for _, dependency := range s.Dependencies {
+		if !dependency.Healthy && dependency.Level == LevelSoft {
+			return StatusDegraded
+		}
+	}
+
+	return StatusHealthy
+}
+
+// statusCode returns the response code of the status document of a snapshot
+func (s *ServiceCheck) statusCode() int {
+	switch {
+	case !s.isServing() && s.unhealthyCode != 0:
+		return s.unhealthyCode
+	case !s.isServing():
+		return 503
+	case s.degradedCode != 0 && s.serviceStatus() == StatusDegraded:
+		return s.degradedCode
+	default:
+		return 200
+	}
+}
+
+// MarshalText encodes the status by name
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the status from its name, unrecognised names being
+// StatusUnknown
+func (s *Status) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "healthy":
+		*s = StatusHealthy
+	case "degraded":
+		*s = StatusDegraded
+	case "unhealthy":
+		*s = StatusUnhealthy
+	default:
+		*s = StatusUnknown
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		hard, soft   bool
+		code         int
+		expected     Status
+		expectedCode int
+	}{
+		{true, true, 0, StatusHealthy, 200},
+		{true, false, 0, StatusDegraded, 200},
+		{true, false, 207, StatusDegraded, 207},
+		{true, true, 207, StatusHealthy, 200},
+		{false, false, 207, StatusUnhealthy, 503},
+	}
+
+	for _, test := range tests {
+		check, _ := InitialiseServiceCheck("api", time.Minute, WithDegradedStatusCode(test.code))
+		hard, soft := test.hard, test.soft
+		check.RegisterDependency("db", LevelHard, func() bool { return hard })
+		check.RegisterDependency("cache", LevelSoft, func() bool { return soft })
+		check.Update()
+
+		if status := check.Status(); status != test.expected {
+			t.Errorf("expected %v got %v", test.expected, status)
+		}
+
+		for _, handler := range []func(w *httptest.ResponseRecorder){
+			func(w *httptest.ResponseRecorder) { check.HTTPHandler(w, httptest.NewRequest("GET", "/", nil)) },
+			func(w *httptest.ResponseRecorder) { check.FastHTTPHandler(w, httptest.NewRequest("GET", "/", nil)) },
+			func(w *httptest.ResponseRecorder) {
+				code, _ := check.WriteResponse(w.Body)
+				w.WriteHeader(code)
+			},
+		} {
+			w := httptest.NewRecorder()
+			handler(w)
+			if w.Code != test.expectedCode {
+				t.Errorf("expected %d got %d", test.expectedCode, w.Code)
+			}
+
+			var doc StatusDocument
+			if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("expected nil got %v", err)
+			}
+			if doc.Status != test.expected {
+				t.Errorf("expected %v got %v in %s", test.expected, doc.Status, w.Body)
+			}
+		}
+	}
+}
+
+func TestWithUnhealthyStatusCode(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithUnhealthyStatusCode(429))
+	healthy := false
+	check.RegisterDependency("db", LevelHard, func() bool { return healthy })
+	check.Update()
+
+	for _, test := range []struct {
+		healthy      bool
+		expectedCode int
+	}{
+		{false, 429},
+		{true, 200},
+	} {
+		healthy = test.healthy
+		check.Update()
+
+		w := httptest.NewRecorder()
+		check.HTTPHandler(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != test.expectedCode {
+			t.Errorf("expected %d got %d", test.expectedCode, w.Code)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package statuspage keeps statuspage.io components in line with a
+// ServiceCheck, automating public status updates for customer facing outages.
+package statuspage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// DefaultBaseURL of the statuspage.io API
+const DefaultBaseURL = "https://api.statuspage.io/v1"
+
+// Component statuses understood by statuspage.io
+const (
+	StatusOperational         = "operational"
+	StatusDegradedPerformance = "degraded_performance"
+	StatusPartialOutage       = "partial_outage"
+	StatusMajorOutage         = "major_outage"
+)
+
+// Notifier updates statuspage.io components as the service and its
+// dependencies change health. Components are only updated on transitions, so
+// statuses set by hand are left alone until the next change.
+type Notifier struct {
+	PageID string
+	APIKey string
+
+	// ServiceComponent is the ID of the component reflecting the service as a
+	// whole: a major outage whilst unhealthy and degraded performance whilst
+	// soft dependencies fail. Empty disables it.
+	ServiceComponent string
+	// Components maps dependency names to the ID of the component reflecting
+	// them: a major outage whilst a hard dependency fails and a partial outage
+	// whilst a soft one fails
+	Components map[string]string
+
+	// BaseURL of the API, DefaultBaseURL if empty
+	BaseURL string
+	// Client is used to make requests, a client with a 10 second timeout is
+	// used if nil
+	Client *http.Client
+
+	mu   sync.Mutex
+	last map[string]string
+}
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Notify updates every component whose status has changed since it was last
+// successfully updated
+func (n *Notifier) Notify(ctx context.Context, check *health.ServiceCheck) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.last == nil {
+		n.last = make(map[string]string)
+	}
+
+	var errs []error
+	for component, status := range n.statuses(check) {
+		if n.last[component] == status {
+			continue
+		}
+
+		if err := n.update(ctx, component, status); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n.last[component] = status
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("updating %d statuspage.io components failed, first error: %v", len(errs), errs[0])
+	}
+	return nil
+}
+
+// Start calls Notify every `interval` until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context, check *health.ServiceCheck, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n.Notify(ctx, check)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// statuses returns the desired status of every configured component
+func (n *Notifier) statuses(check *health.ServiceCheck) map[string]string {
+	statuses := make(map[string]string, len(n.Components)+1)
+
+	softFailing := false
+	for _, dependency := range check.DependencyStates() {
+		// an informational dependency neither fails nor degrades the service,
+		// so its component stays operational
+		status := StatusOperational
+		if !dependency.Healthy && dependency.Level != health.LevelInformational {
+			status = StatusMajorOutage
+			if dependency.Level == health.LevelSoft {
+				status = StatusPartialOutage
+				softFailing = true
+			}
+		}
+
+		if component, ok := n.Components[dependency.Name]; ok {
+			statuses[component] = status
+		}
+	}
+
+	if n.ServiceComponent != "" {
+		switch {
+		case !check.IsHealthy():
+			statuses[n.ServiceComponent] = StatusMajorOutage
+		case softFailing:
+			statuses[n.ServiceComponent] = StatusDegradedPerformance
+		default:
+			statuses[n.ServiceComponent] = StatusOperational
+		}
+	}
+
+	return statuses
+}
+
+func (n *Notifier) update(ctx context.Context, component, status string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"component": map[string]string{"status": status},
+	})
+	if err != nil {
+		return err
+	}
+
+	baseURL := n.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	req, err := http.NewRequest("PATCH", baseURL+"/pages/"+url.PathEscape(n.PageID)+"/components/"+url.PathEscape(component), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "OAuth "+n.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("statuspage.io responded to component %s update with status code %d", component, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package statuspage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestNotifier(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		updates = map[string]string{}
+		calls   int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.Header.Get("Authorization") != "OAuth key" {
+			t.Errorf("unexpected request %s with %q", r.Method, r.Header.Get("Authorization"))
+		}
+
+		var body struct {
+			Component struct {
+				Status string `json:"status"`
+			} `json:"component"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		updates[r.URL.Path] = body.Component.Status
+		calls++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+	check.RegisterDependency("cache", health.LevelSoft, func() bool { return false })
+
+	notifier := &Notifier{
+		PageID:           "page",
+		APIKey:           "key",
+		ServiceComponent: "api-component",
+		Components:       map[string]string{"cache": "cache-component"},
+		BaseURL:          server.URL,
+	}
+
+	if err := notifier.Notify(context.Background(), check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	mu.Lock()
+	expected := map[string]string{
+		"/pages/page/components/api-component":   StatusDegradedPerformance,
+		"/pages/page/components/cache-component": StatusPartialOutage,
+	}
+	for path, status := range expected {
+		if updates[path] != status {
+			t.Errorf("expected %v got %v for %s", status, updates[path], path)
+		}
+	}
+	mu.Unlock()
+
+	// no transition, no updates
+	notifier.Notify(context.Background(), check)
+	mu.Lock()
+	if calls != 2 {
+		t.Errorf("expected components to only be updated on transitions got %d calls", calls)
+	}
+	mu.Unlock()
+}
+
+func TestNotifierRetriesFailedUpdates(t *testing.T) {
+	var fail int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	check := &health.ServiceCheck{Name: "api", Healthy: false}
+	notifier := &Notifier{ServiceComponent: "api-component", BaseURL: server.URL}
+
+	if err := notifier.Notify(context.Background(), check); err == nil {
+		t.Fatal("expected an error from a failed update")
+	}
+
+	atomic.StoreInt32(&fail, 0)
+	if err := notifier.Notify(context.Background(), check); err != nil {
+		t.Errorf("expected the failed update to be retried got %v", err)
+	}
+	if notifier.last["api-component"] != StatusMajorOutage {
+		t.Errorf("expected %v got %v", StatusMajorOutage, notifier.last["api-component"])
+	}
+}
+
+func TestNotifierInformational(t *testing.T) {
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("audit", health.LevelInformational, func() bool { return false })
+	check.Update()
+
+	notifier := &Notifier{ServiceComponent: "api-component", Components: map[string]string{"audit": "audit-component"}}
+
+	// ensure a failing informational dependency leaves every component operational
+	for component, status := range notifier.statuses(check) {
+		if status != StatusOperational {
+			t.Errorf("expected %v got %v for %v", StatusOperational, status, component)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultStopGrace is how long StopCheck waits for checks in flight unless
+// set by WithStopGrace
+const DefaultStopGrace = 5 * time.Second
+
+// WithStopGrace sets how long StopCheck waits for checks in flight before
+// cancelling them
+func WithStopGrace(grace time.Duration) Option {
+	return func(s *ServiceCheck) {
+		s.stopGrace = grace
+	}
+}
+
+// loops are the goroutines started by StartCheck and StartSpread, along with
+// the watchdog
+type loops struct {
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// StopCheck stops the checks started by StartCheck or StartSpread. It waits
+// up to the grace period, see WithStopGrace, for checks in flight to finish,
+// then cancels the context of those still running, such as remote services'.
+// No result of a check started before it is recorded once it returns, so the
+// status stays as it was. Results shared by WithSharedCache are released. It
+// is safe to call more than once, and the check can be started again
+// afterwards.
+func (s *ServiceCheck) StopCheck() {
+	s.mu.Lock()
+	l := s.loops
+	s.loops = nil
+	s.mu.Unlock()
+
+	if l != nil {
+		close(l.stop)
+
+		finished := make(chan struct{})
+		go func() {
+			l.wg.Wait()
+			close(finished)
+		}()
+
+		grace := s.stopGrace
+		if grace == 0 {
+			grace = DefaultStopGrace
+		}
+		deadline := newTimer(s.getClock(), grace)
+		defer deadline.stop()
+
+		select {
+		case <-finished:
+		case <-deadline.c:
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running.Store(false)
+	s.epoch++
+	if s.cancel != nil {
+		s.cancel()
+		s.ctx, s.cancel = nil, nil
+	}
+	// shared results are taken again if the check is restarted
+	for _, dependency := range s.Dependencies {
+		dependency.releaseShared()
+	}
+}
+
+// StartCheckContext starts checking the dependencies as StartCheck does,
+// stopping them as StopCheck does once ctx is done, for services shutting down
+// by cancelling a context
+func (s *ServiceCheck) StartCheckContext(ctx context.Context) {
+	s.StartCheck()
+
+	s.mu.RLock()
+	l := s.loops
+	s.mu.RUnlock()
+	if l == nil {
+		return
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.StopCheck()
+		case <-l.stop:
+		}
+	}()
+}
+
+// start registers a goroutine checking the dependencies, returning a channel
+// closed by StopCheck and a function to call once the goroutine returns
+func (s *ServiceCheck) start() (<-chan struct{}, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running.Store(true)
+	if s.loops == nil {
+		s.loops = &loops{stop: make(chan struct{})}
+		s.watch(s.loops)
+	}
+	s.loops.wg.Add(1)
+	return s.loops.stop, s.loops.wg.Done
+}
+
+// context returns the context checks run with, which StopCheck cancels
+func (s *ServiceCheck) context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lockedContext()
+}
+
+// lockedContext returns the context as context does, s.mu must be held
+func (s *ServiceCheck) lockedContext() context.Context {
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	return s.ctx
+}
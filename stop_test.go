@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStopCheck(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+
+	var (
+		mu   sync.Mutex
+		runs int
+	)
+	check.RegisterDependency("db", LevelHard, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		runs++
+		return true
+	})
+
+	check.StartCheck()
+	clock.advance(time.Second, 1)
+	check.StopCheck()
+	check.StopCheck()
+
+	mu.Lock()
+	stopped := runs
+	mu.Unlock()
+	clock.advance(time.Minute, 0)
+	time.Sleep(10 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != stopped {
+		t.Errorf("expected %d runs got %d", stopped, runs)
+	}
+}
+
+func TestStopCheckRestart(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", 10*time.Millisecond)
+	checked := make(chan struct{}, 100)
+	check.RegisterDependency("db", LevelHard, func() bool {
+		checked <- struct{}{}
+		return true
+	})
+	<-checked
+
+	check.StartSpread(2)
+	<-checked
+	check.StopCheck()
+
+	check.StartCheck()
+	<-checked
+	check.StopCheck()
+}
+
+func TestStopCheckGrace(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Second, WithStopGrace(50*time.Millisecond))
+
+	var healthy bool
+	release, started := make(chan struct{}), make(chan struct{})
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		if !healthy {
+			healthy = true
+			return true
+		}
+		close(started)
+		<-release
+		return false
+	})
+
+	check.StartCheck()
+	<-started
+
+	begin := time.Now()
+	check.StopCheck()
+	if waited := time.Since(begin); waited < 50*time.Millisecond {
+		t.Errorf("expected to wait for the grace period got %v", waited)
+	}
+
+	// the check finishing late isn't recorded
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	if !db.IsHealthy() || !check.IsHealthy() {
+		t.Error("expected the result after stopping to be discarded")
+	}
+}
+
+func TestStopCheckCancelsRemote(t *testing.T) {
+	cancelled := make(chan struct{})
+	var once sync.Once
+	first := true
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if first {
+			first = false
+			w.Write([]byte(`{"name":"remote","healthy":true}`))
+			return
+		}
+
+		<-r.Context().Done()
+		once.Do(func() { close(cancelled) })
+	}))
+	defer remote.Close()
+
+	check, _ := InitialiseServiceCheck("api", time.Millisecond, WithStopGrace(10*time.Millisecond))
+	if _, err := check.RegisterRemoteService("remote", remote.URL, LevelHard); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	check.StartCheck()
+	time.Sleep(20 * time.Millisecond)
+	check.StopCheck()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the remote check to be cancelled")
+	}
+}
+
+func TestStartCheckContext(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", 10*time.Millisecond)
+	checked := make(chan struct{}, 100)
+	check.RegisterDependency("db", LevelHard, func() bool {
+		checked <- struct{}{}
+		return true
+	})
+	<-checked
+
+	ctx, cancel := context.WithCancel(context.Background())
+	check.StartCheckContext(ctx)
+	<-checked
+	cancel()
+
+	// ensure cancelling the context stops the check
+	for i := 0; check.running.Load(); i++ {
+		if i == 100 {
+			t.Fatalf("expected the check to stop")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// stopping it first is fine too
+	check.StartCheckContext(context.Background())
+	check.StopCheck()
+	check.StopCheck()
+}
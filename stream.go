@@ -0,0 +1,92 @@
+package health
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// writers are reused between streams
+var writers = sync.Pool{
+	New: func() interface{} { return bufio.NewWriter(nil) },
+}
+
+// stream encodes the status document to w a dependency at a time, rather than
+// building it in memory, so services with thousands of dependencies serve it
+// in constant memory. The output is identical to encoding the StatusDocument.
+func (s *ServiceCheck) stream(w io.Writer) error {
+	buf := writers.Get().(*bufio.Writer)
+	buf.Reset(w)
+	defer func() {
+		buf.Reset(nil)
+		writers.Put(buf)
+	}()
+	e := &streamer{w: buf}
+
+	e.raw(`{"schemaVersion":` + strconv.Itoa(SchemaVersion) + `,"name":`)
+	e.value(s.Name)
+	e.raw(`,"healthy":` + strconv.FormatBool(s.Healthy) + `,"status":"` + s.serviceStatus().String() + `","dependencies":`)
+	if s.Dependencies == nil {
+		e.raw("null")
+	} else {
+		e.raw("[")
+		for i, dependency := range s.Dependencies {
+			if i > 0 {
+				e.raw(",")
+			}
+			e.value(dependency.document(s.checkTimes))
+		}
+		e.raw("]")
+	}
+	if s.Draining {
+		e.raw(`,"draining":true`)
+	}
+	if s.ShuttingDown {
+		e.raw(`,"shuttingDown":true`)
+	}
+	if s.Starting {
+		e.raw(`,"starting":true`)
+	}
+	if s.Maintenance {
+		e.raw(`,"maintenance":true`)
+	}
+	e.raw(`,"message":`)
+	e.value(s.message())
+	if s.metadata != nil {
+		e.raw(`,"metadata":`)
+		e.value(s.metadata)
+	}
+	e.raw("}\n")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// streamer writes JSON, keeping the first error
+type streamer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *streamer) raw(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *streamer) value(v interface{}) {
+	if e.err != nil {
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		e.err = err
+		return
+	}
+	_, e.err = e.w.Write(b)
+}
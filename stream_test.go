@@ -0,0 +1,68 @@
+package health
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	tests := []*ServiceCheck{
+		{Name: "empty", Healthy: true},
+		{Name: "draining", Draining: true, Starting: true, Dependencies: []*Dependency{}},
+		{
+			Name: "remote <&>",
+			Dependencies: []*Dependency{
+				{Name: "db", Level: LevelHard, URL: "http://db/health?a=1&b=2"},
+				{Name: "cache", Healthy: true, Paused: true, Stale: true, Remote: &ServiceCheck{Name: "cache"}},
+			},
+		},
+	}
+
+	for _, check := range tests {
+		expected, err := check.MarshalJSON()
+		if err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+
+		var got bytes.Buffer
+		if err := check.load().status.stream(&got); err != nil {
+			t.Fatalf("expected nil got %v", err)
+		}
+		if got.String() != string(expected)+"\n" {
+			t.Errorf("expected %s got %s", expected, got.String())
+		}
+	}
+}
+
+func TestStreamLarge(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	for i := 0; i < 1000; i++ {
+		check.RegisterDependency(fmt.Sprintf("shard-%d", i), LevelSoft, func() bool { return true })
+	}
+
+	var got bytes.Buffer
+	if err := check.WriteStatus(&got); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	expected, _ := check.MarshalJSON()
+	if got.String() != string(expected)+"\n" {
+		t.Error("expected the streamed document to match MarshalJSON")
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWrite }
+
+func TestStreamError(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	if err := check.WriteStatus(failingWriter{}); !errors.Is(err, errWrite) {
+		t.Errorf("expected %v got %v", errWrite, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package systemd integrates a ServiceCheck with the systemd service manager
+// using the sd_notify protocol, so units with Type=notify and WatchdogSec are
+// only considered started once their dependencies are healthy, and are
+// restarted if the health loop stalls or a hard dependency fails.
+package systemd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// ErrNotReady is returned by Run when the dependencies didn't become healthy
+// in time
+var ErrNotReady = errors.New("systemd: dependencies did not become healthy")
+
+// Notify sends `state`, for example "READY=1", to the service manager over
+// $NOTIFY_SOCKET. It returns false without an error if the process wasn't
+// started with a notify socket.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// names starting with @ are abstract sockets, which net handles for us
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+
+	// ensure conn is closed when function returns
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// WatchdogInterval returns the interval in which systemd expects keep-alive
+// pings, from $WATCHDOG_USEC, or zero if the watchdog isn't enabled for this
+// process
+func WatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errors.New("systemd: invalid WATCHDOG_USEC " + strconv.Quote(usec))
+	}
+
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// Run waits up to `timeout` for the dependencies of `check` to become healthy
+// and sends READY=1. If the watchdog is enabled it then sends WATCHDOG=1,
+// at most every half of the watchdog interval, whenever a check has completed
+// and the service is healthy, until ctx is cancelled. The check must be started
+// with an interval shorter than that, otherwise systemd will consider the unit
+// hung.
+//
+// Run returns nil straight after READY=1 when the watchdog isn't enabled.
+func Run(ctx context.Context, check *health.ServiceCheck, timeout time.Duration) error {
+	interval, err := WatchdogInterval()
+	if err != nil {
+		return err
+	}
+
+	if !check.WaitForDependencies(timeout) {
+		return ErrNotReady
+	}
+
+	if _, err := Notify("READY=1"); err != nil {
+		return err
+	}
+
+	if interval == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		checked := check.LastChecked()
+		if checked.After(last) && check.IsHealthy() {
+			last = checked
+			Notify("WATCHDOG=1")
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// listen creates a notify socket and points NOTIFY_SOCKET at it
+func listen(t *testing.T) *net.UnixConn {
+	dir, err := os.MkdirTemp("", "sd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	return conn
+}
+
+func read(t *testing.T, conn *net.UnixConn) string {
+	buf := make([]byte, 256)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify("READY=1"); sent || err != nil {
+		t.Errorf("expected false <nil> got %v %v", sent, err)
+	}
+
+	conn := listen(t)
+	if sent, err := Notify("READY=1"); !sent || err != nil {
+		t.Errorf("expected true <nil> got %v %v", sent, err)
+	}
+	if state := read(t, conn); state != "READY=1" {
+		t.Errorf("expected READY=1 got %s", state)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		usec     string
+		pid      string
+		expected time.Duration
+		err      bool
+	}{
+		{"", "", 0, false},
+		{"500000", "", 500 * time.Millisecond, false},
+		{"500000", strconv.Itoa(os.Getpid()), 500 * time.Millisecond, false},
+		{"500000", "1", 0, false},
+		{"soon", "", 0, true},
+	}
+
+	for _, test := range tests {
+		t.Setenv("WATCHDOG_USEC", test.usec)
+		t.Setenv("WATCHDOG_PID", test.pid)
+
+		interval, err := WatchdogInterval()
+		if interval != test.expected || (err != nil) != test.err {
+			t.Errorf("expected %v %v got %v %v", test.expected, test.err, interval, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	conn := listen(t)
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", "")
+
+	check, _ := health.InitialiseServiceCheck("api", 10*time.Millisecond)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+	check.StartCheck()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- Run(ctx, check, time.Second) }()
+
+	if state := read(t, conn); state != "READY=1" {
+		t.Errorf("expected READY=1 got %s", state)
+	}
+	if state := read(t, conn); state != "WATCHDOG=1" {
+		t.Errorf("expected WATCHDOG=1 got %s", state)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+}
+
+func TestRunNotReady(t *testing.T) {
+	listen(t)
+	t.Setenv("WATCHDOG_USEC", "")
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return false })
+
+	if err := Run(context.Background(), check, 50*time.Millisecond); err != ErrNotReady {
+		t.Errorf("expected %v got %v", ErrNotReady, err)
+	}
+}
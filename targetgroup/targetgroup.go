@@ -0,0 +1,124 @@
+// Package targetgroup serves a ServiceCheck the way AWS ALB/NLB target groups
+// expect, including a warm-up period after start and a drain mode which knows
+// how long the load balancer takes to stop routing to a target.
+package targetgroup
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Defaults matching the target group defaults of an ALB
+const (
+	DefaultInterval            = 30 * time.Second
+	DefaultUnhealthyThreshold  = 2
+	DefaultDeregistrationDelay = 300 * time.Second
+)
+
+// Config mirrors the health check settings of the target group
+type Config struct {
+	// Interval and UnhealthyThreshold of the target group's health check,
+	// which together decide how long it takes the load balancer to notice a
+	// failing target
+	Interval           time.Duration
+	UnhealthyThreshold int
+	// DeregistrationDelay of the target group, in-flight requests are given
+	// this long to complete when a target is deregistered
+	DeregistrationDelay time.Duration
+	// WarmUp fails health checks for this long after the Handler is created,
+	// so the target only receives traffic once caches and connections are
+	// warm
+	WarmUp time.Duration
+}
+
+// Handler is a http.Handler answering target group health checks. Use New to
+// instantiate one
+type Handler struct {
+	check   *health.ServiceCheck
+	config  Config
+	started time.Time
+
+	draining int32
+}
+
+// New returns a Handler serving the health of `check`. Zero values in config
+// are replaced by the ALB defaults, except WarmUp.
+func New(check *health.ServiceCheck, config Config) *Handler {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.UnhealthyThreshold <= 0 {
+		config.UnhealthyThreshold = DefaultUnhealthyThreshold
+	}
+	if config.DeregistrationDelay <= 0 {
+		config.DeregistrationDelay = DefaultDeregistrationDelay
+	}
+
+	return &Handler{
+		check:   check,
+		config:  config,
+		started: time.Now(),
+	}
+}
+
+// ServeHTTP responds with a 200 whilst the target should receive traffic and
+// a 503 otherwise. Target groups only look at the status code, so the body is
+// a short plain text reason.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	code, reason := http.StatusOK, "healthy"
+	switch {
+	case atomic.LoadInt32(&h.draining) == 1:
+		code, reason = http.StatusServiceUnavailable, "draining"
+	case time.Since(h.started) < h.config.WarmUp:
+		code, reason = http.StatusServiceUnavailable, "warming up"
+	case !h.check.IsHealthy():
+		code, reason = http.StatusServiceUnavailable, "unhealthy"
+	case !h.check.IsServing():
+		code, reason = http.StatusServiceUnavailable, "not serving"
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if r.Method != "HEAD" {
+		w.Write([]byte(reason + "\n"))
+	}
+}
+
+// Draining returns whether Drain has been called
+func (h *Handler) Draining() bool {
+	return atomic.LoadInt32(&h.draining) == 1
+}
+
+// DrainDuration is how long Drain waits: long enough for the load balancer to
+// mark the target unhealthy and, if it was deregistered, for the
+// deregistration delay to elapse
+func (h *Handler) DrainDuration() time.Duration {
+	notice := h.config.Interval * time.Duration(h.config.UnhealthyThreshold)
+	if h.config.DeregistrationDelay > notice {
+		return h.config.DeregistrationDelay
+	}
+	return notice
+}
+
+// Drain starts failing health checks and blocks until the load balancer has
+// stopped routing to the target, see DrainDuration, or ctx is cancelled. The
+// application should keep serving requests until Drain returns and then shut
+// down.
+func (h *Handler) Drain(ctx context.Context) error {
+	atomic.StoreInt32(&h.draining, 1)
+
+	timer := time.NewTimer(h.DrainDuration())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package targetgroup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestHandler(t *testing.T) {
+	check := &health.ServiceCheck{Name: "test", Healthy: true}
+	h := New(check, Config{WarmUp: 50 * time.Millisecond})
+
+	expectCode := func(expected int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+		if rec.Code != expected {
+			t.Errorf("expected %d got %d (%s)", expected, rec.Code, rec.Body.String())
+		}
+	}
+
+	expectCode(http.StatusServiceUnavailable)
+
+	time.Sleep(60 * time.Millisecond)
+	expectCode(http.StatusOK)
+
+	check.Healthy = false
+	expectCode(http.StatusServiceUnavailable)
+
+	check.Healthy = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := h.Drain(ctx); err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+	if !h.Draining() {
+		t.Error("expected handler to be draining")
+	}
+	expectCode(http.StatusServiceUnavailable)
+}
+
+func TestHandlerServiceDraining(t *testing.T) {
+	tests := []struct {
+		name  string
+		drain func(*health.ServiceCheck)
+	}{
+		{"drain", (*health.ServiceCheck).Drain},
+		{"shutdown", (*health.ServiceCheck).BeginShutdown},
+	}
+
+	for _, test := range tests {
+		check, _ := health.InitialiseServiceCheck("test", time.Second)
+		h := New(check, Config{})
+		test.drain(check)
+
+		// ensure the target fails whilst the service drains, though it's healthy
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected %d got %d for %s", http.StatusServiceUnavailable, rec.Code, test.name)
+		}
+	}
+}
+
+func TestDrainDuration(t *testing.T) {
+	tests := []struct {
+		config   Config
+		expected time.Duration
+	}{
+		{Config{}, DefaultDeregistrationDelay},
+		{Config{Interval: 10 * time.Second, UnhealthyThreshold: 3, DeregistrationDelay: 20 * time.Second}, 30 * time.Second},
+		{Config{Interval: 5 * time.Second, DeregistrationDelay: 15 * time.Second}, 15 * time.Second},
+	}
+
+	for _, test := range tests {
+		h := New(&health.ServiceCheck{}, test.config)
+		if d := h.DrainDuration(); d != test.expected {
+			t.Errorf("expected %v got %v", test.expected, d)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package health
+
+// WithThreshold only changes the reported health of the dependency after
+// `failures` consecutive failures or `successes` consecutive successes, so a
+// single transient failure doesn't flip the service behind a load balancer.
+// Values below 2 change it straight away. The first check is reported as is.
+func WithThreshold(failures, successes int) DependencyOption {
+	return func(d *Dependency) {
+		d.failures, d.successes = failures, successes
+	}
+}
+
+// threshold returns the health to report for a check finding the dependency
+// `healthy`, counting the consecutive results differing from the reported
+// health. s.mu must be held.
+func (d *Dependency) threshold(healthy bool) bool {
+	if !d.checked || healthy == d.Healthy {
+		d.streak = 0
+		return healthy
+	}
+
+	d.streak++
+	threshold := d.failures
+	if healthy {
+		threshold = d.successes
+	}
+	if d.streak < threshold {
+		return d.Healthy
+	}
+
+	d.streak = 0
+	return healthy
+}
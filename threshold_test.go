@@ -0,0 +1,47 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithThreshold(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+
+	// the first check, on registration, is reported as is
+	results := []bool{true}
+	dep, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		result := results[0]
+		results = results[1:]
+		return result
+	}, WithThreshold(3, 2))
+
+	tests := []struct {
+		result   bool
+		expected bool
+	}{
+		// a single failure doesn't flip the dependency
+		{false, true},
+		{true, true},
+		// three consecutive failures do
+		{false, true},
+		{false, true},
+		{false, false},
+		// and two consecutive successes flip it back
+		{true, false},
+		{false, false},
+		{true, false},
+		{true, true},
+	}
+
+	for i, test := range tests {
+		results = []bool{test.result}
+		check.Update()
+		if dep.IsHealthy() != test.expected {
+			t.Errorf("expected %v got %v after check %d", test.expected, dep.IsHealthy(), i+1)
+		}
+		if check.IsHealthy() != test.expected {
+			t.Errorf("expected service %v got %v after check %d", test.expected, check.IsHealthy(), i+1)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package health
+
+import "time"
+
+// timer is a reusable timer on a Clock. With the real clock it wraps a
+// time.Timer, so waiting repeatedly doesn't allocate a timer each time.
+type timer struct {
+	c     <-chan time.Time
+	t     *time.Timer
+	clock Clock
+}
+
+// newTimer returns a timer firing after `d` on `clock`
+func newTimer(clock Clock, d time.Duration) *timer {
+	if _, ok := clock.(realClock); ok {
+		t := time.NewTimer(d)
+		return &timer{c: t.C, t: t}
+	}
+
+	return &timer{c: clock.After(d), clock: clock}
+}
+
+// reset starts the timer again, firing after `d`. It must only be called once
+// the timer has fired and been received from.
+func (t *timer) reset(d time.Duration) {
+	if t.t != nil {
+		t.t.Reset(d)
+		return
+	}
+	t.c = t.clock.After(d)
+}
+
+// stop releases the timer
+func (t *timer) stop() {
+	if t.t != nil {
+		t.t.Stop()
+	}
+}
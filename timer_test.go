@@ -0,0 +1,42 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimer(t *testing.T) {
+	timer := newTimer(realClock{}, time.Millisecond)
+	defer timer.stop()
+	if timer.t == nil {
+		t.Error("expected the real clock to use a time.Timer")
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-timer.c:
+		case <-time.After(time.Second):
+			t.Fatalf("expected the timer to fire on iteration %d", i)
+		}
+		timer.reset(time.Millisecond)
+	}
+}
+
+func TestTimerFakeClock(t *testing.T) {
+	clock := &fakeClock{}
+	timer := newTimer(clock, time.Second)
+
+	clock.advance(time.Second, 1)
+	<-timer.c
+
+	timer.reset(time.Minute)
+	clock.advance(time.Second, 1)
+	select {
+	case <-timer.c:
+		t.Error("expected the reset timer not to fire yet")
+	default:
+	}
+
+	clock.advance(time.Minute, 1)
+	<-timer.c
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer traces each check of a service's dependencies, such as in an
+// OpenTelemetry span, see WithTracer and the otelhealth package
+type Tracer interface {
+	// StartCheck starts tracing a check of the dependency, returning the
+	// context the check runs with, so work within it is traced too, and a
+	// func ending the trace with the result of the check
+	StartCheck(ctx context.Context, service, dependency string, level Level) (context.Context, func(healthy bool, err error, took time.Duration))
+}
+
+// WithTracer traces each check of the service's dependencies with `tracer`.
+// Checks registered with RegisterDependencyContext run with the context it
+// returns. Nothing is traced without it.
+func WithTracer(tracer Tracer) Option {
+	return func(s *ServiceCheck) {
+		s.tracer = tracer
+	}
+}
+
+// startSpan starts tracing a check of `dep` with the Tracer, if any,
+// returning the context the check runs with and a func ending the trace
+func (s *ServiceCheck) startSpan(ctx context.Context, dep *Dependency) (context.Context, func(healthy bool, err error)) {
+	if s.tracer == nil {
+		return ctx, func(bool, error) {}
+	}
+
+	clock := s.getClock()
+	start := clock.Now()
+	ctx, end := s.tracer.StartCheck(ctx, s.Name, dep.Name, dep.Level)
+	return ctx, func(healthy bool, err error) {
+		end(healthy, err, clock.Now().Sub(start))
+	}
+}
+
+// traced returns `check` traced by the owner's Tracer, if any. Checks taking
+// a context are traced as they're given one instead, see withContext.
+func (d *Dependency) traced(check func() bool) func() bool {
+	if d.owner == nil || d.owner.tracer == nil || d.errs != nil {
+		return check
+	}
+
+	return func() bool {
+		_, end := d.owner.startSpan(d.owner.context(), d)
+		healthy := check()
+		var err error
+		if !healthy {
+			err = d.checkError()
+		}
+		end(healthy, err)
+		return healthy
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type traceKey struct{}
+
+// span is a check traced by fakeTracer
+type span struct {
+	service, dependency string
+	level               Level
+	healthy             bool
+	err                 error
+}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []span
+}
+
+func (f *fakeTracer) StartCheck(ctx context.Context, service, dependency string, level Level) (context.Context, func(bool, error, time.Duration)) {
+	return context.WithValue(ctx, traceKey{}, dependency), func(healthy bool, err error, took time.Duration) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.spans = append(f.spans, span{service, dependency, level, healthy, err})
+	}
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &fakeTracer{}
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithTracer(tracer))
+
+	check.RegisterDependency("cache", LevelSoft, func() bool { return false })
+	var traced interface{}
+	failure := errors.New("connection refused")
+	check.RegisterDependencyContext("db", LevelHard, func(ctx context.Context) (bool, error) {
+		// ensure the check runs within the trace
+		traced = ctx.Value(traceKey{})
+		return false, failure
+	})
+
+	expected := []span{
+		{"api", "cache", LevelSoft, false, nil},
+		{"api", "db", LevelHard, false, failure},
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.spans) != len(expected) {
+		t.Fatalf("expected %d spans got %d", len(expected), len(tracer.spans))
+	}
+	for i, s := range expected {
+		if tracer.spans[i] != s {
+			t.Errorf("expected %+v got %+v", s, tracer.spans[i])
+		}
+	}
+	if traced != "db" {
+		t.Errorf("expected the check's context to be traced got %v", traced)
+	}
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportConfig configures the HTTP transport of a client for health
+// requests, so probes with different network requirements, such as those to
+// external partners through a proxy, needn't share a transport
+type TransportConfig struct {
+	// Proxy returns the proxy for a request. Unlike http.DefaultTransport no
+	// proxy is used if nil, set it to http.ProxyFromEnvironment to use the
+	// environment.
+	Proxy func(*http.Request) (*url.URL, error)
+	// DialContext dials connections, net.Dialer's is used if nil
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// TLSClientConfig configures TLS connections
+	TLSClientConfig *tls.Config
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool
+	// MaxIdleConns and MaxIdleConnsPerHost limit the idle connections kept
+	// open, those of http.DefaultTransport are used if zero
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes idle connections after the duration, that of
+	// http.DefaultTransport is used if zero
+	IdleConnTimeout time.Duration
+	// Timeout limits each request, that of NewHTTPClient is used if zero
+	Timeout time.Duration
+}
+
+// Transport returns a transport configured as per the config
+func (c TransportConfig) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = c.Proxy
+	if c.DialContext != nil {
+		transport.DialContext = c.DialContext
+	}
+	if c.TLSClientConfig != nil {
+		transport.TLSClientConfig = c.TLSClientConfig
+	}
+	transport.DisableKeepAlives = c.DisableKeepAlives
+	if c.MaxIdleConns > 0 {
+		transport.MaxIdleConns = c.MaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.IdleConnTimeout
+	}
+
+	return transport
+}
+
+// Client returns a client using the configured transport, for passing to
+// WithHTTPClient or any of the helpers
+func (c TransportConfig) Client() *http.Client {
+	client := NewHTTPClient()
+	client.Transport = c.Transport()
+	if c.Timeout > 0 {
+		client.Timeout = c.Timeout
+	}
+
+	return client
+}
+
+// WithRemoteTransport fetches the remote status with a client configured as
+// per `config`
+func WithRemoteTransport(config TransportConfig) RemoteOption {
+	return WithRemoteClient(config.Client())
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTransportConfig(t *testing.T) {
+	transport := TransportConfig{}.Transport()
+	if transport.Proxy != nil {
+		t.Error("expected no proxy by default")
+	}
+	if transport.MaxIdleConns != http.DefaultTransport.(*http.Transport).MaxIdleConns {
+		t.Errorf("expected the default max idle conns got %d", transport.MaxIdleConns)
+	}
+
+	proxy, _ := url.Parse("http://proxy:3128")
+	transport = TransportConfig{
+		Proxy:               http.ProxyURL(proxy),
+		DisableKeepAlives:   true,
+		MaxIdleConns:        1,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     time.Second,
+	}.Transport()
+
+	req, _ := http.NewRequest("GET", "http://partner/health", nil)
+	if got, _ := transport.Proxy(req); got.String() != proxy.String() {
+		t.Errorf("expected %v got %v", proxy, got)
+	}
+	if !transport.DisableKeepAlives || transport.MaxIdleConns != 1 || transport.MaxIdleConnsPerHost != 2 || transport.IdleConnTimeout != time.Second {
+		t.Errorf("expected the configured settings got %+v", transport)
+	}
+
+	if client := (TransportConfig{}).Client(); client.Timeout != NewHTTPClient().Timeout {
+		t.Errorf("expected the default timeout got %v", client.Timeout)
+	}
+	if client := (TransportConfig{Timeout: time.Second}).Client(); client.Timeout != time.Second {
+		t.Errorf("expected %v got %v", time.Second, client.Timeout)
+	}
+}
+
+func TestWithRemoteTransport(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("users", time.Second)
+		check.HTTPHandler(w, r)
+	}))
+	defer remote.Close()
+
+	var dials int32
+	dialer := &net.Dialer{}
+	config := TransportConfig{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+
+	check, _ := InitialiseServiceCheck("test", time.Second)
+	if _, err := check.RegisterRemoteService("users", remote.URL, LevelHard, WithRemoteTransport(config)); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+	if !check.IsHealthy() || atomic.LoadInt32(&dials) != 1 {
+		t.Errorf("expected a healthy check through the dialer got %v with %d dials", check.IsHealthy(), dials)
+	}
+}
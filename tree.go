@@ -0,0 +1,187 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultTreeMaxDepth is the number of hops GetTree follows when
+	// TreeOptions.MaxDepth is not set
+	DefaultTreeMaxDepth = 5
+	// DefaultTreeMaxConcurrency is the number of endpoints GetTree fetches at
+	// once when TreeOptions.MaxConcurrency is not set
+	DefaultTreeMaxConcurrency = 8
+)
+
+// Tree is a node in a nested health tree. Nodes with a URL are remote services
+// using this package, other nodes are plain dependencies as reported by their
+// parent.
+type Tree struct {
+	Name         string  `json:"name"`
+	URL          string  `json:"url,omitempty"`
+	Healthy      bool    `json:"healthy"`
+	Level        Level   `json:"level"`
+	Error        string  `json:"error,omitempty"`
+	Cycle        bool    `json:"cycle,omitempty"`
+	Truncated    bool    `json:"truncated,omitempty"`
+	Dependencies []*Tree `json:"dependencies,omitempty"`
+}
+
+// TreeOptions bounds how deep and how wide GetTree fans out
+type TreeOptions struct {
+	// MaxDepth is the maximum number of hops followed from the root endpoint.
+	// Remote dependencies beyond it are reported with Truncated set.
+	MaxDepth int
+	// MaxConcurrency is the maximum number of endpoints fetched at once across
+	// the whole tree.
+	MaxConcurrency int
+	// Client is used to fetch each endpoint, a NewHTTPClient is used if nil.
+	Client *http.Client
+}
+
+// GetTree fetches the health endpoint at rawURL and recursively follows the
+// URLs of its remote dependencies, building a nested health tree.
+//
+// A URL which is already an ancestor of the current node is not followed
+// again, instead the node is reported with Cycle set, so A→B→A topologies
+// terminate. Failures to fetch an endpoint are reported on the node rather
+// than aborting the walk.
+func GetTree(ctx context.Context, rawURL string, opts TreeOptions) *Tree {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultTreeMaxDepth
+	}
+	if opts.MaxConcurrency <= 0 {
+		opts.MaxConcurrency = DefaultTreeMaxConcurrency
+	}
+	if opts.Client == nil {
+		opts.Client = defaultHTTPClient
+	}
+
+	w := &treeWalker{
+		opts: opts,
+		sem:  make(chan struct{}, opts.MaxConcurrency),
+	}
+
+	root := &Tree{URL: rawURL, Level: LevelHard}
+	w.walk(ctx, root, nil, 0)
+	return root
+}
+
+type treeWalker struct {
+	opts TreeOptions
+	sem  chan struct{}
+}
+
+// walk populates node from its URL. path holds the normalised URLs of every
+// ancestor of node.
+func (w *treeWalker) walk(ctx context.Context, node *Tree, path []string, depth int) {
+	key := normaliseURL(node.URL)
+	for _, visited := range path {
+		if visited == key {
+			node.Cycle = true
+			return
+		}
+	}
+
+	if depth > w.opts.MaxDepth {
+		node.Truncated = true
+		return
+	}
+
+	// only hold a slot whilst fetching, holding it whilst waiting on children
+	// would deadlock wide trees
+	select {
+	case w.sem <- struct{}{}:
+	case <-ctx.Done():
+		node.Error = ctx.Err().Error()
+		return
+	}
+	check, err := fetchServiceCheck(ctx, w.opts.Client, node.URL)
+	<-w.sem
+
+	if err != nil {
+		node.Error = err.Error()
+		return
+	}
+
+	if node.Name == "" {
+		node.Name = check.Name
+	}
+	node.Healthy = check.Healthy
+
+	// copy the path so siblings don't share a backing array
+	childPath := make([]string, len(path), len(path)+1)
+	copy(childPath, path)
+	childPath = append(childPath, key)
+
+	var wg sync.WaitGroup
+	for _, dependency := range check.Dependencies {
+		child := &Tree{
+			Name:    dependency.Name,
+			URL:     dependency.URL,
+			Healthy: dependency.Healthy,
+			Level:   dependency.Level,
+		}
+		node.Dependencies = append(node.Dependencies, child)
+
+		if child.URL == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.walk(ctx, child, childPath, depth+1)
+		}()
+	}
+	wg.Wait()
+}
+
+// fetchServiceCheck fetches and decodes the status document served by another
+// ServiceCheck. Unhealthy services respond with a 503 but still include the
+// document, so the body is decoded regardless of the status code.
+func fetchServiceCheck(ctx context.Context, client *http.Client, rawURL string) (*ServiceCheck, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	check := &ServiceCheck{}
+	if err := decodeResponse(resp, check); err != nil {
+		if resp.StatusCode != http.StatusOK && !errors.Is(err, ErrResponseTooLarge) {
+			return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return nil, err
+	}
+
+	return check, nil
+}
+
+// normaliseURL returns a comparable form of rawURL, falling back to rawURL
+// itself if it can't be parsed
+func normaliseURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
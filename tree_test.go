@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetTreeCycle(t *testing.T) {
+	var aURL, bURL string
+
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("a", time.Second)
+		check.RegisterDependency("b", LevelHard, func() bool { return true }, WithURL(bURL))
+		check.HTTPHandler(w, r)
+	}))
+	defer a.Close()
+
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("b", time.Second)
+		check.RegisterDependency("a", LevelSoft, func() bool { return true }, WithURL(aURL))
+		check.HTTPHandler(w, r)
+	}))
+	defer b.Close()
+
+	aURL, bURL = a.URL, b.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	tree := GetTree(ctx, a.URL, TreeOptions{})
+	if tree.Name != "a" || !tree.Healthy {
+		t.Fatalf("expected healthy root a got %+v", tree)
+	}
+	if len(tree.Dependencies) != 1 || tree.Dependencies[0].Name != "b" {
+		t.Fatalf("expected dependency b got %+v", tree.Dependencies)
+	}
+
+	b1 := tree.Dependencies[0]
+	if len(b1.Dependencies) != 1 {
+		t.Fatalf("expected b to have 1 dependency got %d", len(b1.Dependencies))
+	}
+
+	a1 := b1.Dependencies[0]
+	if !a1.Cycle {
+		t.Errorf("expected cycle to be detected on %+v", a1)
+	}
+	if len(a1.Dependencies) != 0 {
+		t.Errorf("expected cycle node not to be followed got %+v", a1.Dependencies)
+	}
+}
+
+func TestGetTreeLimits(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		current int
+		peak    int
+		leafURL string
+	)
+
+	leaf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		current++
+		if current > peak {
+			peak = current
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		current--
+		mu.Unlock()
+
+		check, _ := InitialiseServiceCheck("leaf", time.Second)
+		check.RegisterDependency("deeper", LevelHard, func() bool { return true }, WithURL(leafURL+"/deeper"))
+		check.HTTPHandler(w, r)
+	}))
+	defer leaf.Close()
+	leafURL = leaf.URL
+
+	root := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check, _ := InitialiseServiceCheck("root", time.Second)
+		for _, name := range []string{"1", "2", "3", "4", "5", "6"} {
+			check.RegisterDependency(name, LevelHard, func() bool { return true }, WithURL(leaf.URL+"/"+name))
+		}
+		check.HTTPHandler(w, r)
+	}))
+	defer root.Close()
+
+	tree := GetTree(context.Background(), root.URL, TreeOptions{
+		MaxDepth:       1,
+		MaxConcurrency: 2,
+	})
+
+	if len(tree.Dependencies) != 6 {
+		t.Fatalf("expected 6 dependencies got %d", len(tree.Dependencies))
+	}
+	for _, child := range tree.Dependencies {
+		if child.Error != "" {
+			t.Errorf("unexpected error on %s: %s", child.Name, child.Error)
+		}
+		if len(child.Dependencies) != 1 || !child.Dependencies[0].Truncated {
+			t.Errorf("expected dependencies beyond max depth to be truncated on %+v", child)
+		}
+	}
+
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent fetches got %d", peak)
+	}
+}
+
+func TestGetTreeUnreachable(t *testing.T) {
+	tree := GetTree(context.Background(), "http://127.0.0.1:0", TreeOptions{})
+	if tree.Healthy {
+		t.Error("expected unreachable root to be unhealthy")
+	}
+	if tree.Error == "" {
+		t.Error("expected error to be reported on the root")
+	}
+}
@@ -0,0 +1,27 @@
+package health
+
+import "time"
+
+// WithTTL keeps each result of the dependency valid for `ttl` after it was
+// checked, so neither the check's cycles nor an on demand Update run its check
+// again until then. This suits expensive checks which are fresh enough for
+// longer than the service's duration.
+func WithTTL(ttl time.Duration) DependencyOption {
+	return func(d *Dependency) {
+		d.ttl = ttl
+	}
+}
+
+// fresh returns whether the last result is still within the TTL
+func (d *Dependency) fresh() bool {
+	return d.ttl > 0 && d.checked && d.clock().Now().Sub(d.started) < d.ttl
+}
+
+// clock returns the owner's Clock, or the real clock
+func (d *Dependency) clock() Clock {
+	if d.owner == nil {
+		return realClock{}
+	}
+
+	return d.owner.getClock()
+}
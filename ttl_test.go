@@ -0,0 +1,49 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithTTL(t *testing.T) {
+	clock := &fakeClock{}
+	check, _ := InitialiseServiceCheck("api", time.Second, WithClock(clock))
+
+	var runs int
+	check.RegisterDependency("warehouse", LevelSoft, func() bool {
+		runs++
+		return true
+	}, WithTTL(time.Minute))
+	check.RegisterDependency("db", LevelHard, func() bool { return true })
+
+	tests := []struct {
+		advance  time.Duration
+		expected int
+	}{
+		{time.Second, 1},
+		{58 * time.Second, 1},
+		{time.Second, 2},
+		{30 * time.Second, 2},
+	}
+
+	for _, test := range tests {
+		clock.advance(test.advance, 0)
+		check.Update()
+		if runs != test.expected {
+			t.Errorf("expected %d runs got %d at %v", test.expected, runs, clock.Now())
+		}
+	}
+}
+
+func TestWithTTLSpread(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Second)
+	warehouse, _ := check.RegisterDependency("warehouse", LevelSoft, func() bool { return true }, WithTTL(time.Hour))
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true }, WithTTL(time.Millisecond))
+
+	if interval := check.interval(warehouse); interval != time.Hour {
+		t.Errorf("expected %v got %v", time.Hour, interval)
+	}
+	if interval := check.interval(db); interval != time.Second {
+		t.Errorf("expected %v got %v", time.Second, interval)
+	}
+}
@@ -0,0 +1,60 @@
+package health
+
+import "time"
+
+// HistorySummary summarises the results of a dependency held by WithHistory
+type HistorySummary struct {
+	// Checks is the number of results summarised
+	Checks int
+	// Availability is the percentage of the results which were healthy
+	Availability float64
+	// AverageDuration is the mean time the checks took
+	AverageDuration time.Duration
+}
+
+// summarise summarises `results`, the zero HistorySummary if there are none
+func summarise(results []CheckResult) HistorySummary {
+	if len(results) == 0 {
+		return HistorySummary{}
+	}
+
+	var (
+		healthy int
+		total   time.Duration
+	)
+	for _, result := range results {
+		if result.Healthy {
+			healthy++
+		}
+		total += result.Duration
+	}
+
+	return HistorySummary{
+		Checks:          len(results),
+		Availability:    100 * float64(healthy) / float64(len(results)),
+		AverageDuration: total / time.Duration(len(results)),
+	}
+}
+
+// History returns the results of the dependency's checks held by
+// WithHistory, oldest first, or nil without it. Only the handle returned by
+// RegisterDependency or Dependency has a history, not the copies returned by
+// DependencyStates.
+func (d *Dependency) History() []CheckResult {
+	if d.owner == nil || d.owner.history == nil {
+		return nil
+	}
+
+	return d.owner.history.results(d)
+}
+
+// Summary summarises the dependency's History
+func (d *Dependency) Summary() HistorySummary {
+	return summarise(d.History())
+}
+
+// Availability returns the percentage of the dependency's History which was
+// healthy, or 0 if there is none
+func (d *Dependency) Availability() float64 {
+	return d.Summary().Availability
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDependencyHistory(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute, WithHistory(HistoryConfig{Size: 3, Summary: true}))
+	results := []bool{true, false, true, true, false}
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool {
+		result := results[0]
+		results = results[1:]
+		return result
+	})
+
+	// the check on registration isn't kept, then the oldest is evicted
+	for i := 0; i < 4; i++ {
+		check.Update()
+	}
+	history := db.History()
+	if len(history) != 3 {
+		t.Fatalf("expected 3 results got %d", len(history))
+	}
+	for i, expected := range []bool{true, true, false} {
+		if history[i].Healthy != expected {
+			t.Errorf("expected %v got %v at %d", expected, history[i].Healthy, i)
+		}
+	}
+
+	summary := db.Summary()
+	if summary.Checks != 3 || db.Availability() < 66 || db.Availability() > 67 {
+		t.Errorf("expected 3 checks 66.67%% available got %+v", summary)
+	}
+
+	doc := check.Document().Dependencies[0]
+	if doc.UptimePercent == nil || *doc.UptimePercent != summary.Availability || doc.AvgCheckDurationMs == nil {
+		t.Errorf("expected the summary in the document got %+v", doc)
+	}
+
+	b, _ := json.Marshal(check)
+	if !strings.Contains(string(b), `"uptimePercent":`) || !strings.Contains(string(b), `"avgCheckDurationMs":`) {
+		t.Errorf("expected the summary to be encoded got %s", b)
+	}
+}
+
+func TestDependencyHistoryDisabled(t *testing.T) {
+	check, _ := InitialiseServiceCheck("api", time.Minute)
+	db, _ := check.RegisterDependency("db", LevelHard, func() bool { return true })
+	check.Update()
+
+	if db.History() != nil || db.Availability() != 0 {
+		t.Errorf("expected no history got %v", db.History())
+	}
+	if doc := check.Document().Dependencies[0]; doc.UptimePercent != nil {
+		t.Errorf("expected no summary got %v", *doc.UptimePercent)
+	}
+}
@@ -0,0 +1,103 @@
+// Package uptimekuma drives an Uptime Kuma push monitor from a ServiceCheck,
+// for teams on self-hosted status tooling which can't reach the service to
+// probe it.
+package uptimekuma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// Monitor pushes heartbeats to an Uptime Kuma push monitor
+type Monitor struct {
+	// PushURL of the monitor, as shown by Uptime Kuma, for example
+	// https://kuma.example.com/api/push/abc123. Any query string is replaced.
+	PushURL string
+	// ReportDown pushes a "down" heartbeat naming the failing hard
+	// dependencies whilst unhealthy. Otherwise heartbeats stop whilst
+	// unhealthy and Uptime Kuma marks the monitor down once its heartbeat
+	// interval passes.
+	ReportDown bool
+	// Client is used to make requests, a client with a 10 second timeout is
+	// used if nil
+	Client *http.Client
+}
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Push sends a single heartbeat reflecting the health of `check`
+func (m *Monitor) Push(ctx context.Context, check *health.ServiceCheck) error {
+	status, msg := "up", "OK"
+	if !check.IsHealthy() {
+		if !m.ReportDown {
+			return nil
+		}
+
+		var failing []string
+		for _, dependency := range check.DependencyStates() {
+			if !dependency.Healthy && dependency.Level == health.LevelHard {
+				failing = append(failing, dependency.Name)
+			}
+		}
+		status, msg = "down", "unhealthy: "+strings.Join(failing, ", ")
+	}
+
+	u, err := url.Parse(m.PushURL)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("status", status)
+	query.Set("msg", msg)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	client := m.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	// ensure resp.Body is closed when function returns
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("uptime kuma responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Start calls Push every `interval` until ctx is cancelled. `interval` should
+// be shorter than the monitor's heartbeat interval.
+func (m *Monitor) Start(ctx context.Context, check *health.ServiceCheck, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			m.Push(ctx, check)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
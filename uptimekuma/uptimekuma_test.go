@@ -0,0 +1,70 @@
+package uptimekuma
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+func TestMonitorPush(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		queries []url.Values
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/push/token" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		mu.Lock()
+		queries = append(queries, r.URL.Query())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	var healthy bool
+	check.RegisterDependency("db", health.LevelHard, func() bool { return healthy })
+
+	tests := []struct {
+		healthy        bool
+		reportDown     bool
+		expectedStatus string
+		expectedMsg    string
+	}{
+		{true, false, "up", "OK"},
+		{false, false, "", ""},
+		{false, true, "down", "unhealthy: db"},
+	}
+
+	for i, test := range tests {
+		mu.Lock()
+		queries = nil
+		mu.Unlock()
+
+		healthy = test.healthy
+		check.Update()
+		monitor := &Monitor{PushURL: server.URL + "/api/push/token?status=up", ReportDown: test.reportDown}
+		if err := monitor.Push(context.Background(), check); err != nil {
+			t.Errorf("expected nil got %v on test case #%d", err, i)
+		}
+
+		mu.Lock()
+		switch {
+		case test.expectedStatus == "" && len(queries) != 0:
+			t.Errorf("expected no heartbeat on test case #%d", i)
+		case test.expectedStatus != "" && len(queries) != 1:
+			t.Errorf("expected 1 heartbeat got %d on test case #%d", len(queries), i)
+		case test.expectedStatus != "":
+			if queries[0].Get("status") != test.expectedStatus || queries[0].Get("msg") != test.expectedMsg {
+				t.Errorf("unexpected heartbeat %v on test case #%d", queries[0], i)
+			}
+		}
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Status is the state of a service, as observed of a health endpoint by Watch
+// or reported by ServiceCheck.Status
+type Status uint32
+
+const (
+	// StatusUnknown means the endpoint couldn't be reached or its response
+	// couldn't be understood
+	StatusUnknown Status = 0
+	// StatusHealthy means the endpoint reported itself as healthy
+	StatusHealthy Status = 1
+	// StatusUnhealthy means the endpoint reported itself as unhealthy
+	StatusUnhealthy Status = 2
+	// StatusDegraded means the service is healthy but some of its soft
+	// dependencies are failing, see ServiceCheck.Status
+	StatusDegraded Status = 3
+)
+
+// String returns the name of the status
+func (s Status) String() string {
+	switch s {
+	case StatusHealthy:
+		return "healthy"
+	case StatusUnhealthy:
+		return "unhealthy"
+	case StatusDegraded:
+		return "degraded"
+	default:
+		return "unknown"
+	}
+}
+
+// Watch polls the health endpoint at url every `interval` and invokes onChange
+// with the new status whenever it changes, including once with the first
+// observed status. It blocks until ctx is cancelled, returning ctx.Err(), or
+// returns ErrInvalidInterval straight away unless `interval` is positive.
+// Function supports passing an optional *http.Client to use a different
+// timeout for the probes.
+func Watch(ctx context.Context, url string, interval time.Duration, onChange func(Status), optionalClient ...*http.Client) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+	client := getHTTPClient(optionalClient)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		last     Status
+		observed bool
+	)
+	for {
+		status := probeStatus(ctx, client, url)
+
+		// a probe aborted by cancellation says nothing about the endpoint
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !observed || status != last {
+			observed = true
+			last = status
+			onChange(status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeStatus requests the status document at url and maps it onto a Status.
+// Endpoints serving, such as with 200 or a degraded status code, report their
+// own status, falling back to whether they're healthy for older versions of
+// this package which didn't include one.
+func probeStatus(ctx context.Context, client *http.Client, url string) Status {
+	r, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return StatusUnknown
+	}
+
+	resp, err := client.Do(r.WithContext(ctx))
+	if err != nil {
+		return StatusUnknown
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return StatusUnhealthy
+	}
+
+	var doc StatusDocument
+	switch err := decodeResponse(resp, &doc); {
+	case err != nil:
+		return StatusUnknown
+	case doc.Status != StatusUnknown:
+		return doc.Status
+	case doc.Healthy:
+		return StatusHealthy
+	default:
+		return StatusUnhealthy
+	}
+}
@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		healthy = true
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(&ServiceCheck{Name: "test", Healthy: healthy})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan Status, 10)
+	done := make(chan error)
+
+	go func() {
+		done <- Watch(ctx, server.URL, 10*time.Millisecond, func(status Status) {
+			changes <- status
+		})
+	}()
+
+	expectStatus := func(expected Status) {
+		select {
+		case status := <-changes:
+			if status != expected {
+				t.Errorf("expected %v got %v", expected, status)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %v", expected)
+		}
+	}
+
+	expectStatus(StatusHealthy)
+
+	mu.Lock()
+	healthy = false
+	mu.Unlock()
+	expectStatus(StatusUnhealthy)
+
+	server.Close()
+	expectStatus(StatusUnknown)
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+
+	select {
+	case status := <-changes:
+		t.Errorf("unexpected change to %v", status)
+	default:
+	}
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	called := false
+	// ensure a non-positive interval is an error rather than a panic
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := Watch(context.Background(), "http://localhost", interval, func(Status) { called = true })
+		if !errors.Is(err, ErrInvalidInterval) {
+			t.Errorf("expected %v got %v", ErrInvalidInterval, err)
+		}
+	}
+	if called {
+		t.Error("expected onChange not to be called")
+	}
+}
+
+func TestProbeStatus(t *testing.T) {
+	tests := []struct {
+		code     int
+		body     string
+		expected Status
+	}{
+		{200, `{"name":"test","healthy":true,"status":"healthy"}`, StatusHealthy},
+		{200, `{"name":"test","healthy":true,"status":"degraded"}`, StatusDegraded},
+		{207, `{"name":"test","healthy":true,"status":"degraded"}`, StatusDegraded},
+		{503, `{"name":"test","healthy":false,"status":"unhealthy"}`, StatusUnhealthy},
+		{503, `{"name":"test","healthy":true,"status":"healthy","draining":true}`, StatusUnhealthy},
+		// older versions don't report a status
+		{200, `{"name":"test","healthy":true}`, StatusHealthy},
+		{200, `{"name":"test","healthy":false}`, StatusUnhealthy},
+		{200, `not json`, StatusUnknown},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.code)
+			w.Write([]byte(test.body))
+		}))
+
+		if status := probeStatus(context.Background(), server.Client(), server.URL); status != test.expected {
+			t.Errorf("expected %v got %v for %d %s", test.expected, status, test.code, test.body)
+		}
+		server.Close()
+	}
+}
+
+func TestStatusString(t *testing.T) {
+	tests := []struct {
+		status   Status
+		expected string
+	}{
+		{StatusUnknown, "unknown"},
+		{StatusHealthy, "healthy"},
+		{StatusUnhealthy, "unhealthy"},
+	}
+
+	for _, test := range tests {
+		if test.status.String() != test.expected {
+			t.Errorf("expected %v got %v", test.expected, test.status.String())
+		}
+	}
+}
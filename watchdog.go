@@ -0,0 +1,93 @@
+package health
+
+import "time"
+
+// WatchdogName is the name of the dependency registered by WithWatchdog
+const WatchdogName = "watchdog"
+
+// watchdog is the dependency registered by WithWatchdog
+type watchdog struct {
+	cycles int
+	level  Level
+	dep    *Dependency
+}
+
+// WithWatchdog registers a dependency of `level` named WatchdogName which
+// fails when the dependencies haven't been checked for `cycles` times the
+// duration, so a deadlocked or blocked StartCheck or StartSpread shows up at
+// the endpoint rather than leaving it reporting its last, healthy, status. It
+// is checked by a goroutine of its own, and recovers once the checks do. It
+// affects both readiness and liveness, see WithProbes, so a stalled process
+// is restarted. It is registered by InitialiseServiceCheck once every other
+// option is applied, so it follows the check's clock, name policy and Store.
+func WithWatchdog(cycles int, level Level) Option {
+	return func(s *ServiceCheck) {
+		s.watchdog = &watchdog{cycles: cycles, level: level}
+	}
+}
+
+// registerWatchdog registers the watchdog's dependency, if there is one
+func (s *ServiceCheck) registerWatchdog() error {
+	if s.watchdog == nil {
+		return nil
+	}
+
+	// reaching the check at all means the checks are running
+	dep, err := s.RegisterDependency(WatchdogName, s.watchdog.level, func() bool { return true }, WithProbes(ProbeReadiness|ProbeLiveness))
+	if err != nil {
+		return err
+	}
+	s.watchdog.dep = dep
+	return nil
+}
+
+// watch starts the watchdog, if there is one, for the lifetime of `l`. s.mu
+// must be held.
+func (s *ServiceCheck) watch(l *loops) {
+	if s.watchdog == nil {
+		return
+	}
+
+	dog := s.watchdog
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		s.runWatchdog(l.stop, dog)
+	}()
+}
+
+// runWatchdog fails the watchdog's dependency every duration that the
+// dependencies haven't been checked for long enough, until `stop` is closed
+func (s *ServiceCheck) runWatchdog(stop <-chan struct{}, dog *watchdog) {
+	clock := s.getClock()
+	started := clock.Now()
+	t := newTimer(clock, s.duration)
+	defer t.stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.c:
+		}
+		t.reset(s.duration)
+
+		// the checks may have been blocked since before they were started
+		last := started
+		if checked := s.lastChecked.Load(); checked != nil && checked.After(last) {
+			last = *checked
+		}
+		now := clock.Now()
+		if now.Sub(last) <= time.Duration(dog.cycles)*s.duration {
+			continue
+		}
+
+		s.mu.Lock()
+		if dog.dep.Healthy && s.registered(dog.dep) {
+			dog.dep.record(false, now, 0)
+			s.remember(dog.dep)
+			s.recordHealth(true)
+		}
+		s.mu.Unlock()
+	}
+}
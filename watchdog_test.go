@@ -0,0 +1,81 @@
+package health
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithWatchdog(t *testing.T) {
+	clock := &fakeClock{}
+	// ensure the watchdog follows options given after it
+	check, _ := InitialiseServiceCheck("api", time.Second, WithWatchdog(2, LevelHard), WithClock(clock))
+
+	var block atomic.Bool
+	release := make(chan struct{})
+	check.RegisterDependency("db", LevelHard, func() bool {
+		if block.Load() {
+			<-release
+		}
+		return true
+	})
+
+	check.StartCheck()
+	defer check.StopCheck()
+
+	// ensure the first cycle is done before blocking the next
+	clock.advance(0, 2)
+	block.Store(true)
+	for i := 0; i < 2; i++ {
+		clock.advance(time.Second, 2)
+		if !check.IsHealthy() {
+			t.Fatalf("expected healthy within %d cycles", i+1)
+		}
+	}
+
+	changed := check.Changed()
+	clock.advance(time.Second, 2)
+	<-changed
+	if check.IsHealthy() {
+		t.Errorf("expected unhealthy once the checks stalled")
+	}
+	if dep, _ := check.Dependency(WatchdogName); dep.IsHealthy() {
+		t.Errorf("expected the watchdog to fail")
+	}
+
+	// the watchdog recovers with the checks
+	changed = check.Changed()
+	block.Store(false)
+	close(release)
+	<-changed
+	if !check.IsHealthy() {
+		t.Errorf("expected healthy once the checks recovered")
+	}
+}
+
+func TestWithWatchdogOptions(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		opts     []Option
+		expected error
+	}{
+		{[]Option{WithWatchdog(2, LevelHard), WithClock(clock)}, nil},
+		{[]Option{WithWatchdog(2, LevelHard), WithNamePolicy(NamePolicy{MaxLength: 4})}, ErrInvalidDependencyName},
+	}
+
+	for i, test := range tests {
+		check, err := InitialiseServiceCheck("api", time.Second, test.opts...)
+		if !errors.Is(err, test.expected) {
+			t.Fatalf("expected %v got %v on test case #%d", test.expected, err, i)
+		}
+		if err != nil {
+			continue
+		}
+
+		dep, _ := check.Dependency(WatchdogName)
+		if !dep.LastChecked.Equal(clock.Now()) {
+			t.Errorf("expected %v got %v on test case #%d", clock.Now(), dep.LastChecked, i)
+		}
+	}
+}
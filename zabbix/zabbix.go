@@ -0,0 +1,200 @@
+// Package zabbix pushes the health of a ServiceCheck to a Zabbix server or
+// proxy using the sender protocol, keyed per dependency, for estates
+// standardised on Zabbix.
+//
+// Items are sent as trapper items:
+//
+//	<prefix>.service               1 when the service is healthy, 0 otherwise
+//	<prefix>.dependency[<name>]    1 when the dependency is healthy, 0 otherwise
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+const (
+	// DefaultKeyPrefix is used for item keys when Sender.KeyPrefix is empty
+	DefaultKeyPrefix = "health"
+	// DefaultTimeout bounds a send when Sender.Timeout is not set and the
+	// context has no deadline
+	DefaultTimeout = 5 * time.Second
+
+	// maxResponseSize bounds how much of a response is read
+	maxResponseSize = 1 << 16
+)
+
+var header = []byte("ZBXD\x01")
+
+// Sender sends health items to a Zabbix server or proxy
+type Sender struct {
+	// Addr of the server or proxy trapper port, for example zabbix:10051
+	Addr string
+	// Host is the name of the host the items belong to in Zabbix
+	Host string
+	// KeyPrefix of every item key, DefaultKeyPrefix if empty
+	KeyPrefix string
+	// Timeout bounds each send, DefaultTimeout if not set
+	Timeout time.Duration
+}
+
+type item struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+}
+
+type request struct {
+	Request string `json:"request"`
+	Data    []item `json:"data"`
+	Clock   int64  `json:"clock"`
+}
+
+type response struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+var failedRegexp = regexp.MustCompile(`failed: (\d+)`)
+
+// Send pushes the current health of `check` and its dependencies
+func (s *Sender) Send(ctx context.Context, check *health.ServiceCheck) error {
+	prefix := s.KeyPrefix
+	if prefix == "" {
+		prefix = DefaultKeyPrefix
+	}
+
+	now := time.Now().Unix()
+	items := []item{{Host: s.Host, Key: prefix + ".service", Value: boolValue(check.IsHealthy()), Clock: now}}
+	for _, dependency := range check.DependencyStates() {
+		items = append(items, item{
+			Host:  s.Host,
+			Key:   fmt.Sprintf("%s.dependency[%s]", prefix, quoteParam(dependency.Name)),
+			Value: boolValue(dependency.Healthy),
+			Clock: now,
+		})
+	}
+
+	payload, err := json.Marshal(request{Request: "sender data", Data: items, Clock: now})
+	if err != nil {
+		return err
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write(frame(payload)); err != nil {
+		return err
+	}
+
+	body, err := readFrame(conn)
+	if err != nil {
+		return err
+	}
+
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+	if resp.Response != "success" {
+		return fmt.Errorf("zabbix rejected items: %s", resp.Info)
+	}
+	if m := failedRegexp.FindStringSubmatch(resp.Info); m != nil && m[1] != "0" {
+		return fmt.Errorf("zabbix failed to process items, check they exist as trapper items: %s", resp.Info)
+	}
+
+	return nil
+}
+
+// Start calls Send every `interval` until ctx is cancelled
+func (s *Sender) Start(ctx context.Context, check *health.ServiceCheck, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.Send(ctx, check)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// frame wraps payload in the sender protocol header
+func frame(payload []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(header)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(payload)))
+	binary.Write(&buf, binary.LittleEndian, uint32(0))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// readFrame reads a single sender protocol frame, returning its payload
+func readFrame(r io.Reader) ([]byte, error) {
+	prefix := make([]byte, len(header)+8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:len(header)], header) {
+		return nil, errors.New("zabbix: invalid response header")
+	}
+
+	size := binary.LittleEndian.Uint32(prefix[len(header):])
+	if size > maxResponseSize {
+		return nil, fmt.Errorf("zabbix: response of %d bytes is too large", size)
+	}
+
+	body := make([]byte, size)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// quoteParam quotes an item key parameter if it contains characters which
+// would otherwise end it
+func quoteParam(s string) string {
+	for _, r := range s {
+		switch r {
+		case ',', ']', '"', ' ', '[':
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fresh8/health"
+)
+
+// fakeServer accepts a single sender connection, passing the request it
+// receives on and answering with `info`
+func fakeServer(t *testing.T, info string) (string, <-chan request) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := make(chan request, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		body, err := readFrame(conn)
+		if err != nil {
+			t.Errorf("unexpected error reading request: %v", err)
+			return
+		}
+
+		var req request
+		json.Unmarshal(body, &req)
+		requests <- req
+
+		resp, _ := json.Marshal(response{Response: "success", Info: info})
+		conn.Write(frame(resp))
+	}()
+
+	return listener.Addr().String(), requests
+}
+
+func TestSend(t *testing.T) {
+	addr, requests := fakeServer(t, "processed: 3; failed: 0; total: 3; seconds spent: 0.000055")
+
+	check, _ := health.InitialiseServiceCheck("api", time.Second)
+	check.RegisterDependency("db", health.LevelHard, func() bool { return true })
+	check.RegisterDependency("cache, eu", health.LevelSoft, func() bool { return false })
+
+	sender := &Sender{Addr: addr, Host: "api-1"}
+	if err := sender.Send(context.Background(), check); err != nil {
+		t.Fatalf("expected nil got %v", err)
+	}
+
+	req := <-requests
+	if req.Request != "sender data" {
+		t.Errorf("expected %q got %q", "sender data", req.Request)
+	}
+
+	expected := map[string]string{
+		"health.service":                 "1",
+		"health.dependency[db]":          "1",
+		`health.dependency["cache, eu"]`: "0",
+	}
+	if len(req.Data) != len(expected) {
+		t.Fatalf("expected %d items got %d", len(expected), len(req.Data))
+	}
+	for _, item := range req.Data {
+		if item.Host != "api-1" {
+			t.Errorf("expected host api-1 got %s", item.Host)
+		}
+		if expected[item.Key] != item.Value {
+			t.Errorf("expected %v got %v for %s", expected[item.Key], item.Value, item.Key)
+		}
+	}
+}
+
+func TestSendFailedItems(t *testing.T) {
+	addr, _ := fakeServer(t, "processed: 1; failed: 2; total: 3; seconds spent: 0.000055")
+
+	sender := &Sender{Addr: addr, Host: "api-1"}
+	if err := sender.Send(context.Background(), &health.ServiceCheck{Name: "api"}); err == nil {
+		t.Error("expected an error when items fail to be processed")
+	}
+}